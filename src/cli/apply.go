@@ -3,9 +3,12 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"mindful/src/importer"
 	"mindful/src/models"
 	"mindful/src/symlink"
 
@@ -16,6 +19,9 @@ var (
 	applyTools     string
 	applySkipBuild bool
 	applyDryRun    bool
+	applyForce     bool
+	applyAdopt     bool
+	applyImport    bool
 )
 
 func newApplyCmd() *cobra.Command {
@@ -28,6 +34,10 @@ func newApplyCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&applyTools, "tool", "t", "", "comma separated list of tools to target (defaults to enabled tools)")
 	cmd.Flags().BoolVar(&applySkipBuild, "skip-build", false, "skip automatic build before applying symlinks")
 	cmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "plan symlink changes without modifying the filesystem")
+	cmd.Flags().BoolVar(&applyForce, "force", false, "overwrite hand-edited copies and unmanaged files at mapped paths")
+	cmd.Flags().BoolVar(&applyAdopt, "adopt", false, "move existing files at mapped paths into mindful/backup before linking")
+	addAllowUnignoredFlag(cmd)
+	cmd.Flags().BoolVar(&applyImport, "import", false, "with --adopt, import adopted content into the project memory, subagent and MCP sources")
 
 	return cmd
 }
@@ -40,9 +50,11 @@ func runApply(cmd *cobra.Command, args []string) error {
 	defer ctx.Close()
 
 	if !applySkipBuild {
-		if _, err := executeBuild(ctx); err != nil {
+		artifacts, err := executeBuild(ctx)
+		if err != nil {
 			return fmt.Errorf("build failed: %w", err)
 		}
+		printWarnings(cmd, artifacts)
 	}
 
 	tools, err := resolveTargetTools(ctx.ProjectConfig, applyTools)
@@ -50,11 +62,20 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	manager, err := symlink.NewManager(ctx.ProjectPath, nil)
+	manager, err := ctx.NewSymlinkManager()
 	if err != nil {
 		return err
 	}
 
+	if applyImport && !applyAdopt {
+		return fmt.Errorf("--import requires --adopt")
+	}
+	if applyAdopt && !applyDryRun {
+		if err := adoptExisting(cmd, ctx, manager, tools); err != nil {
+			return err
+		}
+	}
+
 	var toolErrs []error
 	for _, tool := range tools {
 		if applyDryRun {
@@ -64,18 +85,176 @@ func runApply(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		if err := manager.CreateSymlinks(tool); err != nil {
+		if err := manager.Apply(tool, symlink.ApplyOptions{Force: applyForce}); err != nil {
 			toolErrs = append(toolErrs, fmt.Errorf("%s: %w", tool, err))
 			fmt.Fprintf(cmd.ErrOrStderr(), "✗ %s: %v\n", tool, err)
 			continue
 		}
 
 		fmt.Fprintf(cmd.OutOrStdout(), "✓ %s symlinks updated\n", tool)
+
+		pruned, err := manager.PruneOrphans(tool)
+		for _, path := range pruned {
+			fmt.Fprintf(cmd.OutOrStdout(), "  pruned orphan %s\n", path)
+		}
+		if err != nil {
+			toolErrs = append(toolErrs, fmt.Errorf("%s: %w", tool, err))
+			fmt.Fprintf(cmd.ErrOrStderr(), "✗ %s: %v\n", tool, err)
+		}
+	}
+
+	if strings.TrimSpace(applyTools) == "" {
+		if err := forgetDisabledTools(cmd, manager, tools); err != nil {
+			toolErrs = append(toolErrs, err)
+		}
 	}
 
 	return errors.Join(toolErrs...)
 }
 
+// adoptExisting backs up files that block the links of tools, optionally importing
+// their content into the project sources and rebuilding before links are created.
+func adoptExisting(cmd *cobra.Command, ctx *ProjectContext, manager *symlink.Manager, tools []string) error {
+	backup, err := manager.NewBackup()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, tool := range tools {
+		adopted, err := manager.Adopt(tool, backup)
+		for _, entry := range adopted {
+			fmt.Fprintf(cmd.OutOrStdout(), "  adopted %s -> %s\n", entry.Path, relativeTo(ctx.ProjectPath, filepath.Join(backup.Dir(), entry.File)))
+		}
+		if err != nil && !errors.Is(err, symlink.ErrToolNotConfigured) {
+			errs = append(errs, fmt.Errorf("%s: %w", tool, err))
+		}
+	}
+
+	if err := backup.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(backup.Entries()) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ backup %s saved (restore with 'mindful restore --from %s')\n", backup.Name(), backup.Name())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("adopt failed: %w", err)
+	}
+
+	if !applyImport || len(backup.Entries()) == 0 {
+		return nil
+	}
+
+	items, err := adoptedItems(backup)
+	if err != nil {
+		return err
+	}
+	store, err := ctx.GetStorageManager()
+	if err != nil {
+		return err
+	}
+
+	imp := importer.New(ctx.ProjectPath, store)
+	changes, err := imp.Plan(items)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if err := imp.Apply(changes); err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	for _, change := range changes {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", describeImportChange(ctx.ProjectPath, change))
+	}
+
+	if applySkipBuild {
+		fmt.Fprintln(cmd.OutOrStdout(), "  imported content takes effect after 'mindful build'")
+		return nil
+	}
+	artifacts, err := executeBuild(ctx)
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+	printWarnings(cmd, artifacts)
+	return nil
+}
+
+// adoptedItems reads the backed-up files as import items.
+func adoptedItems(backup *symlink.Backup) ([]importer.Item, error) {
+	items := make([]importer.Item, 0, len(backup.Entries()))
+	for _, entry := range backup.Entries() {
+		content, err := os.ReadFile(filepath.Join(backup.Dir(), filepath.FromSlash(entry.File)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read adopted %s: %w", entry.Path, err)
+		}
+		target := filepath.Base(entry.Target)
+		items = append(items, importer.Item{
+			Kind:    entry.Kind,
+			Tool:    entry.Tool,
+			Path:    entry.Path,
+			Name:    strings.TrimSuffix(target, filepath.Ext(target)),
+			Content: content,
+		})
+	}
+	return items, nil
+}
+
+// describeImportChange renders a planned import change for output.
+func describeImportChange(projectPath string, change importer.Change) string {
+	target := change.Target
+	if filepath.IsAbs(target) {
+		target = relativeTo(projectPath, target)
+	}
+	line := fmt.Sprintf("%-6s %s <- %s", change.Action, target, change.Source)
+	if change.Reason != "" {
+		line += " (" + change.Reason + ")"
+	}
+	return line
+}
+
+// forgetDisabledTools removes paths recorded for tools that are no longer enabled.
+func forgetDisabledTools(cmd *cobra.Command, manager *symlink.Manager, enabled []string) error {
+	recorded, err := manager.RecordedTools()
+	if err != nil {
+		return err
+	}
+
+	active := make(map[string]struct{}, len(enabled))
+	for _, tool := range enabled {
+		active[tool] = struct{}{}
+	}
+
+	var errs []error
+	for _, tool := range recorded {
+		if _, ok := active[tool]; ok {
+			continue
+		}
+
+		if applyDryRun {
+			infos, err := manager.ListSymlinks(tool)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("dry-run failed for %s: %w", tool, err))
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (disabled):\n", tool)
+			for _, info := range infos {
+				if info.Status == models.LinkStatusOrphaned {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s\n", "remove", info.LinkPath)
+				}
+			}
+			continue
+		}
+
+		if err := manager.ForgetTool(tool); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tool, err))
+			fmt.Fprintf(cmd.ErrOrStderr(), "✗ %s: %v\n", tool, err)
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ %s disabled, managed paths removed\n", tool)
+	}
+
+	return errors.Join(errs...)
+}
+
 func resolveTargetTools(cfg *models.ProjectConfig, selection string) ([]string, error) {
 	var tools []string
 	if strings.TrimSpace(selection) != "" {
@@ -120,12 +299,54 @@ func reportPlannedSymlinks(cmd *cobra.Command, manager *symlink.Manager, tool st
 	}
 
 	for _, info := range infos {
-		status := "create"
-		if info.IsValid {
-			status = "ok"
+		action := plannedAction(info)
+		if applyAdopt && action == "blocked" {
+			action = "adopt"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s -> %s%s\n", action, info.LinkPath, info.TargetPath, renderLinkMode(info))
+	}
+
+	previews, err := manager.PreviewMerges(tool, symlink.ApplyOptions{Force: applyForce})
+	if err != nil {
+		return fmt.Errorf("dry-run failed for %s: %w", tool, err)
+	}
+	for _, preview := range previews {
+		for _, line := range strings.Split(strings.TrimSuffix(preview.Diff(), "\n"), "\n") {
+			fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", line)
+		}
+	}
+
+	recorded, err := manager.ListSymlinks(tool)
+	if err != nil {
+		return fmt.Errorf("dry-run failed for %s: %w", tool, err)
+	}
+	for _, info := range recorded {
+		if info.Status == models.LinkStatusOrphaned {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s\n", "remove", info.LinkPath)
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "  %-7s %s -> %s\n", status, info.LinkPath, info.TargetPath)
+	}
+
+	orphans, err := manager.FindOrphans(tool)
+	if err != nil {
+		return fmt.Errorf("dry-run failed for %s: %w", tool, err)
+	}
+	for _, path := range orphans {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s\n", "prune", path)
 	}
 
 	return nil
 }
+
+// plannedAction describes what apply would do with a planned link.
+func plannedAction(info models.SymlinkInfo) string {
+	switch info.Status {
+	case models.LinkStatusOK:
+		return "ok"
+	case models.LinkStatusStale:
+		return "update"
+	case models.LinkStatusModified, models.LinkStatusConflict:
+		return "blocked"
+	default:
+		return "create"
+	}
+}
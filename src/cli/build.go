@@ -1,24 +1,34 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"mindful/src/models"
 
 	"github.com/spf13/cobra"
 )
 
+// allowUnignoredOutput lets build write MCP credentials into a mindful/out that git does not ignore.
+var allowUnignoredOutput bool
+
 func newBuildCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "build",
 		Short: "Render mindful/out artefacts from project sources",
 		RunE:  runBuild,
 	}
+	addAllowUnignoredFlag(cmd)
 	return cmd
 }
 
+func addAllowUnignoredFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&allowUnignoredOutput, "allow-unignored", false, "write MCP configuration even if mindful/out is not git-ignored")
+}
+
 func runBuild(cmd *cobra.Command, args []string) error {
 	ctx, err := NewProjectContext()
 	if err != nil {
@@ -30,41 +40,91 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	printWarnings(cmd, artifacts)
 
 	if verboseFlag {
 		subagentCount := 0
+		var servers []*models.MCPServerSource
 		if artifacts != nil {
 			subagentCount = len(artifacts.Subagents)
+			servers = artifacts.MCPServers
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "mindful/out refreshed (subagents: %d, mcp servers: %d)\n", subagentCount, len(servers))
+		for _, server := range servers {
+			line := fmt.Sprintf("  mcp %-20s scope:%s source:%s", server.Name, server.Scope, server.Source)
+			if len(server.Shadowed) > 0 {
+				line += fmt.Sprintf(" (overrides %s)", strings.Join(server.Shadowed, ", "))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), line)
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "mindful/out refreshed (subagents: %d)\n", subagentCount)
 	}
 
 	return nil
 }
 
+// printWarnings reports non-fatal build problems on stderr, followed by the
+// personal files that went into mindful/out.
+func printWarnings(cmd *cobra.Command, artifacts *models.BuildArtifacts) {
+	if artifacts == nil {
+		return
+	}
+	for _, warning := range artifacts.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning)
+	}
+	if len(artifacts.Personal) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "note: mindful/out includes personal content (scope: %s); keep it out of shared files or set user_scope: false in mindful.yaml:\n", models.ScopeUser)
+		for _, path := range artifacts.Personal {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", path)
+		}
+	}
+}
+
 func executeBuild(ctx *ProjectContext) (*models.BuildArtifacts, error) {
 	if ctx == nil {
 		return nil, errors.New("project context cannot be nil")
 	}
 
-	teamSource, err := ctx.ResolveTeamSource()
+	layers, err := ctx.SourceLayers()
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve team source: %w", err)
+		return nil, fmt.Errorf("failed to resolve sources: %w", err)
+	}
+	lockWarnings, err := pinSources(ctx, layers)
+	if err != nil {
+		return nil, err
 	}
 
-	artifacts, err := ctx.SourceManager.LoadArtifacts(teamSource, ctx.ProjectPath)
+	artifacts, err := ctx.SourceManager.LoadLayers(layers)
 	if err != nil {
 		return nil, err
 	}
 	if artifacts == nil {
 		artifacts = &models.BuildArtifacts{}
 	}
+	artifacts.Warnings = append(artifacts.Warnings, lockWarnings...)
+
+	if err := ctx.RenderToolOutputs(artifacts); err != nil {
+		return nil, err
+	}
+
+	servers, mcpSources, warnings, err := loadMCPServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	artifacts.MCPServers = mcpSources
+	artifacts.Warnings = append(artifacts.Warnings, warnings...)
 
-	mcpContent, err := loadMCPContent(ctx)
+	outputs, warnings, err := renderMCPOutputs(ctx, servers)
 	if err != nil {
 		return nil, err
 	}
-	artifacts.MCPContent = mcpContent
+	artifacts.MCPOutputs = outputs
+	artifacts.Warnings = append(artifacts.Warnings, warnings...)
+
+	if len(outputs) > 0 && !allowUnignoredOutput {
+		if err := ensureOutIgnored(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := ctx.WriteArtifacts(artifacts); err != nil {
 		return nil, err
@@ -73,35 +133,280 @@ func executeBuild(ctx *ProjectContext) (*models.BuildArtifacts, error) {
 	return artifacts, nil
 }
 
-func loadMCPContent(ctx *ProjectContext) ([]byte, error) {
-	storageManager, err := ctx.GetStorageManager()
+// loadMCPServers merges the MCP scopes and returns the validated, enabled servers
+// selected by mindful.yaml with placeholders still unresolved. Warnings name servers
+// the project references that are not defined in any scope.
+func loadMCPServers(ctx *ProjectContext) ([]*models.MCPServer, []*models.MCPServerSource, []string, error) {
+	layers, err := loadMCPLayers(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Later layers take precedence: team < project < user.
+	records := make(map[string]string)
+	origins := make(map[string]*models.MCPServerSource)
+	for _, layer := range layers {
+		for name, encoded := range layer.servers {
+			origin := &models.MCPServerSource{Name: name, Scope: layer.scope, Source: layer.source}
+			if previous, ok := origins[name]; ok {
+				origin.Shadowed = append(previous.Shadowed, previous.Scope)
+			}
+			records[name] = encoded
+			origins[name] = origin
+		}
+	}
+
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+
+	var settings *models.MCPSettings
+	if ctx.ProjectConfig != nil {
+		settings = ctx.ProjectConfig.MCP
+	}
+	selected, missing := settings.SelectServers(names)
+
+	var warnings []string
+	for _, name := range missing {
+		warnings = append(warnings, fmt.Sprintf("MCP server %q is referenced in mindful.yaml but not defined in any scope", name))
+	}
+
+	var servers []*models.MCPServer
+	var sources []*models.MCPServerSource
+	var invalid []error
+	for _, name := range selected {
+		server, err := models.DecodeMCPServer(name, records[name])
+		if err == nil {
+			if settings != nil {
+				settings.Overrides[name].Apply(server)
+			}
+			err = server.Validate()
+		}
+		if err != nil {
+			origin := origins[name]
+			invalid = append(invalid, fmt.Errorf("%s scope (%s):\n%w", origin.Scope, origin.Source, err))
+			continue
+		}
+		if server.Disabled {
+			continue
+		}
+		servers = append(servers, server)
+		sources = append(sources, origins[name])
+	}
+	if len(invalid) > 0 {
+		return nil, nil, nil, fmt.Errorf("invalid MCP configuration (fix with 'mindful mcp edit' or mcp.overrides in mindful.yaml):\n%w", errors.Join(invalid...))
+	}
+
+	return servers, sources, warnings, nil
+}
+
+// renderMCPOutputs renders the servers once per enabled tool whose mapping has an
+// MCP target or format, resolving placeholders in the tool's own syntax.
+func renderMCPOutputs(ctx *ProjectContext, servers []*models.MCPServer) ([]*models.MCPOutput, []string, error) {
+	if len(servers) == 0 {
+		return nil, nil, nil
+	}
+
+	symlinkConfig, err := ctx.SymlinkConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolver := newPlaceholderResolver(ctx)
+	var outputs []*models.MCPOutput
+	var warnings []string
+	for _, tool := range ctx.EnabledMappedTools(symlinkConfig) {
+		toolConfig, _ := symlinkConfig.ToolConfig(tool)
+		if !toolConfig.RendersMCP() {
+			continue
+		}
+		format := toolConfig.MCPFormatName()
+
+		resolved, inputs, skipped, err := resolver.resolve(servers, format)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, message := range skipped {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", tool, message))
+		}
+
+		data, skipped, err := models.RenderMCP(format, resolved, inputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to render MCP configuration for %s: %w", tool, err)
+		}
+		for _, message := range skipped {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", tool, message))
+		}
+
+		outputs = append(outputs, &models.MCPOutput{Tool: tool, Format: format, Content: data})
+	}
+
+	if err := resolver.err(); err != nil {
+		return nil, nil, err
+	}
+	return outputs, warnings, nil
+}
+
+// mcpLayer is one scope's MCP server definitions.
+type mcpLayer struct {
+	scope   string
+	source  string
+	servers map[string]string
+}
+
+// loadMCPLayers collects the team store, the project's mindful/mcp.json and the
+// user store, in increasing order of precedence.
+func loadMCPLayers(ctx *ProjectContext) ([]mcpLayer, error) {
+	var layers []mcpLayer
+
+	storageManager, err := ctx.ReadStorageManager()
 	if err != nil {
 		// Treat absence of storage as a non-fatal error when the directory does not exist yet.
 		var pathErr *os.PathError
-		if errors.As(err, &pathErr) && os.IsNotExist(pathErr.Err) {
-			return nil, nil
+		if !errors.As(err, &pathErr) || !os.IsNotExist(pathErr.Err) {
+			return nil, err
+		}
+	} else {
+		records, err := storageManager.ListMCP()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list MCP configurations: %w", err)
 		}
+		layers = append(layers, mcpLayer{scope: models.ScopeTeam, source: storageManager.Path(), servers: records})
+	}
+
+	projectCfg, projectFile, err := ctx.SourceManager.LoadProjectMCP(ctx.ProjectPath)
+	if err != nil {
 		return nil, err
 	}
+	if projectCfg != nil {
+		layers = append(layers, mcpLayer{scope: models.ScopeProject, source: projectFile, servers: projectCfg.Servers})
+	}
 
-	records, err := storageManager.ListMCP()
+	userStore, err := ctx.GetUserStorageManager(false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list MCP configurations: %w", err)
+		return nil, err
+	}
+	if userStore != nil {
+		records, err := userStore.ListMCP()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list user MCP configurations: %w", err)
+		}
+		layers = append(layers, mcpLayer{scope: models.ScopeUser, source: userStore.Path(), servers: records})
 	}
 
-	if len(records) == 0 {
-		return nil, nil
+	return layers, nil
+}
+
+// placeholderResolver substitutes ${secret:NAME} and ${env:NAME} references and
+// collects every reference that could not be resolved across all tools.
+type placeholderResolver struct {
+	ctx        *ProjectContext
+	values     bool // Substitute values even when env references are enabled
+	unresolved []string
+	seen       map[string]struct{}
+}
+
+func newPlaceholderResolver(ctx *ProjectContext) *placeholderResolver {
+	return &placeholderResolver{ctx: ctx, seen: make(map[string]struct{})}
+}
+
+// resolve returns copies of the servers with placeholders replaced for format.
+// With env references enabled, env placeholders become the tool's own syntax and
+// VS Code prompts for secrets through inputs instead of receiving their values.
+// Servers the format cannot express are skipped and described in the messages.
+func (r *placeholderResolver) resolve(servers []*models.MCPServer, format string) ([]*models.MCPServer, []models.MCPInput, []string, error) {
+	references := r.ctx.ProjectConfig.UsesEnvReferences() && !r.values
+	var inputs []models.MCPInput
+	inputSeen := make(map[string]struct{})
+
+	lookup := func(kind, name string) (string, bool, error) {
+		switch kind {
+		case models.PlaceholderEnv:
+			if references {
+				// Let the tool expand the variable when it starts the server.
+				ref, err := models.MCPEnvReference(format, name)
+				return ref, err == nil, err
+			}
+			value, ok := os.LookupEnv(name)
+			return value, ok, nil
+		case models.PlaceholderSecret:
+			if references && format == models.MCPFormatVSCode {
+				if _, ok := inputSeen[name]; !ok {
+					inputSeen[name] = struct{}{}
+					inputs = append(inputs, models.MCPInput{Type: "promptString", ID: name, Description: "mindful secret " + name, Password: true})
+				}
+				return "${input:" + name + "}", true, nil
+			}
+			store, err := r.ctx.ReadSecretStore()
+			if err != nil || store == nil {
+				return "", false, err
+			}
+			return store.RetrieveSecret(name)
+		}
+		return "", false, nil
+	}
+
+	var resolved []*models.MCPServer
+	var skipped []string
+	for _, server := range servers {
+		data, err := server.MarshalJSON()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, nil, nil, err
+		}
+
+		value, missing, err := models.ResolvePlaceholders(generic, lookup)
+		if errors.Is(err, models.ErrMCPUnsupported) {
+			skipped = append(skipped, fmt.Sprintf("MCP server %q left out: %v", server.Name, err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("MCP server %s: %w", server.Name, err)
+		}
+		for _, ref := range missing {
+			entry := fmt.Sprintf("%s: %s", server.Name, ref)
+			if _, ok := r.seen[entry]; !ok {
+				r.seen[entry] = struct{}{}
+				r.unresolved = append(r.unresolved, entry)
+			}
+		}
+
+		typed, err := models.ParseMCPServerMap(server.Name, value)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		resolved = append(resolved, typed)
+	}
+
+	return resolved, inputs, skipped, nil
+}
+
+// err reports the unresolved references collected so far.
+func (r *placeholderResolver) err() error {
+	if len(r.unresolved) == 0 {
+		return nil
 	}
+	return fmt.Errorf("unresolved placeholders in MCP configuration (set secrets with 'mindful secrets set NAME', env vars in the environment):\n  %s", strings.Join(r.unresolved, "\n  "))
+}
 
-	cfg := models.NewMCPConfig()
-	for name, encoded := range records {
-		cfg.Servers[name] = encoded
+// ensureOutIgnored refuses to write secret-bearing artefacts into a mindful/out
+// that git would pick up. Projects outside a git work tree are not checked.
+func ensureOutIgnored(ctx *ProjectContext) error {
+	if !isGitWorkTree(ctx.ProjectPath) {
+		return nil
 	}
 
-	data, err := cfg.ToMCPJSON()
+	outDir := relativeTo(ctx.ProjectPath, ctx.ResolveOutDir())
+	ignored, err := isGitIgnored(ctx.ProjectPath, outDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render MCP configuration: %w", err)
+		return fmt.Errorf("failed to check whether %s is git-ignored: %w", outDir, err)
 	}
-
-	return data, nil
+	if !ignored {
+		return fmt.Errorf("refusing to write MCP configuration: %s is not git-ignored and would expose credentials; add it to .gitignore or pass --allow-unignored", outDir)
+	}
+	return nil
 }
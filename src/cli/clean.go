@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"mindful/src/symlink"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanTool        string
+	cleanOrphansOnly bool
+)
+
+func newCleanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove managed links and prune orphaned subagent links",
+		RunE:  runClean,
+	}
+
+	cmd.Flags().StringVarP(&cleanTool, "tool", "t", "", "comma separated list of tools to clean (defaults to every mapped tool)")
+	cmd.Flags().BoolVar(&cleanOrphansOnly, "orphans", false, "only prune orphaned links, keeping links for current artefacts")
+
+	return cmd
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	manager, err := ctx.NewSymlinkManager()
+	if err != nil {
+		return err
+	}
+
+	tools := withRecordedTools(manager, collectListTools(ctx, ""))
+	if cleanTool != "" {
+		tools, err = resolveTargetTools(ctx.ProjectConfig, cleanTool)
+		if err != nil {
+			return err
+		}
+	}
+	if len(tools) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no symlink mappings available")
+		return nil
+	}
+
+	var toolErrs []error
+	for _, tool := range tools {
+		pruned, err := manager.PruneOrphans(tool)
+		if errors.Is(err, symlink.ErrToolNotConfigured) {
+			// Only recorded paths remain for tools that are no longer mapped.
+			err = nil
+		}
+		if err != nil {
+			toolErrs = append(toolErrs, fmt.Errorf("%s: %w", tool, err))
+			fmt.Fprintf(cmd.ErrOrStderr(), "✗ %s: %v\n", tool, err)
+			continue
+		}
+
+		if !cleanOrphansOnly {
+			if err := manager.CleanupSymlinks(tool); err != nil {
+				toolErrs = append(toolErrs, fmt.Errorf("%s: %w", tool, err))
+				fmt.Fprintf(cmd.ErrOrStderr(), "✗ %s: %v\n", tool, err)
+				continue
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ %s cleaned\n", tool)
+		for _, path := range pruned {
+			fmt.Fprintf(cmd.OutOrStdout(), "  pruned orphan %s\n", path)
+		}
+	}
+
+	return errors.Join(toolErrs...)
+}
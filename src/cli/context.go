@@ -10,6 +10,7 @@ import (
 	"mindful/src/models"
 	"mindful/src/source"
 	"mindful/src/storage"
+	"mindful/src/symlink"
 )
 
 // ProjectContext aggregates shared services for CLI commands.
@@ -18,6 +19,8 @@ type ProjectContext struct {
 	ConfigManager  *config.Manager
 	SourceManager  *source.Manager
 	StorageManager *storage.Manager
+	UserStore      *storage.Manager
+	SecretStore    *storage.Manager
 	ProjectConfig  *models.ProjectConfig
 }
 
@@ -46,16 +49,73 @@ func NewProjectContext() (*ProjectContext, error) {
 
 // Close releases any resources held by the context.
 func (c *ProjectContext) Close() error {
+	var err error
 	if c.StorageManager != nil {
-		return c.StorageManager.Close()
+		err = c.StorageManager.Close()
 	}
-	return nil
+	for _, store := range []*storage.Manager{c.UserStore, c.SecretStore} {
+		if store == nil {
+			continue
+		}
+		if closeErr := store.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// GetSecretStore lazily opens the per-user secrets database for writing.
+func (c *ProjectContext) GetSecretStore() (*storage.Manager, error) {
+	path, err := storage.SecretsPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStoreWith(&c.SecretStore, path, true, storage.NewSecretStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets database at %s: %w", path, err)
+	}
+	return store, nil
 }
 
-// GetStorageManager lazily initialises the storage manager.
+// ReadSecretStore lazily opens the per-user secrets database read-only. It returns
+// nil when no secret has been stored yet.
+func (c *ProjectContext) ReadSecretStore() (*storage.Manager, error) {
+	path, err := storage.SecretsPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(&c.SecretStore, path, false)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets database at %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// GetStorageManager lazily opens the team MCP store for writing. Only commands
+// that change the store should use it; the write lock excludes every other process.
 func (c *ProjectContext) GetStorageManager() (*storage.Manager, error) {
-	if c.StorageManager != nil {
-		return c.StorageManager, nil
+	return c.openTeamStore(true)
+}
+
+// ReadStorageManager lazily opens the team MCP store read-only, so any number of
+// reading commands can run at once. A missing store is reported as an *os.PathError.
+func (c *ProjectContext) ReadStorageManager() (*storage.Manager, error) {
+	return c.openTeamStore(false)
+}
+
+func (c *ProjectContext) openTeamStore(write bool) (*storage.Manager, error) {
+	if write {
+		gitSource, err := c.ProjectConfig.GitSource()
+		if err != nil {
+			return nil, err
+		}
+		if gitSource != nil {
+			// Changes to the cached checkout would be lost on the next update.
+			return nil, fmt.Errorf("team source %s is a git repository; change mindful.db in a clone of it and push, or use --scope user", gitSource)
+		}
 	}
 
 	dbPath, err := c.ProjectConfig.GetDatabasePath(c.ProjectPath)
@@ -63,18 +123,188 @@ func (c *ProjectContext) GetStorageManager() (*storage.Manager, error) {
 		return nil, err
 	}
 
-	manager, err := storage.NewManager(dbPath)
+	manager, err := openStore(&c.StorageManager, dbPath, write)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open storage at %s: %w", dbPath, err)
 	}
+	return manager, nil
+}
+
+// openStore returns the store cached in *slot, opening it on first use. A cached
+// read-only handle is reopened when write access is requested, because one process
+// cannot hold the shared and the exclusive lock on the same file at once.
+func openStore(slot **storage.Manager, path string, write bool) (*storage.Manager, error) {
+	return openStoreWith(slot, path, write, storage.NewManager)
+}
+
+// openStoreWith is openStore with the constructor used for write access.
+func openStoreWith(slot **storage.Manager, path string, write bool, openWriter func(string) (*storage.Manager, error)) (*storage.Manager, error) {
+	if *slot != nil {
+		if !write || !(*slot).ReadOnly() {
+			return *slot, nil
+		}
+		if err := (*slot).Close(); err != nil {
+			return nil, err
+		}
+		*slot = nil
+	}
 
-	c.StorageManager = manager
+	var store *storage.Manager
+	var err error
+	if write {
+		store, err = openWriter(path)
+	} else {
+		store, err = storage.NewReadOnlyManager(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	*slot = store
+	return store, nil
+}
+
+// UserDatabasePath returns the user-level MCP store, or "" when it is the same
+// file as the team store (the default team source is ~/.mindful).
+func (c *ProjectContext) UserDatabasePath() (string, error) {
+	userDir, err := models.UserMindfulDir()
+	if err != nil {
+		return "", err
+	}
+	userPath := filepath.Join(userDir, models.DefaultStorageFileName)
+
+	teamPath, err := c.ProjectConfig.GetDatabasePath(c.ProjectPath)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(teamPath) == filepath.Clean(userPath) {
+		return "", nil
+	}
+	return userPath, nil
+}
+
+// GetUserStorageManager lazily opens the user-level MCP store, for writing when
+// create is set and read-only otherwise. Unless create is set, it returns nil when
+// the store does not exist or is the team store.
+func (c *ProjectContext) GetUserStorageManager(create bool) (*storage.Manager, error) {
+	dbPath, err := c.UserDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	if dbPath == "" {
+		if create {
+			// The user store is the team store.
+			return c.GetStorageManager()
+		}
+		return nil, nil
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to inspect user storage at %s: %w", dbPath, err)
+		}
+		if !create {
+			return nil, nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create user storage directory: %w", err)
+		}
+	}
+
+	manager, err := openStore(&c.UserStore, dbPath, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user storage at %s: %w", dbPath, err)
+	}
 	return manager, nil
 }
 
-// ResolveTeamSource resolves the configured team source path.
+// ResolveTeamSource resolves the primary shared source (the last layer), which
+// holds the team MCP store. Git sources are cloned into the user cache on first use
+// and checked out at the commit pinned in mindful.lock, if any.
 func (c *ProjectContext) ResolveTeamSource() (string, error) {
-	return c.ProjectConfig.ResolveSourceRoot(c.ProjectPath)
+	configured, err := c.ProjectConfig.SourceLayers()
+	if err != nil {
+		return "", err
+	}
+	lock, err := c.ConfigManager.LoadLock(c.ProjectPath)
+	if err != nil {
+		return "", err
+	}
+	return c.resolveLayerDir(configured[len(configured)-1], lock)
+}
+
+// SourceLayers resolves the shared sources listed in mindful.yaml followed by the
+// project and user layers, from lowest to highest precedence. The user layer is
+// left out when mindful.yaml opts out of it or when the user directory is already
+// one of the shared sources.
+func (c *ProjectContext) SourceLayers() ([]source.Layer, error) {
+	configured, err := c.ProjectConfig.SourceLayers()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := c.ConfigManager.LoadLock(c.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []source.Layer
+	shared := make(map[string]struct{}, len(configured))
+	for _, layer := range configured {
+		dir, err := c.resolveLayerDir(layer, lock)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", layer.Name, err)
+		}
+		shared[filepath.Clean(dir)] = struct{}{}
+		layers = append(layers, source.SharedLayer(layer.Name, layer.EffectiveScope(), layer.Path, dir))
+	}
+	layers = append(layers, source.ProjectLayer(c.ProjectPath))
+	if !c.ProjectConfig.UserScopeEnabled() {
+		return layers, nil
+	}
+
+	userDir, err := models.UserMindfulDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := shared[filepath.Clean(userDir)]; !ok {
+		layers = append(layers, source.UserLayer(userDir))
+	}
+	return layers, nil
+}
+
+// resolveLayerDir returns the directory of a shared source, checking git sources
+// out at their pinned commit.
+func (c *ProjectContext) resolveLayerDir(layer *models.SourceLayer, lock *models.LockFile) (string, error) {
+	gitSource, err := models.ParseGitSource(layer.Path)
+	if err != nil {
+		return "", err
+	}
+	if gitSource == nil {
+		return models.ResolveSourcePath(layer.Path, c.ProjectPath)
+	}
+
+	var checkout *source.GitCheckout
+	if pin := lock.Find(layer.Name, layer.Path); pin != nil && pin.Commit != "" {
+		checkout, err = c.SourceManager.CheckoutGitCommit(gitSource, pin.Commit)
+	} else {
+		checkout, err = c.SourceManager.SyncGitSource(gitSource, false)
+	}
+	if err != nil {
+		return "", err
+	}
+	return checkout.Dir, nil
+}
+
+// SymlinkConfig returns the default tool mapping merged with the symlinks section of mindful.yaml.
+func (c *ProjectContext) SymlinkConfig() (*models.SymlinkConfig, error) {
+	return symlink.ResolveConfig(c.ProjectConfig.Symlinks)
+}
+
+// NewSymlinkManager constructs a symlink manager using the project's merged mapping.
+func (c *ProjectContext) NewSymlinkManager() (*symlink.Manager, error) {
+	cfg, err := c.SymlinkConfig()
+	if err != nil {
+		return nil, err
+	}
+	return symlink.NewManager(c.ProjectPath, cfg)
 }
 
 // ResolveOutDir returns mindful/out for the project.
@@ -98,47 +328,154 @@ func (c *ProjectContext) EnsureMindfulStructure() error {
 	return os.MkdirAll(c.ResolveMindfulDir(), 0o755)
 }
 
+// RenderToolOutputs fills artifacts.Tools with the memory and subagents of every
+// enabled tool whose mapping links them.
+func (c *ProjectContext) RenderToolOutputs(artifacts *models.BuildArtifacts) error {
+	symlinkConfig, err := c.SymlinkConfig()
+	if err != nil {
+		return err
+	}
+
+	var targets []source.ToolTarget
+	for _, tool := range c.EnabledMappedTools(symlinkConfig) {
+		toolConfig, _ := symlinkConfig.ToolConfig(tool)
+		target := source.ToolTarget{
+			Name:        tool,
+			Memory:      strings.TrimSpace(toolConfig.Memory) != "",
+			Subagents:   strings.TrimSpace(toolConfig.Subagents) != "",
+			Frontmatter: toolConfig.FrontmatterFormat(),
+		}
+		if target.Memory || target.Subagents {
+			targets = append(targets, target)
+		}
+	}
+
+	outputs, err := c.SourceManager.RenderTools(artifacts, targets, symlinkConfig.ToolNames())
+	if err != nil {
+		return err
+	}
+	artifacts.Tools = outputs
+	return nil
+}
+
+// EnabledMappedTools returns the tools enabled in mindful.yaml that have a mapping, in
+// mapping order. Only these are built, so mindful/out never holds files, such as MCP
+// configurations with resolved secrets, for tools the project does not use.
+func (c *ProjectContext) EnabledMappedTools(symlinkConfig *models.SymlinkConfig) []string {
+	var tools []string
+	for _, tool := range symlinkConfig.ToolNames() {
+		if c.ProjectConfig.IsToolEnabled(tool) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
 // WriteArtifacts writes build artefacts to mindful/out.
+// Files are rewritten in place rather than recreated so hardlinked copies stay attached.
 func (c *ProjectContext) WriteArtifacts(artifacts *models.BuildArtifacts) error {
 	outDir := c.ResolveOutDir()
 
-	if err := os.RemoveAll(outDir); err != nil {
-		return fmt.Errorf("failed to clean %s: %w", outDir, err)
-	}
-
-	if err := os.MkdirAll(filepath.Join(outDir, "subagents"), 0o755); err != nil {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
 		return fmt.Errorf("failed to prepare output directories: %w", err)
 	}
 
-	if artifacts != nil && artifacts.Memory != nil && strings.TrimSpace(artifacts.Memory.Content) != "" {
-		memoryPath := filepath.Join(outDir, "memory.md")
-		if err := os.WriteFile(memoryPath, []byte(artifacts.Memory.Content+"\n"), 0o644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", memoryPath, err)
+	written := make(map[string]struct{})
+	write := func(path string, data []byte, perm os.FileMode) error {
+		if err := os.WriteFile(path, data, perm); err != nil {
+			return err
 		}
+		// WriteFile keeps the mode of an existing file, so tighten it explicitly.
+		if err := os.Chmod(path, perm); err != nil {
+			return err
+		}
+		written[path] = struct{}{}
+		return nil
 	}
 
 	if artifacts != nil {
-		for _, subagent := range artifacts.Subagents {
-			if subagent == nil || subagent.Content == "" {
-				continue
-			}
-			filename := subagent.FileName
-			if filename == "" {
-				filename = subagent.Name + ".mdc"
+		for _, output := range artifacts.Tools {
+			toolDir := filepath.Join(outDir, output.Tool)
+			if strings.TrimSpace(output.Memory) != "" {
+				if err := os.MkdirAll(toolDir, 0o755); err != nil {
+					return fmt.Errorf("failed to prepare %s: %w", toolDir, err)
+				}
+				memoryPath := filepath.Join(toolDir, "memory.md")
+				if err := write(memoryPath, []byte(output.Memory+"\n"), 0o644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", memoryPath, err)
+				}
 			}
-			path := filepath.Join(outDir, "subagents", filename)
-			if err := os.WriteFile(path, []byte(subagent.Content+"\n"), 0o644); err != nil {
-				return fmt.Errorf("failed to write subagent %s: %w", path, err)
+
+			for _, subagent := range output.Subagents {
+				if subagent == nil || subagent.Content == "" {
+					continue
+				}
+				filename := subagent.FileName
+				if filename == "" {
+					filename = subagent.Name + ".mdc"
+				}
+				path := filepath.Join(toolDir, "subagents", filename)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					return fmt.Errorf("failed to prepare %s: %w", filepath.Dir(path), err)
+				}
+				if err := write(path, []byte(subagent.Content+"\n"), 0o644); err != nil {
+					return fmt.Errorf("failed to write subagent %s: %w", path, err)
+				}
 			}
 		}
 
-		if len(artifacts.MCPContent) > 0 {
-			mcpPath := filepath.Join(outDir, "mcp.json")
-			if err := os.WriteFile(mcpPath, artifacts.MCPContent, 0o644); err != nil {
+		for _, output := range artifacts.MCPOutputs {
+			mcpPath := filepath.Join(outDir, output.Tool, models.MCPFormatFileName(output.Format))
+			if err := os.MkdirAll(filepath.Dir(mcpPath), 0o755); err != nil {
+				return fmt.Errorf("failed to prepare %s: %w", filepath.Dir(mcpPath), err)
+			}
+			if err := write(mcpPath, output.Content, 0o600); err != nil {
 				return fmt.Errorf("failed to write %s: %w", mcpPath, err)
 			}
 		}
 	}
 
+	if err := pruneOutDir(outDir, written); err != nil {
+		return fmt.Errorf("failed to clean %s: %w", outDir, err)
+	}
+
+	return nil
+}
+
+// pruneOutDir removes files under outDir that were not produced by the current build,
+// and the directories left empty, such as mindful/out/<tool> for a disabled tool.
+func pruneOutDir(outDir string, keep map[string]struct{}) error {
+	var dirs []string
+	err := filepath.WalkDir(outDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != outDir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if _, ok := keep[path]; ok {
+			return nil
+		}
+		return os.Remove(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Walk order lists parents first, so removing in reverse empties children first.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,496 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"mindful/src/config"
+	"mindful/src/models"
+	"mindful/src/symlink"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the project for broken links, stale artefacts and configuration problems",
+		RunE:  runDoctor,
+		// The report already explains every problem; usage text would only bury it.
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().BoolVar(&doctorFix, "fix", false, "repair what is safe to repair (rebuild, relink, prune orphans)")
+
+	return cmd
+}
+
+// doctorReport collects findings and prints them as they are discovered.
+type doctorReport struct {
+	out      io.Writer
+	problems int
+	refused  []string
+}
+
+func (r *doctorReport) ok(format string, args ...interface{}) {
+	fmt.Fprintf(r.out, "✓ "+format+"\n", args...)
+}
+
+func (r *doctorReport) problem(format string, args ...interface{}) {
+	r.problems++
+	fmt.Fprintf(r.out, "✗ "+format+"\n", args...)
+}
+
+func (r *doctorReport) note(format string, args ...interface{}) {
+	fmt.Fprintf(r.out, "  "+format+"\n", args...)
+}
+
+func (r *doctorReport) fixed(format string, args ...interface{}) {
+	r.problems--
+	fmt.Fprintf(r.out, "  fixed: "+format+"\n", args...)
+}
+
+// refuse records a problem --fix will not repair. Without --fix nothing is attempted,
+// so the hint is printed under the problem instead of in the "Not touched" summary.
+func (r *doctorReport) refuse(format string, args ...interface{}) {
+	if !doctorFix {
+		r.note(format, args...)
+		return
+	}
+	r.refused = append(r.refused, fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) finish() error {
+	if len(r.refused) > 0 {
+		fmt.Fprintln(r.out, "\nNot touched (needs manual attention):")
+		for _, line := range r.refused {
+			fmt.Fprintf(r.out, "  - %s\n", line)
+		}
+	}
+
+	if r.problems <= 0 {
+		fmt.Fprintln(r.out, "\nNo problems found.")
+		return nil
+	}
+	if !doctorFix {
+		fmt.Fprintln(r.out, "\nRun mindful doctor --fix to repair what can be repaired safely.")
+	}
+	return fmt.Errorf("%d problem(s) need attention", r.problems)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := &doctorReport{out: cmd.OutOrStdout()}
+
+	ctx, err := NewProjectContext()
+	if err != nil {
+		report.problem("project configuration: %v", err)
+		return report.finish()
+	}
+	defer ctx.Close()
+	report.ok("mindful.yaml loaded (project %s)", ctx.ProjectConfig.Name)
+
+	sourceOK := checkDoctorSource(ctx, report)
+	if sourceOK {
+		checkDoctorStorage(ctx, report)
+		checkDoctorBuild(ctx, report)
+	}
+
+	manager, err := ctx.NewSymlinkManager()
+	if err != nil {
+		report.problem("symlink mapping: %v", err)
+		return report.finish()
+	}
+
+	tools := checkDoctorMapping(ctx, report)
+	checkDoctorLinks(ctx, manager, tools, report)
+	checkDoctorGitIgnore(ctx, manager, tools, report)
+
+	return report.finish()
+}
+
+func checkDoctorSource(ctx *ProjectContext, report *doctorReport) bool {
+	if err := config.ValidateProjectStructure(ctx.ProjectPath, ctx.ProjectConfig); err != nil {
+		report.problem("project structure: %v", err)
+		return false
+	}
+
+	layers, err := ctx.SourceLayers()
+	if err != nil {
+		report.problem("source cannot be resolved: %v", err)
+		return false
+	}
+
+	healthy := true
+	for _, layer := range layers {
+		if layer.Personal {
+			if _, err := os.Stat(layer.Dir); err == nil {
+				report.ok("user scope: %s (personal, not shared with the team)", layer.Dir)
+			}
+			continue
+		}
+		if layer.Source == "" {
+			continue
+		}
+		info, err := os.Stat(layer.Dir)
+		if err != nil {
+			report.problem("source %s (%s) is not reachable: %v", layer.Name, layer.Dir, err)
+			healthy = false
+			continue
+		}
+		if !info.IsDir() {
+			report.problem("source %s (%s) is not a directory", layer.Name, layer.Dir)
+			healthy = false
+			continue
+		}
+		if gitSource, _ := models.ParseGitSource(layer.Source); gitSource != nil {
+			report.ok("source %s: %s (checkout %s)", layer.Name, layer.Source, layer.Dir)
+			continue
+		}
+		report.ok("source %s: %s", layer.Name, layer.Dir)
+	}
+	if !ctx.ProjectConfig.UserScopeEnabled() {
+		report.ok("user scope: disabled by mindful.yaml")
+	}
+	return healthy
+}
+
+func checkDoctorStorage(ctx *ProjectContext, report *doctorReport) {
+	dbPath, err := ctx.ProjectConfig.GetDatabasePath(ctx.ProjectPath)
+	if err != nil {
+		report.problem("MCP store: %v", err)
+		return
+	}
+
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		report.ok("no MCP store at %s (MCP output disabled)", dbPath)
+		return
+	}
+
+	storageManager, err := ctx.ReadStorageManager()
+	if err != nil {
+		report.problem("MCP store %s is unreadable: %v", dbPath, err)
+		return
+	}
+	records, err := storageManager.ListMCP()
+	if err != nil {
+		report.problem("MCP store %s is unreadable: %v", dbPath, err)
+		return
+	}
+	report.ok("MCP store %s (%d server(s))", dbPath, len(records))
+}
+
+func checkDoctorBuild(ctx *ProjectContext, report *doctorReport) {
+	outDir := ctx.ResolveOutDir()
+	builtAt, err := newestModTime(outDir)
+	if err != nil {
+		report.problem("failed to inspect %s: %v", outDir, err)
+		return
+	}
+
+	layers, err := ctx.SourceLayers()
+	if err != nil {
+		report.problem("failed to resolve sources: %v", err)
+		return
+	}
+	sources, err := ctx.SourceManager.LayerFiles(layers)
+	if err != nil {
+		report.problem("failed to list sources: %v", err)
+		return
+	}
+
+	var newest string
+	var newestAt time.Time
+	for _, path := range sources {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(newestAt) {
+			newest, newestAt = path, info.ModTime()
+		}
+	}
+
+	switch {
+	case builtAt.IsZero():
+		report.problem("%s has not been built", relativeTo(ctx.ProjectPath, outDir))
+	case newestAt.After(builtAt):
+		report.problem("%s is older than %s", relativeTo(ctx.ProjectPath, outDir), newest)
+	default:
+		report.ok("%s is up to date", relativeTo(ctx.ProjectPath, outDir))
+		return
+	}
+
+	if !doctorFix {
+		return
+	}
+	if _, err := executeBuild(ctx); err != nil {
+		report.note("rebuild failed: %v", err)
+		return
+	}
+	report.fixed("rebuilt %s", relativeTo(ctx.ProjectPath, outDir))
+}
+
+func checkDoctorMapping(ctx *ProjectContext, report *doctorReport) []string {
+	cfg, err := ctx.SymlinkConfig()
+	if err != nil {
+		report.problem("symlink mapping: %v", err)
+		return nil
+	}
+
+	var tools []string
+	for _, tool := range ctx.ProjectConfig.GetEnabledTools() {
+		toolConfig, ok := cfg.ToolConfig(tool)
+		if !ok || toolConfig.IsEmpty() {
+			report.problem("enabled tool %s has no symlink mapping; add it under symlinks in mindful.yaml", tool)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	if len(tools) > 0 {
+		report.ok("mapping found for %d enabled tool(s)", len(tools))
+	}
+	return tools
+}
+
+func checkDoctorLinks(ctx *ProjectContext, manager *symlink.Manager, tools []string, report *doctorReport) {
+	for _, tool := range tools {
+		infos, err := manager.ListSymlinks(tool)
+		if err != nil {
+			report.problem("%s: %v", tool, err)
+			continue
+		}
+
+		orphans, err := manager.FindOrphans(tool)
+		if err != nil {
+			report.problem("%s: %v", tool, err)
+		}
+		orphaned := make(map[string]bool, len(orphans))
+		for _, path := range orphans {
+			orphaned[path] = true
+		}
+
+		var repairable []string
+		healthy := true
+		for _, info := range infos {
+			if orphaned[info.LinkPath] {
+				// Reported (and pruned) below as an orphan rather than relinked.
+				continue
+			}
+			problem, fixable := diagnoseLink(ctx, info)
+			if problem == "" {
+				continue
+			}
+			healthy = false
+			report.problem("%s: %s %s", tool, info.LinkPath, problem)
+			if fixable {
+				repairable = append(repairable, info.LinkPath)
+			} else {
+				report.refuse("%s %s (move it away, or use mindful apply --force)", info.LinkPath, problem)
+			}
+		}
+
+		for _, path := range orphans {
+			healthy = false
+			report.problem("%s: orphaned subagent link %s", tool, path)
+		}
+
+		if healthy {
+			report.ok("%s links are healthy", tool)
+			continue
+		}
+		if !doctorFix {
+			continue
+		}
+
+		if len(repairable) > 0 {
+			if err := manager.Apply(tool, symlink.ApplyOptions{}); err != nil {
+				report.note("relinking %s was incomplete: %v", tool, err)
+			}
+			after, err := manager.ListSymlinks(tool)
+			if err != nil {
+				report.note("failed to re-check %s: %v", tool, err)
+			}
+			for _, path := range repairable {
+				if linkHealthy(ctx, after, path) {
+					report.fixed("relinked %s", path)
+				}
+			}
+		}
+
+		if pruned, err := manager.PruneOrphans(tool); err != nil {
+			report.note("pruning %s orphans failed: %v", tool, err)
+		} else {
+			for _, path := range pruned {
+				report.fixed("pruned %s", path)
+			}
+		}
+	}
+}
+
+// linkHealthy reports whether linkPath is absent from infos (removed) or has no problem.
+func linkHealthy(ctx *ProjectContext, infos []models.SymlinkInfo, linkPath string) bool {
+	for _, info := range infos {
+		if info.LinkPath == linkPath {
+			problem, _ := diagnoseLink(ctx, info)
+			return problem == ""
+		}
+	}
+	return true
+}
+
+// diagnoseLink describes what is wrong with a link and whether apply can repair it.
+func diagnoseLink(ctx *ProjectContext, info models.SymlinkInfo) (string, bool) {
+	linkAbs := resolveProjectPath(ctx, info.LinkPath)
+
+	switch info.Status {
+	case models.LinkStatusOK:
+		if info.Mode == models.LinkModeSymlink {
+			if _, err := os.Stat(linkAbs); err != nil {
+				return "is a broken symlink (target missing)", true
+			}
+		}
+		return "", false
+	case models.LinkStatusMissing:
+		if _, err := os.Stat(resolveProjectPath(ctx, info.TargetPath)); os.IsNotExist(err) {
+			// Nothing was built for this artefact, so there is nothing to link.
+			return "", false
+		}
+		return "is missing", true
+	case models.LinkStatusOrphaned:
+		return "is no longer mapped", true
+	case models.LinkStatusConflict:
+		return "is a regular file blocking the link", false
+	case models.LinkStatusModified:
+		return "is a managed copy edited by hand", false
+	}
+
+	if dest, err := os.Readlink(linkAbs); err == nil {
+		if !filepath.IsAbs(dest) {
+			dest = filepath.Join(filepath.Dir(linkAbs), dest)
+		}
+		if _, err := os.Stat(dest); err != nil {
+			return fmt.Sprintf("is a broken symlink to %s", dest), true
+		}
+		return fmt.Sprintf("is a foreign symlink to %s", dest), true
+	}
+	return "is out of date", true
+}
+
+func checkDoctorGitIgnore(ctx *ProjectContext, manager *symlink.Manager, tools []string, report *doctorReport) {
+	if !isGitWorkTree(ctx.ProjectPath) {
+		report.ok("not a git repository, skipping .gitignore checks")
+		return
+	}
+
+	paths := []string{
+		relativeTo(ctx.ProjectPath, ctx.ResolveOutDir()),
+		filepath.Join(models.DefaultMindfulDirName, models.DefaultStateFileName),
+	}
+	// Adopted originals may hold credentials, such as a hand-written .mcp.json.
+	backupDir := filepath.Join(models.DefaultMindfulDirName, models.DefaultBackupDirName)
+	if _, err := os.Stat(filepath.Join(ctx.ProjectPath, backupDir)); err == nil {
+		paths = append(paths, backupDir)
+	}
+	for _, tool := range tools {
+		infos, err := manager.ListSymlinks(tool)
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			if info.Status == models.LinkStatusOrphaned || filepath.IsAbs(info.LinkPath) {
+				continue
+			}
+			// Injected and merged files are shared with the user and may be committed.
+			if info.Mode == models.LinkModeInject || info.Mode == models.LinkModeMerge {
+				continue
+			}
+			paths = append(paths, info.LinkPath)
+		}
+	}
+
+	var uncovered []string
+	for _, path := range paths {
+		ignored, err := isGitIgnored(ctx.ProjectPath, path)
+		if err != nil {
+			report.problem("failed to check .gitignore for %s: %v", path, err)
+			continue
+		}
+		if !ignored {
+			uncovered = append(uncovered, path)
+		}
+	}
+
+	if len(uncovered) == 0 {
+		report.ok("generated paths are git-ignored")
+		return
+	}
+	for _, path := range uncovered {
+		report.problem("%s is not covered by .gitignore", path)
+		report.refuse("add %s to .gitignore", path)
+	}
+}
+
+// isGitWorkTree reports whether dir is inside a git work tree.
+func isGitWorkTree(dir string) bool {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree")
+	output, err := cmd.Output()
+	return err == nil && string(output) == "true\n"
+}
+
+// isGitIgnored reports whether a project-relative path is ignored by git.
+func isGitIgnored(projectPath, path string) (bool, error) {
+	cmd := exec.Command("git", "-C", projectPath, "check-ignore", "-q", "--no-index", path)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+// newestModTime returns the latest modification time of any file under dir.
+func newestModTime(dir string) (time.Time, error) {
+	var newest time.Time
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return time.Time{}, err
+	}
+	return newest, nil
+}
+
+func resolveProjectPath(ctx *ProjectContext, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(ctx.ProjectPath, path)
+}
+
+func relativeTo(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
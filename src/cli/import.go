@@ -1,21 +1,158 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+
+	"mindful/src/importer"
+	"mindful/src/models"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	importTools  string
+	importYes    bool
+	importDryRun bool
+)
+
 func newImportCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "import",
-		Short: "Show instructions for migrating existing files to the symlink workflow",
-		RunE:  runImport,
+		Short: "Migrate existing agent configuration files into mindful sources",
+		Long: `Scan the project for CLAUDE.md, AGENTS.md, .claude/agents/*.md, .cursor/rules/*.mdc,
+.mcp.json and .cursor/mcp.json, and convert them into mindful/project-memory.mdc,
+mindful/project-subagents/ and MCP records in mindful.db.
+
+Identical content found for several tools is imported once. A preview is shown
+before anything is written.`,
+		RunE: runImport,
 	}
+
+	cmd.Flags().StringVarP(&importTools, "tool", "t", "", "comma separated list of tools to import from ("+strings.Join(importer.Tools(), ", ")+"; defaults to all)")
+	cmd.Flags().BoolVarP(&importYes, "yes", "y", false, "write the import without asking for confirmation")
+	cmd.Flags().BoolVar(&importDryRun, "dry-run", false, "show the preview without writing anything")
+
 	return cmd
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
-	fmt.Fprintln(cmd.OutOrStdout(), "Import is no longer required. Use 'mindful build' followed by 'mindful apply' to refresh symlinks.")
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	var tools []string
+	for _, part := range strings.Split(importTools, ",") {
+		if name := strings.TrimSpace(part); name != "" {
+			tools = append(tools, name)
+		}
+	}
+
+	items, err := importer.Scan(ctx.ProjectPath, tools)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no agent configuration files found to import")
+		return nil
+	}
+
+	// The preview only reads the team store, so it works with git sources and does not
+	// take the write lock; the store is reopened for writing once the import is confirmed.
+	store, err := openImportStore(ctx, items, false)
+	if err != nil {
+		return err
+	}
+
+	imp := importer.New(ctx.ProjectPath, store)
+	changes, err := imp.Plan(items)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	fmt.Fprintln(cmd.OutOrStdout(), "Import preview:")
+	for _, change := range changes {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", describeImportChange(ctx.ProjectPath, change))
+		if change.Action != importer.ActionSkip {
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "nothing new to import")
+		return nil
+	}
+	if importDryRun {
+		return nil
+	}
+	if !importYes && !confirm(cmd, fmt.Sprintf("Write %d changes?", pending)) {
+		fmt.Fprintln(cmd.OutOrStdout(), "import cancelled")
+		return nil
+	}
+
+	if store, err = openImportStore(ctx, items, true); err != nil {
+		return err
+	}
+	imp = importer.New(ctx.ProjectPath, store)
+	if err := imp.Apply(changes); err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ imported %d changes; run 'mindful apply --adopt' to replace the originals with links\n", pending)
 	return nil
 }
+
+// openImportStore opens the team store when items include MCP servers, and returns nil
+// otherwise. A team store that does not exist yet previews as empty.
+func openImportStore(ctx *ProjectContext, items []importer.Item, write bool) (importer.Store, error) {
+	needed := false
+	for _, item := range items {
+		if item.Kind == models.ArtifactMCP {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	if write {
+		return ctx.GetStorageManager()
+	}
+	store, err := ctx.ReadStorageManager()
+	if errors.Is(err, os.ErrNotExist) {
+		return emptyImportStore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// emptyImportStore previews an import into a team store that has not been created yet.
+type emptyImportStore struct{}
+
+func (emptyImportStore) ListMCP() (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (emptyImportStore) StoreMCP(serverName string, config string) error {
+	return fmt.Errorf("cannot store MCP server %s: the team store is opened for preview only", serverName)
+}
+
+// confirm asks a yes/no question on the command's input, defaulting to no.
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
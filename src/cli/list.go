@@ -30,12 +30,15 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 	defer ctx.Close()
 
-	manager, err := symlink.NewManager(ctx.ProjectPath, nil)
+	manager, err := ctx.NewSymlinkManager()
 	if err != nil {
 		return err
 	}
 
 	tools := collectListTools(ctx, listTool)
+	if strings.TrimSpace(listTool) == "" {
+		tools = withRecordedTools(manager, tools)
+	}
 	if len(tools) == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), "no symlink mappings available")
 		return nil
@@ -56,7 +59,7 @@ func runList(cmd *cobra.Command, args []string) error {
 
 		for _, info := range infos {
 			status := renderSymlinkStatus(info)
-			fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s -> %s\n", status, info.LinkPath, info.TargetPath)
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-8s %s -> %s%s\n", status, info.LinkPath, info.TargetPath, renderLinkMode(info))
 		}
 	}
 
@@ -64,13 +67,18 @@ func runList(cmd *cobra.Command, args []string) error {
 }
 
 func collectListTools(ctx *ProjectContext, selected string) []string {
-	cfg, err := symlink.DefaultConfig()
+	cfg, err := ctx.SymlinkConfig()
 	if err != nil {
 		tools := ctx.ProjectConfig.GetEnabledTools()
 		sort.Strings(tools)
 		return tools
 	}
-	names := cfg.ToolNames()
+	var names []string
+	for _, name := range cfg.ToolNames() {
+		if tool, ok := cfg.ToolConfig(name); ok && !tool.IsEmpty() {
+			names = append(names, name)
+		}
+	}
 
 	if strings.TrimSpace(selected) != "" {
 		filter := strings.TrimSpace(selected)
@@ -96,8 +104,40 @@ func collectListTools(ctx *ProjectContext, selected string) []string {
 }
 
 func renderSymlinkStatus(info models.SymlinkInfo) string {
+	if info.Status != "" {
+		return info.Status
+	}
 	if info.IsValid {
 		return "ok"
 	}
 	return "missing"
 }
+
+// renderLinkMode annotates non-symlink strategies so copies are easy to spot.
+func renderLinkMode(info models.SymlinkInfo) string {
+	if info.Mode == "" || info.Mode == models.LinkModeSymlink {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", info.Mode)
+}
+
+// withRecordedTools adds tools that still have paths recorded in the apply state,
+// so links left behind by disabled or unmapped tools remain visible.
+func withRecordedTools(manager *symlink.Manager, tools []string) []string {
+	recorded, err := manager.RecordedTools()
+	if err != nil || len(recorded) == 0 {
+		return tools
+	}
+
+	set := make(map[string]struct{}, len(tools)+len(recorded))
+	for _, tool := range append(append([]string{}, tools...), recorded...) {
+		set[tool] = struct{}{}
+	}
+
+	merged := make([]string, 0, len(set))
+	for tool := range set {
+		merged = append(merged, tool)
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,567 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"mindful/src/models"
+	"mindful/src/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpCommand string
+	mcpArgs    []string
+	mcpEnv     []string
+	mcpURL     string
+	mcpHeaders []string
+	mcpJSON    string
+	mcpFile    string
+	mcpForce   bool
+	mcpReveal  bool
+	mcpScope   string
+)
+
+func newMCPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Manage MCP servers stored in mindful.db",
+		Long: `Manage MCP servers stored in mindful.db.
+
+Servers come from three scopes, later ones taking precedence when names clash:
+  team     mindful.db in the team source (default for these commands)
+  project  mindful/mcp.json or mindful/mcp.yaml in the project, edited by hand
+  user     mindful.db in ~/.mindful or $MINDFUL_USER_DIR, for personal servers (--scope user)`,
+	}
+
+	cmd.PersistentFlags().StringVar(&mcpScope, "scope", models.ScopeTeam, "store to manage: team or user")
+
+	cmd.AddCommand(newMCPAddCmd())
+	cmd.AddCommand(newMCPRemoveCmd())
+	cmd.AddCommand(newMCPListCmd())
+	cmd.AddCommand(newMCPShowCmd())
+	cmd.AddCommand(newMCPEditCmd())
+	cmd.AddCommand(newMCPImportCmd())
+	cmd.AddCommand(newMCPCheckCmd())
+
+	return cmd
+}
+
+func newMCPAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add an MCP server from flags or a JSON snippet",
+		Example: `  mindful mcp add github --command npx --arg -y --arg @modelcontextprotocol/server-github --env GITHUB_TOKEN=...
+  mindful mcp add docs --url https://example.com/mcp --header "Authorization: Bearer ..."
+  mindful mcp add fs --json '{"command":"npx","args":["@modelcontextprotocol/server-filesystem","."]}'`,
+		Args: cobra.ExactArgs(1),
+		RunE: runMCPAdd,
+	}
+
+	addServerFlags(cmd)
+	cmd.Flags().BoolVar(&mcpForce, "force", false, "replace an existing server with the same name")
+
+	return cmd
+}
+
+func newMCPRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove MCP servers",
+		Args:    cobra.MinimumNArgs(1),
+		RunE:    runMCPRemove,
+	}
+}
+
+func newMCPListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List stored MCP servers",
+		Args:  cobra.NoArgs,
+		RunE:  runMCPList,
+	}
+}
+
+func newMCPShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show an MCP server definition with secrets masked",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMCPShow,
+	}
+
+	cmd.Flags().BoolVar(&mcpReveal, "reveal", false, "print env and header values in clear text")
+
+	return cmd
+}
+
+func newMCPEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <name>",
+		Short: "Edit an MCP server with flags, or in $EDITOR when no flags are given",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMCPEdit,
+	}
+
+	addServerFlags(cmd)
+
+	return cmd
+}
+
+func newMCPImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import servers from an .mcp.json style file ('-' reads stdin)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMCPImport,
+	}
+
+	cmd.Flags().BoolVar(&mcpForce, "force", false, "replace existing servers with the same name")
+
+	return cmd
+}
+
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&mcpCommand, "command", "", "executable that starts a stdio server")
+	cmd.Flags().StringArrayVar(&mcpArgs, "arg", nil, "argument passed to the command (repeatable)")
+	cmd.Flags().StringArrayVar(&mcpEnv, "env", nil, "environment variable as KEY=VALUE (repeatable)")
+	cmd.Flags().StringVar(&mcpURL, "url", "", "URL of an HTTP/SSE server")
+	cmd.Flags().StringArrayVar(&mcpHeaders, "header", nil, "HTTP header as 'Name: value' (repeatable)")
+	cmd.Flags().StringVar(&mcpJSON, "json", "", "server definition as a JSON object")
+	cmd.Flags().StringVarP(&mcpFile, "file", "f", "", "read the server definition from a JSON file")
+}
+
+func runMCPAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	store, closeStore, err := openMCPStore(true)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	existing, err := store.ListMCP()
+	if err != nil {
+		return fmt.Errorf("failed to list MCP configurations: %w", err)
+	}
+	if _, ok := existing[name]; ok && !mcpForce {
+		return fmt.Errorf("MCP server %s already exists (use --force to replace it or 'mindful mcp edit')", name)
+	}
+
+	server, err := serverFromFlags(cmd, nil)
+	if err != nil {
+		return err
+	}
+	if err := storeServer(store, name, server); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ MCP server %s saved; run 'mindful build' to update mindful/out\n", name)
+	return nil
+}
+
+func runMCPRemove(cmd *cobra.Command, args []string) error {
+	store, closeStore, err := openMCPStore(true)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	for _, name := range args {
+		if err := store.DeleteMCP(name); err != nil {
+			return fmt.Errorf("failed to remove MCP server %s: %w", name, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ MCP server %s removed\n", name)
+	}
+	return nil
+}
+
+func runMCPList(cmd *cobra.Command, args []string) error {
+	store, closeStore, err := openMCPStore(false)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	var records map[string]string
+	if store != nil {
+		if records, err = store.ListMCP(); err != nil {
+			return fmt.Errorf("failed to list MCP configurations: %w", err)
+		}
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no MCP servers stored")
+		return nil
+	}
+
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := &models.MCPConfig{Servers: records}
+	for _, name := range names {
+		server, err := cfg.GetServer(name)
+		if err != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-20s (unreadable: %v)\n", name, err)
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  %-20s %s\n", name, summariseServer(server))
+	}
+	return nil
+}
+
+func runMCPShow(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	store, closeStore, err := openMCPStore(false)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+	if store == nil {
+		return fmt.Errorf("server %s not found", name)
+	}
+
+	server, err := loadServer(store, name)
+	if err != nil {
+		return err
+	}
+	if !mcpReveal {
+		server = models.MaskSecrets(server)
+	}
+
+	data, err := json.MarshalIndent(server, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to render MCP server %s: %w", name, err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return nil
+}
+
+func runMCPEdit(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	store, closeStore, err := openMCPStore(true)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	server, err := loadServer(store, name)
+	if err != nil {
+		return err
+	}
+
+	if serverFlagsChanged(cmd) {
+		server, err = serverFromFlags(cmd, server)
+	} else {
+		server, err = editServerInEditor(cmd, name, server)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := storeServer(store, name, server); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ MCP server %s updated; run 'mindful build' to update mindful/out\n", name)
+	return nil
+}
+
+func runMCPImport(cmd *cobra.Command, args []string) error {
+	data, err := readInput(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := models.FromMCPJSON(data)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if len(cfg.Servers) == 0 {
+		return fmt.Errorf("%s contains no mcpServers", args[0])
+	}
+
+	store, closeStore, err := openMCPStore(true)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	existing, err := store.ListMCP()
+	if err != nil {
+		return fmt.Errorf("failed to list MCP configurations: %w", err)
+	}
+
+	names := cfg.ListServers()
+	sort.Strings(names)
+	imported := 0
+	for _, name := range names {
+		if _, ok := existing[name]; ok && !mcpForce {
+			fmt.Fprintf(cmd.OutOrStdout(), "  skip   %s (already stored, use --force to replace)\n", name)
+			continue
+		}
+		server, err := cfg.GetServer(name)
+		if err != nil {
+			return err
+		}
+		if err := storeServer(store, name, server); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  store  %s\n", name)
+		imported++
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ imported %d MCP servers\n", imported)
+	return nil
+}
+
+// openMCPStore opens the store selected by --scope and returns a function that releases it.
+// Without write the store is opened read-only and is nil when it does not exist yet.
+func openMCPStore(write bool) (*storage.Manager, func(), error) {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var store *storage.Manager
+	switch mcpScope {
+	case models.ScopeTeam, "":
+		if write {
+			store, err = ctx.GetStorageManager()
+		} else if store, err = ctx.ReadStorageManager(); errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+	case models.ScopeUser:
+		store, err = ctx.GetUserStorageManager(write)
+	case models.ScopeProject:
+		err = fmt.Errorf("project servers live in %s/mcp.json; edit that file directly", models.DefaultMindfulDirName)
+	default:
+		err = fmt.Errorf("unknown scope %q, must be team or user", mcpScope)
+	}
+	if err != nil {
+		ctx.Close()
+		return nil, nil, err
+	}
+	return store, func() { ctx.Close() }, nil
+}
+
+func loadServer(store *storage.Manager, name string) (map[string]interface{}, error) {
+	encoded, err := store.RetrieveMCP(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &models.MCPConfig{Servers: map[string]string{name: encoded}}
+	return cfg.GetServer(name)
+}
+
+// storeServer validates a server definition and writes it to storage.
+func storeServer(store *storage.Manager, name string, server map[string]interface{}) error {
+	cfg := models.NewMCPConfig()
+	if err := cfg.AddServer(name, server); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := store.StoreMCP(name, cfg.Servers[name]); err != nil {
+		return fmt.Errorf("failed to store MCP server %s: %w", name, err)
+	}
+	return nil
+}
+
+func serverFlagsChanged(cmd *cobra.Command) bool {
+	for _, flag := range []string{"command", "arg", "env", "url", "header", "json", "file"} {
+		if cmd.Flags().Changed(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverFromFlags builds a server definition from base, a JSON snippet and individual flags,
+// applied in that order.
+func serverFromFlags(cmd *cobra.Command, base map[string]interface{}) (map[string]interface{}, error) {
+	server := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		server[key] = value
+	}
+
+	if mcpJSON != "" && mcpFile != "" {
+		return nil, fmt.Errorf("--json and --file cannot be combined")
+	}
+	snippet := []byte(mcpJSON)
+	if mcpFile != "" {
+		data, err := readInput(cmd, mcpFile)
+		if err != nil {
+			return nil, err
+		}
+		snippet = data
+	}
+	if len(snippet) > 0 {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(snippet, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid server JSON: %w", err)
+		}
+		if _, ok := parsed["mcpServers"]; ok {
+			return nil, fmt.Errorf("the snippet is a whole .mcp.json file; use 'mindful mcp import' instead")
+		}
+		server = parsed
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("command") {
+		server["command"] = mcpCommand
+		delete(server, "url")
+	}
+	if flags.Changed("arg") {
+		args := make([]interface{}, 0, len(mcpArgs))
+		for _, arg := range mcpArgs {
+			args = append(args, arg)
+		}
+		server["args"] = args
+	}
+	if flags.Changed("env") {
+		if err := mergePairs(server, "env", mcpEnv, "="); err != nil {
+			return nil, err
+		}
+	}
+	if flags.Changed("url") {
+		server["url"] = mcpURL
+		delete(server, "command")
+	}
+	if flags.Changed("header") {
+		if err := mergePairs(server, "headers", mcpHeaders, ":", "="); err != nil {
+			return nil, err
+		}
+	}
+
+	return server, nil
+}
+
+// mergePairs parses KEY<sep>VALUE flags into the object stored at server[field].
+func mergePairs(server map[string]interface{}, field string, pairs []string, seps ...string) error {
+	values, _ := server[field].(map[string]interface{})
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	for _, pair := range pairs {
+		var key, value string
+		found := false
+		for _, sep := range seps {
+			if key, value, found = strings.Cut(pair, sep); found {
+				break
+			}
+		}
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("invalid %s value %q: expected KEY%sVALUE", field, pair, seps[0])
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+
+	server[field] = values
+	return nil
+}
+
+// summariseServer describes a server on one line without revealing secrets.
+func summariseServer(server map[string]interface{}) string {
+	var parts []string
+	if command, ok := server["command"]; ok {
+		line := fmt.Sprint(command)
+		if args, ok := server["args"].([]interface{}); ok {
+			for _, arg := range args {
+				line += " " + fmt.Sprint(arg)
+			}
+		}
+		parts = append(parts, line)
+	}
+	if url, ok := server["url"]; ok {
+		parts = append(parts, fmt.Sprint(url))
+	}
+	for _, field := range []string{"env", "headers"} {
+		if values, ok := server[field].(map[string]interface{}); ok && len(values) > 0 {
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			parts = append(parts, fmt.Sprintf("%s: %s", field, strings.Join(keys, ",")))
+		}
+	}
+	if disabled, ok := server["disabled"].(bool); ok && disabled {
+		parts = append(parts, "(disabled)")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// editServerInEditor opens the server definition in $EDITOR and parses the result.
+func editServerInEditor(cmd *cobra.Command, name string, server map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.MarshalIndent(server, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render MCP server %s: %w", name, err)
+	}
+
+	tmp, err := os.CreateTemp("", "mindful-mcp-"+name+"-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	run := exec.Command(editor[0], append(editor[1:], tmp.Name())...)
+	run.Stdin = cmd.InOrStdin()
+	run.Stdout = cmd.OutOrStdout()
+	run.Stderr = cmd.ErrOrStderr()
+	if err := run.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(edited, &result); err != nil {
+		return nil, fmt.Errorf("edited definition is not a JSON object: %w", err)
+	}
+	return result, nil
+}
+
+// readInput reads a file, or stdin when path is "-".
+func readInput(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
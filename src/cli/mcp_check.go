@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mindful/src/mcpcheck"
+	"mindful/src/models"
+
+	"github.com/spf13/cobra"
+)
+
+// stderrTailLines is how many lines of a failing server's stderr are printed.
+const stderrTailLines = 10
+
+var mcpCheckTimeout time.Duration
+
+func newMCPCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check [name...]",
+		Short: "Start each MCP server and verify it answers initialize and tools/list",
+		Long: `Start each MCP server selected for the project (or the named ones), resolve its
+placeholders, perform the MCP initialize handshake and list its tools.
+
+stdio servers are launched as child processes; http and sse servers are contacted at
+their url. A failing server is reported with the tail of its stderr.`,
+		RunE: runMCPCheck,
+	}
+
+	cmd.Flags().DurationVar(&mcpCheckTimeout, "timeout", mcpcheck.DefaultTimeout, "time allowed per server for start-up, handshake and tools/list")
+
+	return cmd
+}
+
+func runMCPCheck(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	servers, _, warnings, err := loadMCPServers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning)
+	}
+
+	servers, err = selectServersByName(servers, args)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no MCP servers to check")
+		return nil
+	}
+
+	results := make([]*mcpcheck.Result, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		// Resolve each server on its own so a missing secret only fails that server.
+		resolver := newPlaceholderResolver(ctx)
+		resolver.values = true
+		resolved, _, _, err := resolver.resolve([]*models.MCPServer{server}, models.MCPFormatStandard)
+		if err == nil {
+			err = resolver.err()
+		}
+		if err != nil {
+			results[i] = &mcpcheck.Result{Server: server.Name, Transport: server.EffectiveTransport(), Err: err}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, server *models.MCPServer) {
+			defer wg.Done()
+			results[i] = mcpcheck.Check(checkContext(cmd), server, mcpcheck.Options{
+				Timeout:   mcpCheckTimeout,
+				Dir:       ctx.ProjectPath,
+				ClientVer: mindfulVersion,
+			})
+		}(i, resolved[0])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		printCheckResult(cmd, result)
+		if !result.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d MCP servers failed the check", failed, len(results))
+	}
+	return nil
+}
+
+// selectServersByName keeps the named servers, in the order given.
+func selectServersByName(servers []*models.MCPServer, names []string) ([]*models.MCPServer, error) {
+	if len(names) == 0 {
+		return servers, nil
+	}
+
+	byName := make(map[string]*models.MCPServer, len(servers))
+	for _, server := range servers {
+		byName[server.Name] = server
+	}
+
+	var selected []*models.MCPServer
+	var unknown []string
+	for _, name := range names {
+		server, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		selected = append(selected, server)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("MCP servers not configured for this project (or disabled): %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+func printCheckResult(cmd *cobra.Command, result *mcpcheck.Result) {
+	out := cmd.OutOrStdout()
+	if result.OK() {
+		name := result.ServerName
+		if result.ServerVersion != "" {
+			name += " " + result.ServerVersion
+		}
+		fmt.Fprintf(out, "✓ %-20s %-5s %s, protocol %s, %d tools (%s)\n",
+			result.Server, result.Transport, name, result.ProtocolVersion, result.Tools, result.Duration.Round(time.Millisecond))
+		return
+	}
+
+	fmt.Fprintf(out, "✗ %-20s %-5s %v\n", result.Server, result.Transport, result.Err)
+	if result.Stderr == "" {
+		return
+	}
+	lines := strings.Split(result.Stderr, "\n")
+	if len(lines) > stderrTailLines {
+		lines = lines[len(lines)-stderrTailLines:]
+	}
+	fmt.Fprintln(out, "    stderr:")
+	for _, line := range lines {
+		fmt.Fprintf(out, "    | %s\n", line)
+	}
+}
+
+// checkContext returns the command context, which is nil when run outside Execute.
+func checkContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
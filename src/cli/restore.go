@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreFrom  string
+	restoreList  bool
+	restoreForce bool
+)
+
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Put files adopted by 'apply --adopt' back in place",
+		RunE:  runRestore,
+	}
+
+	cmd.Flags().StringVar(&restoreFrom, "from", "", "backup to restore (defaults to the most recent one)")
+	cmd.Flags().BoolVar(&restoreList, "list", false, "list available backups without restoring")
+	cmd.Flags().BoolVar(&restoreForce, "force", false, "overwrite paths that were edited after they were adopted")
+
+	return cmd
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	manager, err := ctx.NewSymlinkManager()
+	if err != nil {
+		return err
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	if restoreList {
+		if len(backups) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no backups available")
+			return nil
+		}
+		for _, backup := range backups {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%d files)\n", backup.Name, len(backup.Entries))
+			for _, entry := range backup.Entries {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s [%s]\n", entry.Path, entry.Tool)
+			}
+		}
+		return nil
+	}
+
+	name := restoreFrom
+	if name == "" {
+		if len(backups) == 0 {
+			return fmt.Errorf("no backups available in %s", relativeTo(ctx.ProjectPath, ctx.ResolveMindfulDir()))
+		}
+		name = backups[len(backups)-1].Name
+	}
+
+	restored, err := manager.Restore(name, restoreForce)
+	for _, entry := range restored {
+		fmt.Fprintf(cmd.OutOrStdout(), "  restored %s\n", entry.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("restore from %s incomplete: %w", name, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ restored %d files from backup %s\n", len(restored), name)
+	return nil
+}
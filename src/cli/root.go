@@ -12,22 +12,16 @@ var (
 	verboseFlag     bool
 )
 
-// rootCmd represents the base command when called without any subcommands.
-var rootCmd = &cobra.Command{
-	Use:   "mindful",
-	Short: "Mindful keeps AI assistant configurations in sync",
-	Long: `Mindful builds project configuration artefacts once and shares them across tools via symlinks.
+// NewRootCmd builds the mindful command tree. Flags are bound to package
+// variables, so building a new tree also resets them to their defaults.
+func NewRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "mindful",
+		Short: "Mindful keeps AI assistant configurations in sync",
+		Long: `Mindful builds project configuration artefacts once and shares them across tools via symlinks.
 
 Use "mindful build" to render mindful/out, and "mindful apply" to link the artefacts to enabled tools.`,
-}
-
-// Execute runs the CLI.
-func Execute() error {
-	return rootCmd.Execute()
-}
-
-func init() {
-	cobra.OnInitialize(applyProjectFlag)
+	}
 
 	rootCmd.PersistentFlags().StringVar(&projectPathFlag, "project", "", "path to the mindful project (defaults to current directory)")
 	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "enable verbose output")
@@ -36,8 +30,24 @@ func init() {
 	rootCmd.AddCommand(newBuildCmd())
 	rootCmd.AddCommand(newApplyCmd())
 	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newCleanCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newRestoreCmd())
+	rootCmd.AddCommand(newMCPCmd())
+	rootCmd.AddCommand(newSecretsCmd())
 	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newSourceCmd())
 	rootCmd.AddCommand(newVersionCmd())
+	return rootCmd
+}
+
+// Execute runs the CLI.
+func Execute() error {
+	return NewRootCmd().Execute()
+}
+
+func init() {
+	cobra.OnInitialize(applyProjectFlag)
 }
 
 func applyProjectFlag() {
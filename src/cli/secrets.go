@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mindful/src/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// newPassphraseEnv holds the passphrase that rotate-key switches to.
+const newPassphraseEnv = "MINDFUL_NEW_PASSPHRASE"
+
+var (
+	rotateKeyFile string
+	secretStdin   bool
+)
+
+func newSecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage personal secrets and the encryption of mindful.db",
+		Long: `Secrets are kept in a per-user database (` + storage.SecretsDBEnv + ` or the user config
+directory), never in the project or team source. MCP server definitions reference
+them as ${secret:NAME}; ${env:NAME} reads an environment variable instead. Both
+are resolved by 'mindful build'.`,
+	}
+
+	cmd.AddCommand(newSecretsSetCmd())
+	cmd.AddCommand(newSecretsListCmd())
+	cmd.AddCommand(newSecretsUnsetCmd())
+	cmd.AddCommand(newSecretsInitKeyCmd())
+	cmd.AddCommand(newSecretsRotateKeyCmd())
+
+	return cmd
+}
+
+func newSecretsSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <name> [value]",
+		Short: "Store a secret (prompts for the value when it is not given)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runSecretsSet,
+	}
+
+	cmd.Flags().BoolVar(&secretStdin, "stdin", false, "read the value from stdin instead of prompting")
+
+	return cmd
+}
+
+func newSecretsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List stored secret names",
+		Args:  cobra.NoArgs,
+		RunE:  runSecretsList,
+	}
+}
+
+func newSecretsUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <name>...",
+		Short: "Remove stored secrets",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runSecretsUnset,
+	}
+}
+
+func newSecretsInitKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init-key",
+		Short: "Create the key file that encrypts mindful.db and the secrets database",
+		Long: `Generate a random key in ` + storage.KeyFileEnv + ` (or the default key file in the user
+config directory). Nothing creates a key implicitly: a team that shares mindful.db
+should create the key once and hand the same file to every teammate, who copies it
+into place instead of running init-key.`,
+		Args: cobra.NoArgs,
+		RunE: runSecretsInitKey,
+	}
+}
+
+func newSecretsRotateKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Re-encrypt mindful.db, the user store and the secrets database with a new key",
+		Long: `Decrypt every stored MCP configuration and secret with the current key and encrypt
+it again with a new one.
+
+The current key comes from ` + storage.PassphraseEnv + ` or ` + storage.KeyFileEnv + `. The new key is:
+  - the passphrase in ` + newPassphraseEnv + ` when it is set,
+  - a freshly generated key written to --key-file when given,
+  - otherwise a freshly generated key replacing the current key file.
+
+The new key is staged in <key file>.new and only moved into place once every database
+has been re-encrypted; the replaced key is kept in <key file>.old. The default key file
+in the user config directory is shared by every project, so projects that were not
+rotated need the old key.`,
+		Args: cobra.NoArgs,
+		RunE: runSecretsRotateKey,
+	}
+
+	cmd.Flags().StringVar(&rotateKeyFile, "key-file", "", "write the new key to this file instead of the current key file")
+
+	return cmd
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	var value string
+	switch {
+	case len(args) == 2:
+		value = args[1]
+	case secretStdin:
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	default:
+		fmt.Fprintf(cmd.OutOrStdout(), "Value for %s: ", name)
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read value: %w", err)
+		}
+		value = strings.TrimRight(line, "\r\n")
+	}
+	if value == "" {
+		return fmt.Errorf("secret %s cannot be empty", name)
+	}
+
+	store, err := openSecretStore(true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.StoreSecret(name, value); err != nil {
+		return fmt.Errorf("failed to store secret %s: %w", name, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ secret %s saved; reference it as ${secret:%s}\n", name, name)
+	return nil
+}
+
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	var names []string
+	store, err := openSecretStore(false)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// Nothing has been stored yet.
+	case err != nil:
+		return err
+	default:
+		defer store.Close()
+		if names, err = store.ListSecrets(); err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no secrets stored")
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
+	}
+	return nil
+}
+
+func runSecretsUnset(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore(true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, name := range args {
+		if err := store.DeleteSecret(name); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ secret %s removed\n", name)
+	}
+	return nil
+}
+
+func runSecretsInitKey(cmd *cobra.Command, args []string) error {
+	keys := storage.KeySourceFromEnv()
+	if keys.Passphrase != "" {
+		return fmt.Errorf("%s is set, so no key file is used; unset it to create a key file", storage.PassphraseEnv)
+	}
+	if keys.KeyFile == "" {
+		return fmt.Errorf("no key file location available; set %s", storage.KeyFileEnv)
+	}
+	if _, err := os.Stat(keys.KeyFile); err == nil {
+		return fmt.Errorf("%s already exists; use mindful secrets rotate-key to replace it", keys.KeyFile)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to inspect key file %s: %w", keys.KeyFile, err)
+	}
+
+	if err := storage.GenerateKeyFile(keys.KeyFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ created key file %s\n", keys.KeyFile)
+	fmt.Fprintln(cmd.OutOrStdout(), "  share it with teammates who use the same mindful.db; keep it out of version control")
+	return nil
+}
+
+func runSecretsRotateKey(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	current := storage.KeySourceFromEnv()
+	var next storage.KeySource
+	var finalKeyFile string
+	switch {
+	case os.Getenv(newPassphraseEnv) != "" && rotateKeyFile != "":
+		return fmt.Errorf("%s and --key-file cannot be combined", newPassphraseEnv)
+	case os.Getenv(newPassphraseEnv) != "":
+		next.Passphrase = os.Getenv(newPassphraseEnv)
+	case rotateKeyFile != "":
+		finalKeyFile = rotateKeyFile
+	case current.Passphrase != "":
+		return fmt.Errorf("the current key is a passphrase; set %s or pass --key-file to choose the new key", newPassphraseEnv)
+	default:
+		finalKeyFile = current.KeyFile
+	}
+
+	stores := make(map[string]*storage.Manager)
+	if store, err := ctx.GetStorageManager(); err != nil {
+		return err
+	} else {
+		stores["mindful.db"] = store
+	}
+	if store, err := ctx.GetSecretStore(); err != nil {
+		return err
+	} else {
+		stores["secrets database"] = store
+	}
+	// The user store is encrypted with the same key; it is only rotated when it exists.
+	if path, err := ctx.UserDatabasePath(); err != nil {
+		return err
+	} else if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			store, err := ctx.GetUserStorageManager(true)
+			if err != nil {
+				return err
+			}
+			stores["user mindful.db"] = store
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to inspect user storage at %s: %w", path, err)
+		}
+	}
+
+	// Verify every database opens with the current key before anything is rewritten.
+	for label, store := range stores {
+		if err := store.CheckKey(); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+	}
+
+	if finalKeyFile != "" {
+		// The new key is staged next to its destination and only moved into place
+		// once every database has been re-encrypted with it.
+		// A staged key left by an earlier run may be the only copy of the key some
+		// databases were re-encrypted with, so it is kept on every error path.
+		next.KeyFile = finalKeyFile + ".new"
+		if _, err := os.Stat(next.KeyFile); err == nil {
+			return fmt.Errorf("%s already exists from an earlier rotation; move it aside before rotating again", next.KeyFile)
+		}
+		if err := storage.GenerateKeyFile(next.KeyFile); err != nil {
+			return err
+		}
+	}
+
+	labels := make([]string, 0, len(stores))
+	for label := range stores {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		count, err := stores[label].RotateKey(next)
+		if err != nil {
+			if finalKeyFile != "" {
+				return fmt.Errorf("%s: %w (databases rotated before this one need the key in %s)", label, err, next.KeyFile)
+			}
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s: re-encrypted %d values\n", label, count)
+	}
+
+	var previousKeyFile string
+	if finalKeyFile != "" {
+		// The replaced key is kept: other projects may still be encrypted with it.
+		if _, err := os.Stat(finalKeyFile); err == nil {
+			previousKeyFile = finalKeyFile + ".old"
+			if err := os.Rename(finalKeyFile, previousKeyFile); err != nil {
+				return fmt.Errorf("storage was re-encrypted but the key it replaces could not be moved to %s (the new key is in %s): %w", previousKeyFile, next.KeyFile, err)
+			}
+		}
+		if err := os.Rename(next.KeyFile, finalKeyFile); err != nil {
+			return fmt.Errorf("storage was re-encrypted but the new key could not be moved to %s (it is still in %s): %w", finalKeyFile, next.KeyFile, err)
+		}
+		next.KeyFile = finalKeyFile
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ key rotated to the %s\n", next.Describe())
+	if next.Passphrase != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "  use %s=<new passphrase> from now on\n", storage.PassphraseEnv)
+	} else if next.KeyFile != current.KeyFile || current.Passphrase != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "  point %s at %s from now on\n", storage.KeyFileEnv, next.KeyFile)
+	}
+	if previousKeyFile != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "  the previous key was kept in %s\n", previousKeyFile)
+		if isDefaultKeyFile(finalKeyFile) {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s is shared by every project: point %s at %s to open projects that were not rotated\n", finalKeyFile, storage.KeyFileEnv, previousKeyFile)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "  share the new key with everyone who uses these databases")
+	}
+	return nil
+}
+
+// isDefaultKeyFile reports whether path is the key file shared by every project.
+func isDefaultKeyFile(path string) bool {
+	shared := storage.DefaultKeyFile()
+	if shared == "" {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return abs == filepath.Clean(shared)
+}
+
+// openSecretStore opens the per-user secrets database, read-only unless write is set.
+func openSecretStore(write bool) (*storage.Manager, error) {
+	path, err := storage.SecretsPath()
+	if err != nil {
+		return nil, err
+	}
+	open := storage.NewReadOnlyManager
+	if write {
+		open = storage.NewSecretStore
+	}
+	store, err := open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets database at %s: %w", path, err)
+	}
+	return store, nil
+}
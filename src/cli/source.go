@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"mindful/src/models"
+	"mindful/src/source"
+
+	"github.com/spf13/cobra"
+)
+
+func newSourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "source",
+		Short: "Manage the project's shared sources",
+		Long: `Manage the shared sources listed in mindful.yaml.
+
+A source written as git+https://, git+ssh:// or git+file:// (optionally followed by
+#<branch, tag or commit>) is cloned into the user cache the first time it is needed
+and then used as it is until 'mindful source update' fetches it again.
+
+mindful/mindful.lock pins the revision each source is built from: the commit of a
+git source, or a content hash of each file of a local one. 'mindful build' writes
+missing pins and otherwise builds from the pinned commits; 'mindful source upgrade'
+moves the pins to the latest revisions and summarises what changed.`,
+	}
+
+	cmd.AddCommand(newSourceUpdateCmd())
+	cmd.AddCommand(newSourceUpgradeCmd())
+
+	return cmd
+}
+
+func newSourceUpdateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [name...]",
+		Short: "Fetch git sources into the cache without moving mindful.lock",
+		RunE:  runSourceUpdate,
+	}
+}
+
+func runSourceUpdate(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	layers, err := selectSourceLayers(ctx, args)
+	if err != nil {
+		return err
+	}
+	lock, err := ctx.ConfigManager.LoadLock(ctx.ProjectPath)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, layer := range layers {
+		gitSource, err := models.ParseGitSource(layer.Path)
+		if err != nil {
+			return err
+		}
+		if gitSource == nil {
+			fmt.Fprintf(out, "  %s: %s is a local directory; nothing to update\n", layer.Name, layer.Path)
+			continue
+		}
+
+		checkout, err := ctx.SourceManager.SyncGitSource(gitSource, true)
+		if err != nil {
+			return fmt.Errorf("source %s: %w", layer.Name, err)
+		}
+		switch {
+		case checkout.Cloned:
+			fmt.Fprintf(out, "✓ %s: cloned %s at %s\n", layer.Name, gitSource, shortCommit(checkout.Commit))
+		case checkout.Changed():
+			fmt.Fprintf(out, "✓ %s: updated %s: %s → %s\n", layer.Name, gitSource, shortCommit(checkout.Previous), shortCommit(checkout.Commit))
+		default:
+			fmt.Fprintf(out, "✓ %s: %s is up to date at %s\n", layer.Name, gitSource, shortCommit(checkout.Commit))
+		}
+		fmt.Fprintf(out, "  checkout: %s\n", checkout.Dir)
+		if pin := lock.Find(layer.Name, layer.Path); pin != nil && pin.Commit != checkout.Commit {
+			fmt.Fprintf(out, "  %s pins %s; run 'mindful source upgrade %s' to build from the new commit\n", models.LockFileName, shortCommit(pin.Commit), layer.Name)
+		}
+	}
+	return nil
+}
+
+func newSourceUpgradeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade [name...]",
+		Short: "Move mindful.lock to the latest revision of each source and summarise the changes",
+		RunE:  runSourceUpgrade,
+	}
+}
+
+func runSourceUpgrade(cmd *cobra.Command, args []string) error {
+	ctx, err := NewProjectContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	selected, err := selectSourceLayers(ctx, args)
+	if err != nil {
+		return err
+	}
+	lock, err := ctx.ConfigManager.LoadLock(ctx.ProjectPath)
+	if err != nil {
+		return err
+	}
+
+	upgrades := make(map[string]*models.SourceLock, len(selected))
+	for _, layer := range selected {
+		next, err := upgradeSourceLayer(cmd, ctx, layer, lock.Find(layer.Name, layer.Path))
+		if err != nil {
+			return fmt.Errorf("source %s: %w", layer.Name, err)
+		}
+		upgrades[layer.Name] = next
+	}
+
+	// Layers that were not selected keep their pins.
+	configured, err := ctx.ProjectConfig.SourceLayers()
+	if err != nil {
+		return err
+	}
+	next := &models.LockFile{}
+	for _, layer := range configured {
+		if pin, ok := upgrades[layer.Name]; ok {
+			next.Sources = append(next.Sources, pin)
+		} else if pin := lock.Find(layer.Name, layer.Path); pin != nil {
+			next.Sources = append(next.Sources, pin)
+		}
+	}
+
+	if err := ctx.ConfigManager.SaveLock(ctx.ProjectPath, next); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "  run 'mindful build' to use it and commit %s/%s\n", models.DefaultMindfulDirName, models.LockFileName)
+	return nil
+}
+
+// upgradeSourceLayer fetches one source, prints what changed since pin and returns
+// the new pin.
+func upgradeSourceLayer(cmd *cobra.Command, ctx *ProjectContext, layer *models.SourceLayer, pin *models.SourceLock) (*models.SourceLock, error) {
+	gitSource, err := models.ParseGitSource(layer.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dir string
+	if gitSource != nil {
+		checkout, err := ctx.SourceManager.SyncGitSource(gitSource, true)
+		if err != nil {
+			return nil, err
+		}
+		dir = checkout.Dir
+	} else if dir, err = models.ResolveSourcePath(layer.Path, ctx.ProjectPath); err != nil {
+		return nil, err
+	}
+
+	next, err := ctx.SourceManager.SnapshotSource(layer.Path, dir)
+	if err != nil {
+		return nil, err
+	}
+	next.Name = layer.Name
+
+	out := cmd.OutOrStdout()
+	switch {
+	case pin == nil:
+		if gitSource != nil {
+			fmt.Fprintf(out, "✓ %s: pinned %s at %s\n", layer.Name, layer.Path, shortCommit(next.Commit))
+		} else {
+			fmt.Fprintf(out, "✓ %s: pinned %s (%d files)\n", layer.Name, layer.Path, len(next.Files))
+		}
+	case gitSource != nil:
+		if pin.Commit == next.Commit {
+			fmt.Fprintf(out, "✓ %s: already pinned at the latest commit %s\n", layer.Name, shortCommit(next.Commit))
+			return next, nil
+		}
+		changes, err := ctx.SourceManager.DiffGitSource(dir, pin.Commit, next.Commit)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "✓ %s: upgraded %s: %s → %s\n", layer.Name, layer.Path, shortCommit(pin.Commit), shortCommit(next.Commit))
+		printUpgradeSection(cmd, "commits", changes.Commits)
+		var files []string
+		for _, line := range changes.Files {
+			status, path, _ := strings.Cut(line, "\t")
+			files = append(files, fileChangeLine(status, path))
+		}
+		printUpgradeSection(cmd, "changed sources", files)
+	default:
+		changes := pin.DiffFiles(next)
+		if changes.Empty() {
+			fmt.Fprintf(out, "✓ %s: %s has not changed since it was pinned\n", layer.Name, layer.Path)
+			return next, nil
+		}
+		fmt.Fprintf(out, "✓ %s: upgraded %s (%s)\n", layer.Name, layer.Path, describeFileChanges(changes))
+		var files []string
+		for _, path := range changes.Added {
+			files = append(files, fileChangeLine("A", path))
+		}
+		for _, path := range changes.Changed {
+			files = append(files, fileChangeLine("M", path))
+		}
+		for _, path := range changes.Removed {
+			files = append(files, fileChangeLine("D", path))
+		}
+		printUpgradeSection(cmd, "changed sources", files)
+	}
+	return next, nil
+}
+
+// selectSourceLayers returns the shared sources named in args, or all of them.
+func selectSourceLayers(ctx *ProjectContext, names []string) ([]*models.SourceLayer, error) {
+	configured, err := ctx.ProjectConfig.SourceLayers()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return configured, nil
+	}
+
+	byName := make(map[string]*models.SourceLayer, len(configured))
+	for _, layer := range configured {
+		byName[layer.Name] = layer
+	}
+	var selected []*models.SourceLayer
+	for _, name := range names {
+		layer, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("no source named %s in mindful.yaml", name)
+		}
+		selected = append(selected, layer)
+	}
+	return selected, nil
+}
+
+// pinSources writes mindful.lock when a shared source has no pin yet. Git sources
+// are already checked out at their pinned commit; local sources cannot be pinned,
+// so a warning lists the files that changed since their pin was written.
+func pinSources(ctx *ProjectContext, layers []source.Layer) ([]string, error) {
+	lock, err := ctx.ConfigManager.LoadLock(ctx.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &models.LockFile{}
+	rewrite := lock == nil
+	var warnings []string
+	for _, layer := range layers {
+		if layer.Source == "" {
+			continue
+		}
+		current, err := ctx.SourceManager.SnapshotSource(layer.Source, layer.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", layer.Name, err)
+		}
+		current.Name = layer.Name
+
+		pin := lock.Find(layer.Name, layer.Source)
+		if pin == nil {
+			next.Sources = append(next.Sources, current)
+			rewrite = true
+			continue
+		}
+		next.Sources = append(next.Sources, pin)
+		if current.Commit != "" {
+			continue
+		}
+		if changes := pin.DiffFiles(current); !changes.Empty() {
+			warnings = append(warnings, fmt.Sprintf("source %s (%s) changed since %s was written (%s); building from the current files, run 'mindful source upgrade %s' to accept them",
+				layer.Name, layer.Dir, models.LockFileName, describeFileChanges(changes), layer.Name))
+		}
+	}
+
+	if lock != nil && len(lock.Sources) != len(next.Sources) {
+		// Drop pins of sources that were removed from mindful.yaml.
+		rewrite = true
+	}
+	if rewrite {
+		if err := ctx.ConfigManager.SaveLock(ctx.ProjectPath, next); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+func describeFileChanges(changes models.SourceFileChanges) string {
+	var parts []string
+	for _, part := range []struct {
+		label string
+		paths []string
+	}{{"added", changes.Added}, {"changed", changes.Changed}, {"removed", changes.Removed}} {
+		if len(part.paths) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", part.label, strings.Join(part.paths, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func fileChangeLine(status, path string) string {
+	switch {
+	case strings.HasPrefix(status, "A"):
+		return "+ " + path
+	case strings.HasPrefix(status, "D"):
+		return "- " + path
+	default:
+		return "~ " + path
+	}
+}
+
+func printUpgradeSection(cmd *cobra.Command, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "  %s:\n", title)
+	for _, line := range lines {
+		fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", line)
+	}
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
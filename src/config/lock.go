@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mindful/src/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+const lockFileHeader = "# Generated by mindful. Commit it next to mindful.yaml; run 'mindful source upgrade' to move the pin.\n"
+
+// LockPath returns mindful/mindful.lock for the project.
+func LockPath(projectPath string) string {
+	return filepath.Join(projectPath, models.DefaultMindfulDirName, models.LockFileName)
+}
+
+// LoadLock reads mindful/mindful.lock. It returns nil when the project has no lock yet.
+func (m *Manager) LoadLock(projectPath string) (*models.LockFile, error) {
+	data, err := os.ReadFile(LockPath(projectPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", models.LockFileName, err)
+	}
+
+	var lock models.LockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", models.LockFileName, err)
+	}
+	return &lock, nil
+}
+
+// SaveLock writes mindful/mindful.lock.
+func (m *Manager) SaveLock(projectPath string, lock *models.LockFile) error {
+	if lock == nil {
+		return fmt.Errorf("lock cannot be nil")
+	}
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", models.LockFileName, err)
+	}
+
+	lockPath := LockPath(projectPath)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create mindful directory: %w", err)
+	}
+	if err := os.WriteFile(lockPath, append([]byte(lockFileHeader), data...), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", models.LockFileName, err)
+	}
+	return nil
+}
@@ -93,6 +93,14 @@ func (m *Manager) ValidateProject(projectPath string, config *models.ProjectConf
 		return err
 	}
 
+	if err := ValidateSymlinkOverrides(config); err != nil {
+		return err
+	}
+
+	if err := ValidateMCPSettings(config); err != nil {
+		return err
+	}
+
 	if err := ValidateProjectStructure(projectPath, config); err != nil {
 		return err
 	}
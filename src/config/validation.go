@@ -3,10 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
 	"mindful/src/models"
+	"mindful/src/symlink"
 )
 
 // ValidateProjectStructure validates the project directory structure
@@ -15,13 +17,26 @@ func ValidateProjectStructure(projectPath string, config *models.ProjectConfig)
 		return fmt.Errorf("config cannot be nil")
 	}
 
-	// Validate source path exists or can be created
-	sourcePath, err := config.ResolveSourceRoot(projectPath)
+	layers, err := config.SourceLayers()
 	if err != nil {
 		return fmt.Errorf("invalid source path: %w", err)
 	}
-	if err := validatePath(sourcePath, "source directory"); err != nil {
-		return err
+	for _, layer := range layers {
+		// Git sources are cloned into the user cache on first use.
+		if gitSource, err := models.ParseGitSource(layer.Path); err != nil {
+			return fmt.Errorf("invalid source path: %w", err)
+		} else if gitSource != nil {
+			continue
+		}
+
+		// Validate source path exists or can be created
+		sourcePath, err := models.ResolveSourcePath(layer.Path, projectPath)
+		if err != nil {
+			return fmt.Errorf("invalid source path: %w", err)
+		}
+		if err := validatePath(sourcePath, "source directory"); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -58,6 +73,111 @@ func ValidateToolConfiguration(config *models.ProjectConfig) error {
 	return nil
 }
 
+const linkModeList = "symlink, copy, hardlink, inject or merge"
+
+// ValidateSymlinkOverrides validates the symlinks section of mindful.yaml
+func ValidateSymlinkOverrides(config *models.ProjectConfig) error {
+	if config == nil {
+		return fmt.Errorf("config cannot be nil")
+	}
+
+	for toolName, override := range config.Symlinks {
+		if strings.TrimSpace(toolName) == "" {
+			return fmt.Errorf("symlinks cannot contain empty tool names")
+		}
+		if override == nil {
+			continue
+		}
+
+		if override.Subagents != nil {
+			template := strings.TrimSpace(*override.Subagents)
+			if template != "" && !strings.Contains(template, symlink.SubagentPlaceholder) {
+				return fmt.Errorf("symlinks.%s.subagents must contain the %s placeholder (got '%s')", toolName, symlink.SubagentPlaceholder, template)
+			}
+		}
+
+		if override.MCPFormat != nil {
+			format := strings.TrimSpace(*override.MCPFormat)
+			if format != "" && !models.IsValidMCPFormat(format) {
+				return fmt.Errorf("invalid mcp_format '%s' for symlinks.%s, must be one of %s", format, toolName, strings.Join(models.MCPFormatNames(), ", "))
+			}
+		}
+
+		if override.Frontmatter != nil {
+			format := strings.TrimSpace(*override.Frontmatter)
+			if format != "" && !models.IsValidFrontmatterFormat(format) {
+				return fmt.Errorf("invalid frontmatter '%s' for symlinks.%s, must be one of %s", format, toolName, strings.Join(models.FrontmatterFormatNames(), ", "))
+			}
+		}
+
+		if override.Mode != nil {
+			mode := strings.TrimSpace(*override.Mode)
+			if mode != "" && !models.IsValidLinkMode(mode) {
+				return fmt.Errorf("invalid mode '%s' for symlinks.%s, must be one of %s", mode, toolName, linkModeList)
+			}
+			if mode == models.LinkModeInject || mode == models.LinkModeMerge {
+				return fmt.Errorf("mode '%s' only applies to a single artefact; use symlinks.%s.modes instead", mode, toolName)
+			}
+		}
+
+		for kind, mode := range override.Modes {
+			if !models.IsValidArtifactKind(kind) {
+				return fmt.Errorf("invalid artefact '%s' in symlinks.%s.modes, must be memory, subagents or mcp", kind, toolName)
+			}
+			if mode = strings.TrimSpace(mode); mode != "" && !models.IsValidLinkMode(mode) {
+				return fmt.Errorf("invalid mode '%s' for symlinks.%s.modes.%s, must be one of %s", mode, toolName, kind, linkModeList)
+			}
+			if mode != "" && !models.SupportsLinkMode(kind, mode) {
+				return fmt.Errorf("mode '%s' is not supported for symlinks.%s.modes.%s", mode, toolName, kind)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateMCPSettings checks the mcp include/exclude patterns and overrides.
+func ValidateMCPSettings(config *models.ProjectConfig) error {
+	if config == nil {
+		return fmt.Errorf("config cannot be nil")
+	}
+	if config.MCP == nil {
+		return nil
+	}
+
+	lists := []struct {
+		field    string
+		patterns []string
+	}{{"include", config.MCP.Include}, {"exclude", config.MCP.Exclude}}
+	for _, list := range lists {
+		field := list.field
+		for _, pattern := range list.patterns {
+			if strings.TrimSpace(pattern) == "" {
+				return fmt.Errorf("mcp.%s cannot contain empty entries", field)
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern '%s' in mcp.%s: %w", pattern, field, err)
+			}
+		}
+	}
+
+	for name, override := range config.MCP.Overrides {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("mcp.overrides cannot contain empty server names")
+		}
+		if override == nil {
+			continue
+		}
+		for key := range override.Env {
+			if strings.TrimSpace(key) == "" {
+				return fmt.Errorf("mcp.overrides.%s.env cannot contain empty variable names", name)
+			}
+		}
+	}
+
+	return nil
+}
+
 // ValidateProjectName validates the project name follows conventions
 func ValidateProjectName(name string) error {
 	if name == "" {
@@ -0,0 +1,294 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// Change actions reported by Plan.
+const (
+	ActionCreate = "create"
+	ActionAppend = "append"
+	ActionStore  = "store"
+	ActionSkip   = "skip"
+)
+
+// Item is a piece of existing tool configuration to migrate into mindful sources.
+type Item struct {
+	Kind    string // models.ArtifactMemory, ArtifactSubagents or ArtifactMCP
+	Tool    string // Tool the file belonged to (informational)
+	Path    string // Path the content was read from
+	Name    string // Subagent name (subagents only)
+	Content []byte // Raw file content
+}
+
+// Store is the subset of storage.Manager used to import MCP servers.
+type Store interface {
+	ListMCP() (map[string]string, error)
+	StoreMCP(serverName string, config string) error
+}
+
+// Change describes a single write that Apply will perform.
+type Change struct {
+	Kind   string // Artefact kind
+	Source string // Path the content came from
+	Target string // File or "mindful.db:<server>" that receives it
+	Action string // One of the Action* values
+	Reason string // Why the change is skipped (skip only)
+
+	content []byte
+	server  string
+	config  string
+}
+
+// Importer converts tool configuration files into mindful project sources.
+type Importer struct {
+	mindfulDir string
+	store      Store
+}
+
+// New creates an Importer for a project. store may be nil when MCP import is not needed.
+func New(projectPath string, store Store) *Importer {
+	return &Importer{
+		mindfulDir: filepath.Join(projectPath, models.DefaultMindfulDirName),
+		store:      store,
+	}
+}
+
+// MemoryPath returns the project memory file that imported memory is appended to.
+func (i *Importer) MemoryPath() string {
+	return filepath.Join(i.mindfulDir, "project-memory.mdc")
+}
+
+// SubagentDir returns the project subagent directory that imported subagents are written to.
+func (i *Importer) SubagentDir() string {
+	return filepath.Join(i.mindfulDir, "project-subagents")
+}
+
+// Plan computes the changes needed to import items without touching the filesystem.
+// Content that appears in several items, such as the same memory in CLAUDE.md and
+// AGENTS.md, is imported once and later copies are reported as duplicates.
+func (i *Importer) Plan(items []Item) ([]Change, error) {
+	memory, err := readOptional(i.MemoryPath())
+	if err != nil {
+		return nil, err
+	}
+
+	memorySeen := make(map[string]string)   // Normalised content -> first source
+	subagentSeen := make(map[string]Change) // Target -> first planned change
+	serverSeen := make(map[string]Change)   // Server name -> first planned change
+	var existingServers map[string]string
+
+	var changes []Change
+	for _, item := range items {
+		switch item.Kind {
+		case models.ArtifactMemory:
+			content := normalize(item.Content)
+			if content == "" {
+				continue
+			}
+			change := Change{Kind: item.Kind, Source: item.Path, Target: i.MemoryPath()}
+			switch {
+			case memorySeen[content] != "":
+				change.Action, change.Reason = ActionSkip, "duplicate of "+memorySeen[content]
+			case strings.Contains(memory, content):
+				change.Action, change.Reason = ActionSkip, "already present in project memory"
+			case strings.TrimSpace(memory) == "":
+				change.Action = ActionCreate
+				memory = content + "\n"
+			default:
+				change.Action = ActionAppend
+				memory = strings.TrimRight(memory, "\n") + "\n\n" + content + "\n"
+			}
+			if _, ok := memorySeen[content]; !ok {
+				memorySeen[content] = item.Path
+			}
+			change.content = []byte(memory)
+			changes = append(changes, change)
+
+		case models.ArtifactSubagents:
+			name := strings.TrimSpace(item.Name)
+			if name == "" {
+				return nil, fmt.Errorf("subagent from %s has no name", item.Path)
+			}
+			target := filepath.Join(i.SubagentDir(), name+".mdc")
+			content := normalize(item.Content) + "\n"
+			change := Change{Kind: item.Kind, Source: item.Path, Target: target, Action: ActionCreate, content: []byte(content)}
+			if first, ok := subagentSeen[target]; ok {
+				change.Action = ActionSkip
+				if string(first.content) == content {
+					change.Reason = "duplicate of " + first.Source
+				} else {
+					change.Reason = "conflicts with " + first.Source
+				}
+				changes = append(changes, change)
+				continue
+			}
+			if existing, err := readOptional(target); err != nil {
+				return nil, err
+			} else if existing != "" {
+				change.Action, change.Reason = ActionSkip, "project subagent already exists"
+			}
+			subagentSeen[target] = change
+			changes = append(changes, change)
+
+		case models.ArtifactMCP:
+			if i.store == nil {
+				return nil, fmt.Errorf("cannot import MCP servers from %s: no storage configured", item.Path)
+			}
+			if existingServers == nil {
+				if existingServers, err = i.store.ListMCP(); err != nil {
+					return nil, fmt.Errorf("failed to list MCP configurations: %w", err)
+				}
+			}
+			serverChanges, err := planServers(item, existingServers, serverSeen)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, serverChanges...)
+
+		default:
+			return nil, fmt.Errorf("unsupported import kind %q for %s", item.Kind, item.Path)
+		}
+	}
+
+	return finaliseMemory(changes), nil
+}
+
+// Apply performs the non-skipped changes returned by Plan.
+func (i *Importer) Apply(changes []Change) error {
+	for _, change := range changes {
+		switch change.Action {
+		case ActionSkip:
+			continue
+		case ActionStore:
+			if err := i.store.StoreMCP(change.server, change.config); err != nil {
+				return fmt.Errorf("failed to store MCP server %s: %w", change.server, err)
+			}
+		default:
+			if change.content == nil {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(change.Target), 0o755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(change.Target), err)
+			}
+			if err := os.WriteFile(change.Target, change.content, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", change.Target, err)
+			}
+		}
+	}
+	return nil
+}
+
+func planServers(item Item, existing map[string]string, seen map[string]Change) ([]Change, error) {
+	cfg, err := models.FromMCPJSON(item.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", item.Path, err)
+	}
+
+	names := cfg.ListServers()
+	sort.Strings(names)
+
+	var changes []Change
+	for _, name := range names {
+		encoded := cfg.Servers[name]
+		change := Change{
+			Kind:   models.ArtifactMCP,
+			Source: item.Path,
+			Target: fmt.Sprintf("%s:%s", models.DefaultStorageFileName, name),
+			Action: ActionStore,
+			server: name,
+			config: encoded,
+		}
+		if err := validateServer(name, encoded); err != nil {
+			change.Action = ActionSkip
+			change.Reason = "invalid: " + strings.ReplaceAll(err.Error(), "\n", "; ")
+			changes = append(changes, change)
+			continue
+		}
+		if first, ok := seen[name]; ok {
+			change.Action = ActionSkip
+			if sameServer(first.config, encoded) {
+				change.Reason = "duplicate of " + first.Source
+			} else {
+				change.Reason = "conflicts with " + first.Source
+			}
+		} else if current, ok := existing[name]; ok {
+			change.Action = ActionSkip
+			if sameServer(current, encoded) {
+				change.Reason = "identical server already stored"
+			} else {
+				change.Reason = "a different server with this name is already stored"
+			}
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = change
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// validateServer checks a server definition before it is stored.
+func validateServer(name, encoded string) error {
+	server, err := models.DecodeMCPServer(name, encoded)
+	if err != nil {
+		return err
+	}
+	return server.Validate()
+}
+
+// finaliseMemory keeps only the last memory write, since each planned memory
+// change carries the accumulated file content.
+func finaliseMemory(changes []Change) []Change {
+	last := -1
+	for idx, change := range changes {
+		if change.Kind == models.ArtifactMemory && change.Action != ActionSkip {
+			last = idx
+		}
+	}
+	for idx := range changes {
+		if changes[idx].Kind == models.ArtifactMemory && idx != last {
+			changes[idx].content = nil
+		}
+	}
+	return changes
+}
+
+func sameServer(a, b string) bool {
+	left, errA := base64.StdEncoding.DecodeString(a)
+	right, errB := base64.StdEncoding.DecodeString(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	var l, r interface{}
+	if json.Unmarshal(left, &l) != nil || json.Unmarshal(right, &r) != nil {
+		return string(left) == string(right)
+	}
+	lj, _ := json.Marshal(l)
+	rj, _ := json.Marshal(r)
+	return string(lj) == string(rj)
+}
+
+func readOptional(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+func normalize(content []byte) string {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mindful/src/models"
+	"mindful/src/symlink"
+)
+
+// scanSource is a file pattern that holds configuration for a tool.
+type scanSource struct {
+	tool    string
+	kind    string
+	pattern string // Project-relative glob
+}
+
+var scanSources = []scanSource{
+	{tool: "claude", kind: models.ArtifactMemory, pattern: "CLAUDE.md"},
+	{tool: "claude", kind: models.ArtifactSubagents, pattern: ".claude/agents/*.md"},
+	{tool: "claude", kind: models.ArtifactMCP, pattern: ".mcp.json"},
+	{tool: "codex", kind: models.ArtifactMemory, pattern: "AGENTS.md"},
+	// Cursor rules become memory when always applied, subagents otherwise.
+	{tool: "cursor", kind: models.ArtifactSubagents, pattern: ".cursor/rules/*.mdc"},
+	{tool: "cursor", kind: models.ArtifactMCP, pattern: ".cursor/mcp.json"},
+}
+
+// Tools returns the tools whose configuration files Scan understands.
+func Tools() []string {
+	seen := make(map[string]struct{})
+	var tools []string
+	for _, src := range scanSources {
+		if _, ok := seen[src.tool]; ok {
+			continue
+		}
+		seen[src.tool] = struct{}{}
+		tools = append(tools, src.tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Scan finds existing agent configuration files in a project. Links and copies
+// created by mindful are skipped, and mindful blocks inside user files are stripped.
+// An empty tools list scans every known tool.
+func Scan(projectPath string, tools []string) ([]Item, error) {
+	selected := make(map[string]struct{}, len(tools))
+	for _, tool := range tools {
+		selected[tool] = struct{}{}
+	}
+	for tool := range selected {
+		if !isKnownTool(tool) {
+			return nil, fmt.Errorf("import does not support tool %q (supported: %s)", tool, strings.Join(Tools(), ", "))
+		}
+	}
+
+	outDir := filepath.Join(projectPath, models.DefaultMindfulDirName, models.DefaultOutDirName)
+
+	var items []Item
+	for _, src := range scanSources {
+		if len(selected) > 0 {
+			if _, ok := selected[src.tool]; !ok {
+				continue
+			}
+		}
+
+		matches, err := filepath.Glob(filepath.Join(projectPath, filepath.FromSlash(src.pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan pattern %q: %w", src.pattern, err)
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			item, ok, err := readItem(projectPath, outDir, src, match)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func readItem(projectPath, outDir string, src scanSource, path string) (Item, bool, error) {
+	if strings.Contains(filepath.Base(path), ".mindful.") {
+		return Item{}, false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		// Dangling links and directories carry nothing to import.
+		return Item{}, false, nil
+	}
+
+	managed, err := symlink.IsManagedFile(path, outDir)
+	if err != nil {
+		return Item{}, false, err
+	}
+	if managed {
+		return Item{}, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Item{}, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	servers, err := symlink.MergedServers(projectPath, path)
+	if err != nil {
+		return Item{}, false, err
+	}
+	data, err = symlink.StripManagedContent(path, data, servers)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	rel, err := filepath.Rel(projectPath, path)
+	if err != nil {
+		rel = path
+	}
+	base := filepath.Base(path)
+
+	item := Item{
+		Kind:    src.kind,
+		Tool:    src.tool,
+		Path:    filepath.ToSlash(rel),
+		Name:    strings.TrimSuffix(base, filepath.Ext(base)),
+		Content: data,
+	}
+	if src.tool == "cursor" && src.kind == models.ArtifactSubagents {
+		if body, always := alwaysAppliedRule(data); always {
+			item.Kind = models.ArtifactMemory
+			item.Content = body
+		}
+	}
+	return item, true, nil
+}
+
+// alwaysAppliedRule reports whether a Cursor rule is marked alwaysApply and returns its body.
+func alwaysAppliedRule(content []byte) ([]byte, bool) {
+	normalized := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return nil, false
+	}
+	end := bytes.Index(normalized[4:], []byte("\n---"))
+	if end < 0 {
+		return nil, false
+	}
+
+	always := false
+	for _, line := range strings.Split(string(normalized[4:4+end]), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "alwaysApply" && strings.TrimSpace(value) == "true" {
+			always = true
+		}
+	}
+	if !always {
+		return nil, false
+	}
+
+	body := normalized[4+end+len("\n---"):]
+	return bytes.TrimLeft(body, "\n"), true
+}
+
+func isKnownTool(tool string) bool {
+	for _, src := range scanSources {
+		if src.tool == tool {
+			return true
+		}
+	}
+	return false
+}
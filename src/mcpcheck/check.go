@@ -0,0 +1,206 @@
+package mcpcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mindful/src/models"
+)
+
+// ProtocolVersion is the MCP revision offered in the initialize request.
+const ProtocolVersion = "2025-06-18"
+
+// DefaultTimeout bounds a whole check: start-up, handshake and tools/list.
+const DefaultTimeout = 15 * time.Second
+
+// Options tunes how servers are started and probed.
+type Options struct {
+	Timeout    time.Duration // Per-server limit, DefaultTimeout when zero
+	Dir        string        // Directory relative cwd values are resolved against
+	ClientName string        // clientInfo.name sent in initialize
+	ClientVer  string        // clientInfo.version sent in initialize
+}
+
+// Result is the outcome of checking one server.
+type Result struct {
+	Server          string        // Name of the server in mindful
+	Transport       string        // Effective transport that was probed
+	ServerName      string        // serverInfo.name reported by the server
+	ServerVersion   string        // serverInfo.version reported by the server
+	ProtocolVersion string        // Protocol version the server agreed to
+	Tools           int           // Number of tools listed
+	Duration        time.Duration // Time taken by the check
+	Err             error         // Why the check failed, nil on success
+	Stderr          string        // Tail of the server's stderr (stdio servers)
+}
+
+// OK reports whether the server completed the handshake and listed its tools.
+func (r *Result) OK() bool {
+	return r.Err == nil
+}
+
+// client is one connection to an MCP server over some transport.
+type client interface {
+	call(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	notify(ctx context.Context, method string, params interface{}) error
+	close() error
+}
+
+// Check starts or connects to server, performs the initialize handshake and lists
+// its tools. Placeholders must already be resolved.
+func Check(ctx context.Context, server *models.MCPServer, opts Options) *Result {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := &Result{Server: server.Name, Transport: server.EffectiveTransport()}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	var c client
+	var err error
+	switch result.Transport {
+	case models.MCPTransportStdio:
+		var stdio *stdioClient
+		stdio, err = startStdio(server, opts.Dir)
+		if stdio != nil {
+			defer func() { result.Stderr = stdio.stderrTail() }()
+			c = stdio
+		}
+	case models.MCPTransportHTTP:
+		c = newHTTPClient(server)
+	case models.MCPTransportSSE:
+		c, err = connectSSE(ctx, server)
+	default:
+		err = fmt.Errorf("unknown transport %q", result.Transport)
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer c.close()
+
+	result.Err = probe(ctx, c, result, opts)
+	if result.Err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Err = fmt.Errorf("timed out after %s: %w", timeout, result.Err)
+	}
+	return result
+}
+
+// probe runs initialize, notifications/initialized and tools/list.
+func probe(ctx context.Context, c client, result *Result, opts Options) error {
+	clientName := opts.ClientName
+	if clientName == "" {
+		clientName = "mindful"
+	}
+	params := map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": clientName, "version": opts.ClientVer},
+	}
+
+	raw, err := c.call(ctx, "initialize", params)
+	if err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+	var initialized struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(raw, &initialized); err != nil {
+		return fmt.Errorf("invalid initialize result: %w", err)
+	}
+	result.ProtocolVersion = initialized.ProtocolVersion
+	result.ServerName = initialized.ServerInfo.Name
+	result.ServerVersion = initialized.ServerInfo.Version
+
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("notifications/initialized failed: %w", err)
+	}
+
+	cursor := ""
+	for {
+		var params interface{}
+		if cursor != "" {
+			params = map[string]string{"cursor": cursor}
+		}
+		raw, err := c.call(ctx, "tools/list", params)
+		if err != nil {
+			return fmt.Errorf("tools/list failed: %w", err)
+		}
+		var page struct {
+			Tools      []json.RawMessage `json:"tools"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return fmt.Errorf("invalid tools/list result: %w", err)
+		}
+		result.Tools += len(page.Tools)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// message is a JSON-RPC 2.0 request, notification or response.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+func newRequest(id int, method string, params interface{}) message {
+	return message{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(id)), Method: method, Params: params}
+}
+
+func newNotification(method string, params interface{}) message {
+	return message{JSONRPC: "2.0", Method: method, Params: params}
+}
+
+// isResponseTo reports whether msg answers the request with id.
+func (m *message) isResponseTo(id int) bool {
+	return m.Method == "" && string(m.ID) == fmt.Sprint(id)
+}
+
+// isRequest reports whether msg is a request from the server that expects a reply.
+func (m *message) isRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+// reply answers a server request: pings succeed, everything else is unsupported.
+func (m *message) reply() message {
+	if m.Method == "ping" {
+		return message{JSONRPC: "2.0", ID: m.ID, Result: json.RawMessage("{}")}
+	}
+	return message{JSONRPC: "2.0", ID: m.ID, Error: &rpcError{Code: -32601, Message: "method not found"}}
+}
+
+// outcome returns the result of a response or its error.
+func (m *message) outcome() (json.RawMessage, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+	return m.Result, nil
+}
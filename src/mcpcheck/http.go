@@ -0,0 +1,371 @@
+package mcpcheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// httpClient speaks the Streamable HTTP transport: every message is a POST whose
+// response is either a JSON body or an event stream carrying the reply.
+type httpClient struct {
+	url      string
+	headers  map[string]string
+	http     *http.Client
+	session  string // Mcp-Session-Id assigned by the server
+	protocol string // Negotiated protocol version, sent after initialize
+	nextID   int
+}
+
+func newHTTPClient(server *models.MCPServer) *httpClient {
+	return &httpClient{url: server.URL, headers: server.Headers, http: &http.Client{}}
+}
+
+func (c *httpClient) post(ctx context.Context, msg message) (*http.Response, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.setHeaders(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, statusError(resp)
+	}
+	return resp, nil
+}
+
+func (c *httpClient) setHeaders(req *http.Request) {
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if c.session != "" {
+		req.Header.Set("Mcp-Session-Id", c.session)
+	}
+	if c.protocol != "" {
+		req.Header.Set("MCP-Protocol-Version", c.protocol)
+	}
+}
+
+func (c *httpClient) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	c.nextID++
+	id := c.nextID
+	resp, err := c.post(ctx, newRequest(id, method, params))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if session := resp.Header.Get("Mcp-Session-Id"); session != "" {
+		c.session = session
+	}
+
+	var reply *message
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var msg message
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			return nil, fmt.Errorf("invalid JSON-RPC response: %w", err)
+		}
+		if msg.isResponseTo(id) {
+			reply = &msg
+		}
+	case "text/event-stream":
+		err = readEvents(resp.Body, func(event, data string) bool {
+			var msg message
+			if json.Unmarshal([]byte(data), &msg) == nil && msg.isResponseTo(id) {
+				reply = &msg
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event stream: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected response content type %q", resp.Header.Get("Content-Type"))
+	}
+	if reply == nil {
+		return nil, errors.New("server did not answer the request")
+	}
+
+	result, err := reply.outcome()
+	if err == nil && method == "initialize" {
+		var initialized struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if json.Unmarshal(result, &initialized) == nil {
+			c.protocol = initialized.ProtocolVersion
+		}
+	}
+	return result, err
+}
+
+func (c *httpClient) notify(ctx context.Context, method string, params interface{}) error {
+	resp, err := c.post(ctx, newNotification(method, params))
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// close ends the server-side session, if one was assigned.
+func (c *httpClient) close() error {
+	if c.session == "" {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// sseClient speaks the legacy HTTP+SSE transport: replies arrive on a long-lived
+// event stream and requests are POSTed to the endpoint it announces.
+type sseClient struct {
+	headers  map[string]string
+	http     *http.Client
+	endpoint string
+	cancel   context.CancelFunc
+	messages chan message
+	done     chan struct{} // Closed when the stream ends
+	stop     chan struct{} // Closed by close so the reader never blocks
+	readErr  error
+	nextID   int
+}
+
+func connectSSE(ctx context.Context, server *models.MCPServer) (*sseClient, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range server.Headers {
+		req.Header.Set(key, value)
+	}
+
+	c := &sseClient{
+		headers:  server.Headers,
+		http:     &http.Client{},
+		cancel:   cancel,
+		messages: make(chan message, 16),
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	connected := make(chan error, 1)
+	go func() {
+		resp, err := c.http.Do(req)
+		if err == nil && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+			err = statusError(resp)
+			resp.Body.Close()
+		}
+		if err != nil {
+			connected <- err
+			close(c.done)
+			return
+		}
+		defer resp.Body.Close()
+		c.readLoop(resp.Body, server.URL, connected)
+	}()
+
+	select {
+	case err := <-connected:
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return c, nil
+	case <-ctx.Done():
+		cancel()
+		return nil, fmt.Errorf("no endpoint event received: %w", ctx.Err())
+	}
+}
+
+func (c *sseClient) readLoop(body io.Reader, base string, connected chan<- error) {
+	defer close(c.done)
+	announced := false
+	err := readEvents(body, func(event, data string) bool {
+		if !announced {
+			if event != "endpoint" {
+				return true
+			}
+			endpoint, err := resolveEndpoint(base, data)
+			if err != nil {
+				connected <- err
+				announced = true
+				return false
+			}
+			c.endpoint = endpoint
+			announced = true
+			connected <- nil
+			return true
+		}
+		var msg message
+		if json.Unmarshal([]byte(data), &msg) != nil {
+			return true
+		}
+		select {
+		case c.messages <- msg:
+		case <-c.stop:
+			return false
+		}
+		return true
+	})
+	if !announced {
+		if err == nil {
+			err = errors.New("event stream ended before announcing an endpoint")
+		}
+		connected <- err
+	}
+	c.readErr = err
+}
+
+func (c *sseClient) post(ctx context.Context, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (c *sseClient) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.post(ctx, newRequest(id, method, params)); err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case msg := <-c.messages:
+			if msg.isRequest() {
+				_ = c.post(ctx, msg.reply())
+				continue
+			}
+			if msg.isResponseTo(id) {
+				return msg.outcome()
+			}
+		case <-c.done:
+			if c.readErr != nil {
+				return nil, fmt.Errorf("event stream failed: %w", c.readErr)
+			}
+			return nil, errors.New("server closed the event stream before responding")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *sseClient) notify(ctx context.Context, method string, params interface{}) error {
+	return c.post(ctx, newNotification(method, params))
+}
+
+func (c *sseClient) close() error {
+	close(c.stop)
+	c.cancel()
+	return nil
+}
+
+// readEvents parses a text/event-stream body and calls fn for each event until fn
+// returns false or the stream ends.
+func readEvents(body io.Reader, fn func(event, data string) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	event := ""
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				if !fn(event, strings.Join(data, "\n")) {
+					return nil
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
+
+func resolveEndpoint(base, endpoint string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+// statusError describes a non-2xx response with the start of its body.
+func statusError(resp *http.Response) error {
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	if text := strings.TrimSpace(string(snippet)); text != "" {
+		return fmt.Errorf("HTTP %s: %s", resp.Status, text)
+	}
+	return fmt.Errorf("HTTP %s", resp.Status)
+}
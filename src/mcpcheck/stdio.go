@@ -0,0 +1,225 @@
+package mcpcheck
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mindful/src/models"
+)
+
+// stderrLimit is how much of a server's stderr is kept for failure reports.
+const stderrLimit = 4096
+
+// stdioClient talks newline-delimited JSON-RPC to a child process.
+type stdioClient struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	messages chan message
+	done     chan struct{} // Closed when stdout ends
+	stop     chan struct{} // Closed by close so the reader never blocks
+	stderr   *tailBuffer
+	nextID   int
+	writeMu  sync.Mutex
+	readErr  error
+	waitOnce sync.Once
+	waitErr  error
+}
+
+func startStdio(server *models.MCPServer, dir string) (*stdioClient, error) {
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Dir = dir
+	if server.Cwd != "" {
+		cmd.Dir = server.Cwd
+		if !filepath.IsAbs(server.Cwd) && dir != "" {
+			cmd.Dir = filepath.Join(dir, server.Cwd)
+		}
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout: %w", err)
+	}
+	c := &stdioClient{
+		cmd:      cmd,
+		stdin:    stdin,
+		messages: make(chan message, 16),
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+		stderr:   &tailBuffer{limit: stderrLimit},
+	}
+	cmd.Stderr = c.stderr
+	// Do not hang on grandchildren that keep stderr open after the server exits.
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", server.Command, err)
+	}
+	go c.readLoop(stdout)
+	return c, nil
+}
+
+func (c *stdioClient) readLoop(stdout io.Reader) {
+	defer close(c.done)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var msg message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			c.readErr = fmt.Errorf("server wrote non JSON-RPC output to stdout: %q", truncate(line, 120))
+			return
+		}
+		if msg.isRequest() {
+			_ = c.write(msg.reply())
+			continue
+		}
+		select {
+		case c.messages <- msg:
+		case <-c.stop:
+			return
+		}
+	}
+	c.readErr = scanner.Err()
+}
+
+func (c *stdioClient) write(msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (c *stdioClient) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.write(newRequest(id, method, params)); err != nil {
+		return nil, c.exitError(fmt.Errorf("failed to send request: %w", err))
+	}
+
+	for {
+		select {
+		case msg := <-c.messages:
+			if msg.isResponseTo(id) {
+				return msg.outcome()
+			}
+		case <-c.done:
+			// Drain responses that arrived just before stdout closed.
+			select {
+			case msg := <-c.messages:
+				if msg.isResponseTo(id) {
+					return msg.outcome()
+				}
+				continue
+			default:
+			}
+			if c.readErr != nil {
+				return nil, c.readErr
+			}
+			return nil, c.exitError(errors.New("server closed stdout before responding"))
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *stdioClient) notify(ctx context.Context, method string, params interface{}) error {
+	if err := c.write(newNotification(method, params)); err != nil {
+		return c.exitError(fmt.Errorf("failed to send notification: %w", err))
+	}
+	return nil
+}
+
+// exitError adds the exit status when the process has already stopped.
+func (c *stdioClient) exitError(err error) error {
+	select {
+	case <-c.done:
+	case <-time.After(500 * time.Millisecond):
+		return err
+	}
+	if waitErr := c.wait(); waitErr != nil {
+		return fmt.Errorf("%w (%v)", err, waitErr)
+	}
+	return fmt.Errorf("%w (exited with status 0)", err)
+}
+
+func (c *stdioClient) wait() error {
+	c.waitOnce.Do(func() { c.waitErr = c.cmd.Wait() })
+	return c.waitErr
+}
+
+// close ends the session by closing stdin, then kills servers that do not exit.
+func (c *stdioClient) close() error {
+	close(c.stop)
+	c.stdin.Close()
+	exited := make(chan struct{})
+	go func() {
+		c.wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+func (c *stdioClient) stderrTail() string {
+	return c.stderr.String()
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(string(b.data))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+const (
+	// DefaultBackupDirName is the directory under mindful/ that holds adopted originals.
+	DefaultBackupDirName = "backup"
+	// BackupManifestFileName describes the files stored in a single backup.
+	BackupManifestFileName = "manifest.json"
+)
+
+// BackupManifest records the files moved aside by a single `apply --adopt` run.
+type BackupManifest struct {
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"created_at"`
+	Entries   []BackupEntry `json:"entries"`
+}
+
+// BackupEntry describes one adopted file.
+type BackupEntry struct {
+	Tool   string `json:"tool"`
+	Kind   string `json:"kind"`   // memory, subagents or mcp
+	Mode   string `json:"mode"`   // Link strategy that replaced the original
+	Path   string `json:"path"`   // Original path (project-relative when possible)
+	Target string `json:"target"` // Artefact the path was linked to
+	File   string `json:"file"`   // Location of the saved copy, relative to the backup directory
+}
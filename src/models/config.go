@@ -3,7 +3,9 @@ package models
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -15,23 +17,214 @@ const (
 	DefaultOutDirName = "out"
 	// DefaultStorageFileName is the filename of the BoltDB database used for MCP storage.
 	DefaultStorageFileName = "mindful.db"
+	// DefaultUserDirName is the directory under the home directory holding personal mindful data.
+	DefaultUserDirName = ".mindful"
+	// UserDirEnv overrides the personal mindful directory.
+	UserDirEnv = "MINDFUL_USER_DIR"
 )
 
+// MCP scopes, from lowest to highest precedence.
+const (
+	ScopeTeam    = "team"
+	ScopeProject = "project"
+	ScopeUser    = "user"
+)
+
+// layerNamePattern restricts source layer names and scopes to what annotations can carry.
+var layerNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// UserMindfulDir returns the per-user mindful directory: $MINDFUL_USER_DIR when set,
+// otherwise ~/.mindful.
+func UserMindfulDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv(UserDirEnv)); dir != "" {
+		if strings.HasPrefix(dir, GitSourcePrefix) {
+			return "", fmt.Errorf("%s must be a local directory, got %q", UserDirEnv, dir)
+		}
+		return ResolveSourcePath(dir, "")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultUserDirName), nil
+}
+
 // ProjectConfig models the mindful.yaml configuration file.
 type ProjectConfig struct {
 	Name               string            `yaml:"name" json:"name"`
 	Version            string            `yaml:"version" json:"version"`
 	Source             string            `yaml:"source,omitempty" json:"source,omitempty"`                   // New field (preferred)
 	SourcePath         string            `yaml:"source_path,omitempty" json:"source_path,omitempty"`         // Legacy field for backward compatibility
+	Sources            []*SourceLayer    `yaml:"sources,omitempty" json:"sources,omitempty"`                 // Ordered shared sources, lowest precedence first
 	EnableCodingAgents []string          `yaml:"enable-coding-agents,omitempty" json:"enable-coding-agents"` // Preferred way to declare enabled tools
 	Tools              map[string]string `yaml:"tools,omitempty" json:"tools,omitempty"`                     // Legacy map of tool -> status ("enabled"/"disabled")
+
+	Symlinks map[string]*ToolSymlinkOverride `yaml:"symlinks,omitempty" json:"symlinks,omitempty"` // Per-project overrides of the default symlink mapping
+	MCP      *MCPSettings                    `yaml:"mcp,omitempty" json:"mcp,omitempty"`           // How MCP servers are rendered into mindful/out
+
+	UserScope *bool `yaml:"user_scope,omitempty" json:"user_scope,omitempty"` // Set to false to leave personal memory and subagents out of this project
+}
+
+// UserScopeEnabled reports whether builds include memory and subagents from the
+// user directory. It defaults to true.
+func (p *ProjectConfig) UserScopeEnabled() bool {
+	return p == nil || p.UserScope == nil || *p.UserScope
+}
+
+// SourceLayer is one shared source in the sources list of mindful.yaml, such as an
+// organisation baseline or a team repository.
+type SourceLayer struct {
+	Name  string `yaml:"name" json:"name"`                       // Unique layer name, used by mindful.lock and 'mindful source'
+	Path  string `yaml:"path" json:"path"`                       // Directory or git+ repository URL
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"` // Scope written into annotations; defaults to the name
+}
+
+// EffectiveScope returns the scope the layer's content is annotated with.
+func (l *SourceLayer) EffectiveScope() string {
+	if scope := strings.TrimSpace(l.Scope); scope != "" {
+		return scope
+	}
+	return strings.TrimSpace(l.Name)
+}
+
+// MCPSettings controls how MCP servers are rendered for a project.
+type MCPSettings struct {
+	// EnvReferences keeps ${env:NAME} placeholders as tool-native ${NAME} references
+	// instead of writing the variable's value into mindful/out.
+	EnvReferences bool `yaml:"env_references,omitempty" json:"env_references,omitempty"`
+
+	Include   []string                      `yaml:"include,omitempty" json:"include,omitempty"`     // Server names or glob patterns to render; empty means all
+	Exclude   []string                      `yaml:"exclude,omitempty" json:"exclude,omitempty"`     // Server names or glob patterns left out, applied after include
+	Overrides map[string]*MCPServerOverride `yaml:"overrides,omitempty" json:"overrides,omitempty"` // Project-level additions keyed by server name
+}
+
+// MCPServerOverride adds project-specific arguments and environment to a stored server.
+type MCPServerOverride struct {
+	Args []string          `yaml:"args,omitempty" json:"args,omitempty"` // Appended to the stored args
+	Env  map[string]string `yaml:"env,omitempty" json:"env,omitempty"`   // Merged over the stored env
+}
+
+// Apply adds the override to a server definition.
+func (o *MCPServerOverride) Apply(server *MCPServer) {
+	if o == nil || server == nil {
+		return
+	}
+	if len(o.Args) > 0 {
+		server.Args = append(append([]string{}, server.Args...), o.Args...)
+	}
+	if len(o.Env) > 0 {
+		env := make(map[string]string, len(server.Env)+len(o.Env))
+		for key, value := range server.Env {
+			env[key] = value
+		}
+		for key, value := range o.Env {
+			env[key] = value
+		}
+		server.Env = env
+	}
+}
+
+// SelectServers filters stored server names by include and exclude and returns the
+// selection along with every include entry or override that matches no stored server.
+func (s *MCPSettings) SelectServers(names []string) (selected []string, missing []string) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	if s == nil {
+		return sorted, nil
+	}
+
+	for _, name := range sorted {
+		if len(s.Include) > 0 && !matchesAny(s.Include, name) {
+			continue
+		}
+		if matchesAny(s.Exclude, name) {
+			continue
+		}
+		selected = append(selected, name)
+	}
+
+	for _, pattern := range s.Include {
+		if !matchesAny([]string{pattern}, sorted...) {
+			missing = append(missing, pattern)
+		}
+	}
+	overrideNames := make([]string, 0, len(s.Overrides))
+	for name := range s.Overrides {
+		overrideNames = append(overrideNames, name)
+	}
+	sort.Strings(overrideNames)
+	for _, name := range overrideNames {
+		if !matchesAny([]string{name}, sorted...) && !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return selected, missing
+}
+
+// matchesAny reports whether any of the names matches any of the glob patterns.
+func matchesAny(patterns []string, names ...string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
 }
 
+// UsesEnvReferences reports whether env placeholders are emitted as references.
+func (c *ProjectConfig) UsesEnvReferences() bool {
+	return c != nil && c.MCP != nil && c.MCP.EnvReferences
+}
+
+// Artefact kinds that can be linked into a tool's configuration.
+const (
+	ArtifactMemory    = "memory"
+	ArtifactSubagents = "subagents"
+	ArtifactMCP       = "mcp"
+)
+
+// Link strategies used to materialise mindful/out artefacts at tool paths.
+const (
+	LinkModeSymlink  = "symlink"
+	LinkModeCopy     = "copy"
+	LinkModeHardlink = "hardlink"
+	LinkModeInject   = "inject" // Memory only: maintain delimited blocks inside a user-owned file
+	LinkModeMerge    = "merge"  // MCP only: upsert managed servers into an existing JSON file
+)
+
 // ToolSymlinkConfig defines the link templates for a given tool.
 type ToolSymlinkConfig struct {
-	Memory    string `yaml:"memory,omitempty" json:"memory,omitempty"`
-	Subagents string `yaml:"subagents,omitempty" json:"subagents,omitempty"`
-	MCP       string `yaml:"mcp,omitempty" json:"mcp,omitempty"`
+	Memory      string            `yaml:"memory,omitempty" json:"memory,omitempty"`
+	Subagents   string            `yaml:"subagents,omitempty" json:"subagents,omitempty"`
+	MCP         string            `yaml:"mcp,omitempty" json:"mcp,omitempty"`
+	MCPFormat   string            `yaml:"mcp_format,omitempty" json:"mcp_format,omitempty"`   // Shape of the rendered MCP file, defaults to mcpServers
+	Frontmatter string            `yaml:"frontmatter,omitempty" json:"frontmatter,omitempty"` // Frontmatter written into subagent files, defaults to plain
+	Mode        string            `yaml:"mode,omitempty" json:"mode,omitempty"`               // Default link strategy for the tool
+	Modes       map[string]string `yaml:"modes,omitempty" json:"modes,omitempty"`             // Per-artefact strategy (memory/subagents/mcp)
+}
+
+// ToolSymlinkOverride describes project-level changes to a tool's link templates.
+// A nil field keeps the default template, while an empty string disables it.
+type ToolSymlinkOverride struct {
+	Memory      *string           `yaml:"memory,omitempty" json:"memory,omitempty"`
+	Subagents   *string           `yaml:"subagents,omitempty" json:"subagents,omitempty"`
+	MCP         *string           `yaml:"mcp,omitempty" json:"mcp,omitempty"`
+	MCPFormat   *string           `yaml:"mcp_format,omitempty" json:"mcp_format,omitempty"`
+	Frontmatter *string           `yaml:"frontmatter,omitempty" json:"frontmatter,omitempty"`
+	Mode        *string           `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Modes       map[string]string `yaml:"modes,omitempty" json:"modes,omitempty"`
 }
 
 // SymlinkConfig is a thin wrapper that offers helper methods for tool lookups.
@@ -51,9 +244,13 @@ func NewSymlinkConfig(raw map[string]*ToolSymlinkConfig) *SymlinkConfig {
 			continue
 		}
 		cfg.Tools[k] = &ToolSymlinkConfig{
-			Memory:    strings.TrimSpace(v.Memory),
-			Subagents: strings.TrimSpace(v.Subagents),
-			MCP:       strings.TrimSpace(v.MCP),
+			Memory:      strings.TrimSpace(v.Memory),
+			Subagents:   strings.TrimSpace(v.Subagents),
+			MCP:         strings.TrimSpace(v.MCP),
+			MCPFormat:   strings.TrimSpace(v.MCPFormat),
+			Frontmatter: strings.TrimSpace(v.Frontmatter),
+			Mode:        strings.TrimSpace(v.Mode),
+			Modes:       copyModes(v.Modes),
 		}
 	}
 
@@ -89,6 +286,146 @@ func (c *SymlinkConfig) HasTool(toolName string) bool {
 	return ok
 }
 
+// Merge returns a copy of the configuration with the overrides applied tool-by-tool.
+// Unknown tools are added, and individual templates are replaced only when set.
+func (c *SymlinkConfig) Merge(overrides map[string]*ToolSymlinkOverride) *SymlinkConfig {
+	merged := &SymlinkConfig{
+		Tools: make(map[string]*ToolSymlinkConfig),
+	}
+
+	if c != nil {
+		for name, tool := range c.Tools {
+			if tool == nil {
+				merged.Tools[name] = &ToolSymlinkConfig{}
+				continue
+			}
+			copied := *tool
+			copied.Modes = copyModes(tool.Modes)
+			merged.Tools[name] = &copied
+		}
+	}
+
+	for name, override := range overrides {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		tool, ok := merged.Tools[name]
+		if !ok {
+			tool = &ToolSymlinkConfig{}
+			merged.Tools[name] = tool
+		}
+		if override == nil {
+			continue
+		}
+
+		if override.Memory != nil {
+			tool.Memory = strings.TrimSpace(*override.Memory)
+		}
+		if override.Subagents != nil {
+			tool.Subagents = strings.TrimSpace(*override.Subagents)
+		}
+		if override.MCP != nil {
+			tool.MCP = strings.TrimSpace(*override.MCP)
+		}
+		if override.MCPFormat != nil {
+			tool.MCPFormat = strings.TrimSpace(*override.MCPFormat)
+		}
+		if override.Frontmatter != nil {
+			tool.Frontmatter = strings.TrimSpace(*override.Frontmatter)
+		}
+		if override.Mode != nil {
+			tool.Mode = strings.TrimSpace(*override.Mode)
+		}
+		for kind, mode := range override.Modes {
+			if tool.Modes == nil {
+				tool.Modes = make(map[string]string)
+			}
+			tool.Modes[kind] = strings.TrimSpace(mode)
+		}
+	}
+
+	return merged
+}
+
+// ModeFor returns the link strategy for an artefact kind, falling back to the
+// tool-level mode and finally to symlinks.
+func (t *ToolSymlinkConfig) ModeFor(kind string) string {
+	if t == nil {
+		return LinkModeSymlink
+	}
+	if mode := strings.TrimSpace(t.Modes[kind]); mode != "" {
+		return mode
+	}
+	if mode := strings.TrimSpace(t.Mode); mode != "" {
+		return mode
+	}
+	return LinkModeSymlink
+}
+
+// MCPFormatName returns the tool's MCP output format, defaulting to mcpServers.
+func (t *ToolSymlinkConfig) MCPFormatName() string {
+	if t == nil || strings.TrimSpace(t.MCPFormat) == "" {
+		return MCPFormatStandard
+	}
+	return strings.TrimSpace(t.MCPFormat)
+}
+
+// FrontmatterFormat returns the frontmatter format of the tool's subagent files,
+// defaulting to plain.
+func (t *ToolSymlinkConfig) FrontmatterFormat() string {
+	if t == nil || strings.TrimSpace(t.Frontmatter) == "" {
+		return FrontmatterPlain
+	}
+	return strings.TrimSpace(t.Frontmatter)
+}
+
+// RendersMCP reports whether build should render an MCP artefact for the tool.
+func (t *ToolSymlinkConfig) RendersMCP() bool {
+	return t != nil && (strings.TrimSpace(t.MCP) != "" || strings.TrimSpace(t.MCPFormat) != "")
+}
+
+// IsValidLinkMode reports whether mode names a supported link strategy.
+func IsValidLinkMode(mode string) bool {
+	switch mode {
+	case LinkModeSymlink, LinkModeCopy, LinkModeHardlink, LinkModeInject, LinkModeMerge:
+		return true
+	}
+	return false
+}
+
+// SupportsLinkMode reports whether an artefact kind can be materialised with mode.
+func SupportsLinkMode(kind, mode string) bool {
+	switch mode {
+	case LinkModeInject:
+		return kind == ArtifactMemory
+	case LinkModeMerge:
+		return kind == ArtifactMCP
+	}
+	return IsValidLinkMode(mode)
+}
+
+// IsValidArtifactKind reports whether kind names a linkable artefact.
+func IsValidArtifactKind(kind string) bool {
+	switch kind {
+	case ArtifactMemory, ArtifactSubagents, ArtifactMCP:
+		return true
+	}
+	return false
+}
+
+func copyModes(modes map[string]string) map[string]string {
+	if len(modes) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(modes))
+	for kind, mode := range modes {
+		copied[strings.TrimSpace(kind)] = strings.TrimSpace(mode)
+	}
+	return copied
+}
+
 // IsEmpty reports whether the tool configuration defines any paths.
 func (t *ToolSymlinkConfig) IsEmpty() bool {
 	if t == nil {
@@ -110,12 +447,59 @@ func (p *ProjectConfig) Validate() error {
 	if strings.TrimSpace(p.Version) == "" {
 		return fmt.Errorf("project version cannot be empty")
 	}
-	if _, err := p.resolveSourceValue(); err != nil {
+	layers, err := p.SourceLayers()
+	if err != nil {
 		return err
 	}
+	seen := make(map[string]struct{}, len(layers))
+	for i, layer := range layers {
+		if layer == nil {
+			return fmt.Errorf("sources[%d] cannot be empty", i)
+		}
+		if !layerNamePattern.MatchString(layer.Name) {
+			return fmt.Errorf("sources[%d]: name '%s' must be letters, digits, '.', '_' or '-'", i, layer.Name)
+		}
+		if _, ok := seen[layer.Name]; ok {
+			return fmt.Errorf("sources[%d]: name '%s' is used twice", i, layer.Name)
+		}
+		seen[layer.Name] = struct{}{}
+		if strings.TrimSpace(layer.Path) == "" {
+			return fmt.Errorf("sources.%s: path cannot be empty", layer.Name)
+		}
+		if _, err := ParseGitSource(layer.Path); err != nil {
+			return fmt.Errorf("sources.%s: %w", layer.Name, err)
+		}
+		scope := layer.EffectiveScope()
+		if !layerNamePattern.MatchString(scope) {
+			return fmt.Errorf("sources.%s: scope '%s' must be letters, digits, '.', '_' or '-'", layer.Name, scope)
+		}
+		if scope == ScopeProject || scope == ScopeUser {
+			return fmt.Errorf("sources.%s: scope '%s' is reserved for the built-in %s layer", layer.Name, scope, scope)
+		}
+	}
 	return nil
 }
 
+// SourceLayers returns the shared sources from lowest to highest precedence. A
+// single source (or legacy source_path) is the layer named team.
+func (p *ProjectConfig) SourceLayers() ([]*SourceLayer, error) {
+	if p == nil {
+		return nil, fmt.Errorf("project config is nil")
+	}
+	if len(p.Sources) > 0 {
+		if strings.TrimSpace(p.Source) != "" || strings.TrimSpace(p.SourcePath) != "" {
+			return nil, fmt.Errorf("use either source or sources in mindful.yaml, not both")
+		}
+		return p.Sources, nil
+	}
+
+	candidate, err := p.resolveSourceValue()
+	if err != nil {
+		return nil, err
+	}
+	return []*SourceLayer{{Name: ScopeTeam, Path: candidate, Scope: ScopeTeam}}, nil
+}
+
 // resolveSourceValue determines which source root field is populated.
 func (p *ProjectConfig) resolveSourceValue() (string, error) {
 	if p == nil {
@@ -126,6 +510,10 @@ func (p *ProjectConfig) resolveSourceValue() (string, error) {
 	if candidate == "" {
 		candidate = strings.TrimSpace(p.SourcePath)
 	}
+	if candidate == "" && len(p.Sources) > 0 && p.Sources[len(p.Sources)-1] != nil {
+		// The last layer is the primary source: it holds the team MCP store.
+		candidate = strings.TrimSpace(p.Sources[len(p.Sources)-1].Path)
+	}
 
 	if candidate == "" {
 		return "", fmt.Errorf("source path cannot be empty")
@@ -134,12 +522,36 @@ func (p *ProjectConfig) resolveSourceValue() (string, error) {
 	return candidate, nil
 }
 
-// ResolveSourceRoot resolves the project source root to an absolute path.
+// GitSource returns the git repository named by the primary source, or nil when
+// it is a local directory.
+func (p *ProjectConfig) GitSource() (*GitSource, error) {
+	candidate, err := p.resolveSourceValue()
+	if err != nil {
+		return nil, err
+	}
+	return ParseGitSource(candidate)
+}
+
+// ResolveSourceRoot resolves the primary source root to an absolute path. Git
+// sources resolve to their checkout in the user cache, which may not exist yet.
 func (p *ProjectConfig) ResolveSourceRoot(projectPath string) (string, error) {
 	candidate, err := p.resolveSourceValue()
 	if err != nil {
 		return "", err
 	}
+	return ResolveSourcePath(candidate, projectPath)
+}
+
+// ResolveSourcePath resolves a source value from mindful.yaml to an absolute
+// directory, relative to the project for local paths.
+func ResolveSourcePath(candidate, projectPath string) (string, error) {
+	gitSource, err := ParseGitSource(candidate)
+	if err != nil {
+		return "", err
+	}
+	if gitSource != nil {
+		return gitSource.CacheDir()
+	}
 
 	if strings.HasPrefix(candidate, "~") {
 		homeDir, herr := os.UserHomeDir()
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// Frontmatter formats selected per tool by frontmatter in the mapping.
+const (
+	FrontmatterPlain  = "plain"  // Frontmatter is stripped from the tool's files
+	FrontmatterClaude = "claude" // Claude Code subagents: name, description, tools, model
+	FrontmatterCursor = "cursor" // Cursor rules: description; globs and alwaysApply pass through
+)
+
+var frontmatterFormats = map[string]struct{}{
+	FrontmatterPlain:  {},
+	FrontmatterClaude: {},
+	FrontmatterCursor: {},
+}
+
+// IsValidFrontmatterFormat reports whether format names a supported frontmatter format.
+func IsValidFrontmatterFormat(format string) bool {
+	_, ok := frontmatterFormats[format]
+	return ok
+}
+
+// FrontmatterFormatNames lists the supported formats for messages.
+func FrontmatterFormatNames() []string {
+	names := make([]string, 0, len(frontmatterFormats))
+	for name := range frontmatterFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Frontmatter field names understood in memory and subagent source files.
+const (
+	FrontmatterDescription  = "description"
+	FrontmatterCodingAgents = "coding-agents"
+	FrontmatterModel        = "model"
+	FrontmatterAllowedTools = "allowed-tools"
+	FrontmatterTools        = "tools" // Claude Code's name for allowed-tools
+	FrontmatterTags         = "tags"
+	FrontmatterPriority     = "priority"
+	FrontmatterEnabled      = "enabled"
+	FrontmatterName         = "name"
+)
+
+var frontmatterFields = []string{
+	FrontmatterDescription,
+	FrontmatterCodingAgents,
+	FrontmatterModel,
+	FrontmatterAllowedTools,
+	FrontmatterTools,
+	FrontmatterTags,
+	FrontmatterPriority,
+	FrontmatterEnabled,
+	FrontmatterName,
+}
+
+// MemoryFrontmatterFields lists the fields memory source files may set.
+func MemoryFrontmatterFields() []string {
+	return []string{FrontmatterDescription, FrontmatterCodingAgents, FrontmatterTags, FrontmatterPriority, FrontmatterEnabled}
+}
+
+// SuggestFrontmatterField returns the known field within a small edit distance of
+// key, if any, so misspelt fields are reported instead of passed through.
+func SuggestFrontmatterField(key string) string {
+	return closestKey(key, frontmatterFields)
+}
+
+// Frontmatter is the YAML block at the top of a memory or subagent source file.
+type Frontmatter struct {
+	Name         string             // Subagent name; must match the file name when set
+	Description  string             // What the subagent does or when to use it
+	CodingAgents []string           // Tools the file applies to; empty means every tool
+	Model        string             // Model the subagent runs on (subagents only)
+	AllowedTools []string           // Tools the subagent may call (subagents only)
+	Tags         []string           // Free-form labels
+	Priority     int                // Higher wins name clashes and sorts memory sections first
+	Enabled      *bool              // Set to false to leave the file out of the build
+	Extra        []FrontmatterField // Unknown fields, passed through to tools that read frontmatter
+}
+
+// FrontmatterField is a frontmatter entry mindful does not interpret.
+type FrontmatterField struct {
+	Key   string
+	Value interface{}
+}
+
+// IsEnabled reports whether the file takes part in the build. It defaults to true.
+func (f *Frontmatter) IsEnabled() bool {
+	return f == nil || f.Enabled == nil || *f.Enabled
+}
+
+// PriorityValue returns the priority, treating missing frontmatter as 0.
+func (f *Frontmatter) PriorityValue() int {
+	if f == nil {
+		return 0
+	}
+	return f.Priority
+}
+
+// AppliesTo reports whether the file is rendered for the tool.
+func (f *Frontmatter) AppliesTo(tool string) bool {
+	if f == nil || len(f.CodingAgents) == 0 {
+		return true
+	}
+	for _, agent := range f.CodingAgents {
+		if strings.EqualFold(agent, tool) {
+			return true
+		}
+	}
+	return false
+}
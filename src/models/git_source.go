@@ -0,0 +1,75 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitSourcePrefix marks a source value that names a git repository instead of a directory.
+const GitSourcePrefix = "git+"
+
+// gitSourceSchemes lists the transports accepted after the git+ prefix.
+var gitSourceSchemes = []string{"https", "ssh", "file"}
+
+// GitSource is a team source kept in a git repository, e.g.
+// git+https://github.com/acme/agents.git#main.
+type GitSource struct {
+	URL string // Repository URL passed to git clone (without the git+ prefix)
+	Ref string // Branch, tag or commit to check out; empty means the remote's default branch
+}
+
+// ParseGitSource parses a git+<scheme>://...[#ref] source value. It returns nil for
+// values without the git+ prefix, which name local directories.
+func ParseGitSource(value string) (*GitSource, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, GitSourcePrefix) {
+		return nil, nil
+	}
+
+	url, ref, hasRef := strings.Cut(strings.TrimPrefix(value, GitSourcePrefix), "#")
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok || !containsString(gitSourceSchemes, scheme) {
+		return nil, fmt.Errorf("invalid git source %q: expected git+https://, git+ssh:// or git+file://", value)
+	}
+	if strings.Trim(rest, "/") == "" {
+		return nil, fmt.Errorf("invalid git source %q: repository is missing", value)
+	}
+	if hasRef && strings.TrimSpace(ref) == "" {
+		return nil, fmt.Errorf("invalid git source %q: ref after '#' is empty", value)
+	}
+
+	return &GitSource{URL: url, Ref: strings.TrimSpace(ref)}, nil
+}
+
+// String returns the source in the form it is written in mindful.yaml.
+func (g *GitSource) String() string {
+	if g.Ref == "" {
+		return GitSourcePrefix + g.URL
+	}
+	return GitSourcePrefix + g.URL + "#" + g.Ref
+}
+
+// CacheDir returns the per-user checkout of the repository. Each URL and ref pair
+// gets its own checkout so projects pinned to different refs do not interfere.
+func (g *GitSource) CacheDir() (string, error) {
+	root, err := GitSourceCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(g.String()))
+	return filepath.Join(root, hex.EncodeToString(sum[:8])), nil
+}
+
+// GitSourceCacheDir returns the directory holding cached git sources
+// (~/.cache/mindful/sources on Linux).
+func GitSourceCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "mindful", "sources"), nil
+}
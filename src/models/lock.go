@@ -0,0 +1,79 @@
+package models
+
+import "sort"
+
+// LockFileName is the file next to mindful.yaml that pins the team source revision.
+const LockFileName = "mindful.lock"
+
+// LockFile is the content of mindful.lock: one pin per shared source layer, so
+// everyone building the same project commit gets the same memory, subagents and
+// MCP servers.
+type LockFile struct {
+	Sources []*SourceLock `yaml:"sources"`
+}
+
+// SourceLock pins the revision of one source layer.
+type SourceLock struct {
+	Name   string            `yaml:"name,omitempty"`   // Layer name from mindful.yaml
+	Source string            `yaml:"source"`           // Source value from mindful.yaml the pin belongs to
+	Commit string            `yaml:"commit,omitempty"` // Commit of a git source
+	Files  map[string]string `yaml:"files,omitempty"`  // Content hash per file of a local source, keyed by relative path
+}
+
+// Find returns the pin of the named layer, or nil when the layer is not pinned or
+// its source changed since the pin was written.
+func (f *LockFile) Find(name, source string) *SourceLock {
+	if f == nil {
+		return nil
+	}
+	for _, lock := range f.Sources {
+		if lock != nil && lock.Name == name && lock.Source == source {
+			return lock
+		}
+	}
+	return nil
+}
+
+// SourceFileChanges lists the files added, changed and removed between two locks
+// of a local source, each sorted by path.
+type SourceFileChanges struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether no file differs.
+func (c SourceFileChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// DiffFiles compares the file hashes of two locks.
+func (l *SourceLock) DiffFiles(next *SourceLock) SourceFileChanges {
+	var before, after map[string]string
+	if l != nil {
+		before = l.Files
+	}
+	if next != nil {
+		after = next.Files
+	}
+
+	var changes SourceFileChanges
+	for path, hash := range after {
+		previous, ok := before[path]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, path)
+		case previous != hash:
+			changes.Changed = append(changes.Changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Changed)
+	sort.Strings(changes.Removed)
+	return changes
+}
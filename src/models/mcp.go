@@ -3,11 +3,13 @@ package models
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 )
 
 // MCPConfig represents MCP (Model Context Protocol) server configurations
-// Configurations are stored as base64 encoded JSON strings for security
+// Configurations are base64 encoded JSON strings; storage.Manager encrypts them at rest
 type MCPConfig struct {
 	Servers map[string]string `json:"servers" yaml:"servers"` // serverName -> base64 encoded JSON config
 }
@@ -94,28 +96,28 @@ func (m *MCPConfig) HasServer(serverName string) bool {
 	return exists
 }
 
-// Validate checks if the MCP configuration is valid
+// Validate checks that every server decodes to a valid typed definition
 func (m *MCPConfig) Validate() error {
 	if m == nil {
 		return fmt.Errorf("MCP config is nil")
 	}
-	
-	// Validate each server configuration can be decoded
-	for serverName, encoded := range m.Servers {
-		jsonData, err := base64.StdEncoding.DecodeString(encoded)
+
+	names := m.ListServers()
+	sort.Strings(names)
+
+	var errs []error
+	for _, serverName := range names {
+		server, err := m.Server(serverName)
 		if err != nil {
-			return fmt.Errorf("invalid base64 encoding for server %s: %w", serverName, err)
+			errs = append(errs, err)
+			continue
 		}
-		
-		// Validate it's valid JSON
-		var config interface{}
-		err = json.Unmarshal(jsonData, &config)
-		if err != nil {
-			return fmt.Errorf("invalid JSON config for server %s: %w", serverName, err)
+		if err := server.Validate(); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	
-	return nil
+
+	return errors.Join(errs...)
 }
 
 // ToMCPJSON converts the MCP configuration to the standard .mcp.json format
@@ -174,4 +176,42 @@ func (m *MCPConfig) Clone() *MCPConfig {
 	}
 	
 	return clone
-}
\ No newline at end of file
+}
+
+// secretFields are server keys whose values are treated as credentials.
+var secretFields = []string{"env", "headers"}
+
+// MaskSecrets returns a copy of a decoded server configuration with the values
+// of env variables and headers hidden, for display purposes.
+func MaskSecrets(config map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		masked[key] = value
+	}
+
+	for _, field := range secretFields {
+		values, ok := config[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		hidden := make(map[string]interface{}, len(values))
+		for name, value := range values {
+			hidden[name] = maskValue(fmt.Sprint(value))
+		}
+		masked[field] = hidden
+	}
+
+	return masked
+}
+
+// maskValue hides a secret, keeping the last characters of long values recognisable.
+// Placeholders carry no secret and are shown as is.
+func maskValue(value string) string {
+	if isPlaceholder(value) {
+		return value
+	}
+	if len(value) <= 8 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
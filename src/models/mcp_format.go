@@ -0,0 +1,268 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MCP output formats selected per tool by mcp_format in the mapping.
+const (
+	MCPFormatStandard = "mcpServers" // Claude Code and most tools: {"mcpServers": {...}}, env as ${NAME}
+	MCPFormatCursor   = "cursor"     // mcpServers shape with ${env:NAME} references
+	MCPFormatVSCode   = "vscode"     // .vscode/mcp.json: {"servers": {...}, "inputs": [...]}
+	MCPFormatZed      = "zed"        // Zed settings: {"context_servers": {...}}
+	MCPFormatCodex    = "codex"      // Codex config.toml: [mcp_servers.<name>] tables
+)
+
+// ErrMCPUnsupported marks a server a format cannot express.
+var ErrMCPUnsupported = errors.New("not supported by this MCP format")
+
+// MCPInput is a VS Code input variable that prompts for a value when a server starts.
+type MCPInput struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Password    bool   `json:"password,omitempty"`
+}
+
+var mcpFormats = map[string]struct {
+	fileName   string
+	serversKey string
+}{
+	MCPFormatStandard: {"mcp.json", "mcpServers"},
+	MCPFormatCursor:   {"mcp.json", "mcpServers"},
+	MCPFormatVSCode:   {"mcp.json", "servers"},
+	MCPFormatZed:      {"settings.json", "context_servers"},
+	MCPFormatCodex:    {"config.toml", "mcp_servers"},
+}
+
+// IsValidMCPFormat reports whether format names a supported MCP output format.
+func IsValidMCPFormat(format string) bool {
+	_, ok := mcpFormats[format]
+	return ok
+}
+
+// MCPFormatNames lists the supported formats for messages.
+func MCPFormatNames() []string {
+	names := make([]string, 0, len(mcpFormats))
+	for name := range mcpFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MCPFormatFileName returns the artefact file name written for format.
+func MCPFormatFileName(format string) string {
+	if spec, ok := mcpFormats[format]; ok {
+		return spec.fileName
+	}
+	return mcpFormats[MCPFormatStandard].fileName
+}
+
+// MCPServersKey returns the top-level key holding servers in format.
+func MCPServersKey(format string) string {
+	if spec, ok := mcpFormats[format]; ok {
+		return spec.serversKey
+	}
+	return mcpFormats[MCPFormatStandard].serversKey
+}
+
+// MCPEnvReference returns the tool-native reference to an environment variable.
+func MCPEnvReference(format, name string) (string, error) {
+	switch format {
+	case MCPFormatCursor, MCPFormatVSCode:
+		return "${env:" + name + "}", nil
+	case MCPFormatStandard, "":
+		return "${" + name + "}", nil
+	case MCPFormatCodex:
+		// Codex forwards whole variables through env_vars, see RenderMCP.
+		return "${env:" + name + "}", nil
+	}
+	return "", fmt.Errorf("environment references are %w", ErrMCPUnsupported)
+}
+
+// RenderMCP renders resolved servers in format. Servers the format cannot express
+// are left out and reported as warnings.
+func RenderMCP(format string, servers []*MCPServer, inputs []MCPInput) ([]byte, []string, error) {
+	sorted := append([]*MCPServer{}, servers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	if format == MCPFormatCodex {
+		return renderCodexTOML(sorted)
+	}
+
+	entries := make(map[string]interface{}, len(sorted))
+	for _, server := range sorted {
+		var entry map[string]interface{}
+		switch format {
+		case MCPFormatStandard, MCPFormatCursor:
+			entry = server.ToMap()
+		case MCPFormatVSCode:
+			entry = server.ToMap()
+			delete(entry, mcpKeyTransport)
+			entry[mcpKeyType] = server.EffectiveTransport()
+		case MCPFormatZed:
+			entry = zedServer(server)
+		default:
+			return nil, nil, fmt.Errorf("unknown MCP format %q", format)
+		}
+		entries[server.Name] = entry
+	}
+
+	doc := map[string]interface{}{MCPServersKey(format): entries}
+	if format == MCPFormatVSCode && len(inputs) > 0 {
+		doc["inputs"] = inputs
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render MCP configuration: %w", err)
+	}
+	return append(data, '\n'), nil, nil
+}
+
+// zedServer converts a definition to a Zed context server. Zed has no transport
+// field; remote servers are identified by their url.
+func zedServer(server *MCPServer) map[string]interface{} {
+	entry := map[string]interface{}{"source": "custom"}
+	if server.EffectiveTransport() == MCPTransportStdio {
+		entry[mcpKeyCommand] = server.Command
+		if len(server.Args) > 0 {
+			entry[mcpKeyArgs] = server.Args
+		}
+		if len(server.Env) > 0 {
+			entry[mcpKeyEnv] = server.Env
+		}
+		return entry
+	}
+	entry[mcpKeyURL] = server.URL
+	if len(server.Headers) > 0 {
+		entry[mcpKeyHeaders] = server.Headers
+	}
+	return entry
+}
+
+var envReferencePattern = regexp.MustCompile(`^\$\{env:([A-Za-z0-9_.\-]+)\}$`)
+
+// renderCodexTOML writes [mcp_servers.<name>] tables. Env entries that are exactly
+// ${env:NAME} for their own key become env_vars, which Codex forwards at start-up.
+func renderCodexTOML(servers []*MCPServer) ([]byte, []string, error) {
+	var buf bytes.Buffer
+	var warnings []string
+
+	for _, server := range servers {
+		var body bytes.Buffer
+		if err := writeCodexServer(&body, server); err != nil {
+			warnings = append(warnings, fmt.Sprintf("MCP server %q left out of codex output: %v", server.Name, err))
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s.%s]\n", MCPServersKey(MCPFormatCodex), TOMLKey(server.Name))
+		buf.Write(body.Bytes())
+	}
+
+	return buf.Bytes(), warnings, nil
+}
+
+func writeCodexServer(buf *bytes.Buffer, server *MCPServer) error {
+	switch server.EffectiveTransport() {
+	case MCPTransportStdio:
+		env := make(map[string]string)
+		var forwarded []string
+		for key, value := range server.Env {
+			if match := envReferencePattern.FindStringSubmatch(value); match != nil {
+				if match[1] != key {
+					return fmt.Errorf("env %s references ${env:%s}; codex can only forward a variable under its own name", key, match[1])
+				}
+				forwarded = append(forwarded, key)
+				continue
+			}
+			if strings.Contains(value, "${env:") {
+				return fmt.Errorf("env %s embeds an environment reference, which codex cannot expand", key)
+			}
+			env[key] = value
+		}
+		for _, arg := range server.Args {
+			if strings.Contains(arg, "${env:") {
+				return fmt.Errorf("args embed an environment reference, which codex cannot expand")
+			}
+		}
+		sort.Strings(forwarded)
+
+		fmt.Fprintf(buf, "command = %s\n", TOMLString(server.Command))
+		if len(server.Args) > 0 {
+			fmt.Fprintf(buf, "args = %s\n", tomlStringArray(server.Args))
+		}
+		if len(env) > 0 {
+			fmt.Fprintf(buf, "env = %s\n", tomlInlineTable(env))
+		}
+		if len(forwarded) > 0 {
+			fmt.Fprintf(buf, "env_vars = %s\n", tomlStringArray(forwarded))
+		}
+		if server.Cwd != "" {
+			fmt.Fprintf(buf, "cwd = %s\n", TOMLString(server.Cwd))
+		}
+	case MCPTransportHTTP:
+		fmt.Fprintf(buf, "url = %s\n", TOMLString(server.URL))
+		if len(server.Headers) > 0 {
+			fmt.Fprintf(buf, "http_headers = %s\n", tomlInlineTable(server.Headers))
+		}
+	default:
+		return fmt.Errorf("%s transport is %w", server.EffectiveTransport(), ErrMCPUnsupported)
+	}
+
+	if server.Timeout > 0 {
+		fmt.Fprintf(buf, "startup_timeout_sec = %s\n", strconv.FormatFloat(float64(server.Timeout)/1000, 'f', -1, 64))
+	}
+	return nil
+}
+
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// TOMLKey returns key as a bare TOML key when possible and quoted otherwise.
+func TOMLKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return TOMLString(key)
+}
+
+// TOMLString returns value as a TOML basic string. JSON string escapes are a
+// subset of TOML's, so the JSON encoding is reused.
+func TOMLString(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = TOMLString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func tomlInlineTable(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = TOMLKey(key) + " = " + TOMLString(values[key])
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
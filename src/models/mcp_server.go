@@ -0,0 +1,355 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MCP server transports.
+const (
+	MCPTransportStdio = "stdio"
+	MCPTransportHTTP  = "http"
+	MCPTransportSSE   = "sse"
+)
+
+// Keys of the standard MCP server definition.
+const (
+	mcpKeyType      = "type"
+	mcpKeyTransport = "transport" // Alias of "type" used by some tools
+	mcpKeyCommand   = "command"
+	mcpKeyArgs      = "args"
+	mcpKeyEnv       = "env"
+	mcpKeyCwd       = "cwd"
+	mcpKeyURL       = "url"
+	mcpKeyHeaders   = "headers"
+	mcpKeyTimeout   = "timeout"
+	mcpKeyDisabled  = "disabled"
+)
+
+var knownMCPKeys = []string{
+	mcpKeyType, mcpKeyTransport, mcpKeyCommand, mcpKeyArgs, mcpKeyEnv, mcpKeyCwd,
+	mcpKeyURL, mcpKeyHeaders, mcpKeyTimeout, mcpKeyDisabled,
+}
+
+// MCPServer is a typed MCP server definition. Fields mindful does not know about
+// are preserved in Extra so definitions round-trip without loss.
+type MCPServer struct {
+	Name      string
+	Transport string            // stdio, http or sse; empty means inferred from command/url
+	Command   string            // Executable for stdio servers
+	Args      []string          // Command arguments
+	Env       map[string]string // Environment for stdio servers
+	Cwd       string            // Working directory for stdio servers
+	URL       string            // Endpoint for http/sse servers
+	Headers   map[string]string // HTTP headers for http/sse servers
+	Timeout   int               // Startup/request timeout in milliseconds, 0 for the tool default
+	Disabled  bool              // Disabled servers are kept in storage but left out of builds
+	Extra     map[string]json.RawMessage
+
+	transportKey string // Key the transport was read from, so it is written back unchanged
+}
+
+// MCPFieldError reports a problem with one field of a server definition.
+type MCPFieldError struct {
+	Server  string
+	Field   string
+	Message string
+}
+
+func (e *MCPFieldError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("MCP server %q: %s", e.Server, e.Message)
+	}
+	return fmt.Sprintf("MCP server %q: field %q: %s", e.Server, e.Field, e.Message)
+}
+
+// ParseMCPServer decodes a JSON server definition.
+func ParseMCPServer(name string, data []byte) (*MCPServer, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &MCPFieldError{Server: name, Message: fmt.Sprintf("definition must be a JSON object: %v", err)}
+	}
+
+	server := &MCPServer{Name: name, Extra: make(map[string]json.RawMessage)}
+	var errs []error
+	decode := func(key string, target interface{}, expected string) {
+		if err := json.Unmarshal(raw[key], target); err != nil {
+			errs = append(errs, &MCPFieldError{Server: name, Field: key, Message: "must be " + expected})
+		}
+	}
+
+	for key := range raw {
+		switch key {
+		case mcpKeyType, mcpKeyTransport:
+			if server.transportKey != "" {
+				errs = append(errs, &MCPFieldError{Server: name, Field: key, Message: fmt.Sprintf("conflicts with %q", server.transportKey)})
+				continue
+			}
+			server.transportKey = key
+			decode(key, &server.Transport, "a string")
+		case mcpKeyCommand:
+			decode(key, &server.Command, "a string")
+		case mcpKeyArgs:
+			decode(key, &server.Args, "an array of strings")
+		case mcpKeyEnv:
+			decode(key, &server.Env, "an object of string values")
+		case mcpKeyCwd:
+			decode(key, &server.Cwd, "a string")
+		case mcpKeyURL:
+			decode(key, &server.URL, "a string")
+		case mcpKeyHeaders:
+			decode(key, &server.Headers, "an object of string values")
+		case mcpKeyTimeout:
+			decode(key, &server.Timeout, "an integer number of milliseconds")
+		case mcpKeyDisabled:
+			decode(key, &server.Disabled, "a boolean")
+		default:
+			server.Extra[key] = raw[key]
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// ParseMCPServerMap converts a decoded JSON object into a typed server.
+func ParseMCPServerMap(name string, value interface{}) (*MCPServer, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, &MCPFieldError{Server: name, Message: fmt.Sprintf("cannot encode definition: %v", err)}
+	}
+	return ParseMCPServer(name, data)
+}
+
+// EffectiveTransport returns the declared transport or the one implied by command/url.
+func (s *MCPServer) EffectiveTransport() string {
+	if s.Transport != "" {
+		return s.Transport
+	}
+	if s.URL != "" && s.Command == "" {
+		return MCPTransportHTTP
+	}
+	return MCPTransportStdio
+}
+
+// Validate checks the definition and returns every problem found.
+func (s *MCPServer) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, &MCPFieldError{Server: s.Name, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(s.Name) == "" {
+		errs = append(errs, errors.New("MCP server name cannot be empty"))
+	}
+
+	extra := make([]string, 0, len(s.Extra))
+	for key := range s.Extra {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		if suggestion := closestKey(key, knownMCPKeys); suggestion != "" {
+			fail(key, "unknown field (did you mean %q?)", suggestion)
+		}
+	}
+
+	transportField := s.transportKey
+	if transportField == "" {
+		transportField = mcpKeyType
+	}
+
+	switch transport := s.EffectiveTransport(); transport {
+	case MCPTransportStdio:
+		if strings.TrimSpace(s.Command) == "" {
+			fail(mcpKeyCommand, "required for stdio servers")
+		}
+		if s.URL != "" {
+			fail(mcpKeyURL, "not allowed for stdio servers (set %q to http or sse)", transportField)
+		}
+		if len(s.Headers) > 0 {
+			fail(mcpKeyHeaders, "not allowed for stdio servers")
+		}
+	case MCPTransportHTTP, MCPTransportSSE:
+		if strings.TrimSpace(s.URL) == "" {
+			fail(mcpKeyURL, "required for %s servers", transport)
+		} else if err := validateServerURL(s.URL); err != nil {
+			fail(mcpKeyURL, "%v", err)
+		}
+		if s.Command != "" {
+			fail(mcpKeyCommand, "not allowed for %s servers", transport)
+		}
+		if len(s.Args) > 0 {
+			fail(mcpKeyArgs, "not allowed for %s servers", transport)
+		}
+		if s.Cwd != "" {
+			fail(mcpKeyCwd, "not allowed for %s servers", transport)
+		}
+	default:
+		fail(transportField, "unknown transport %q (expected %s, %s or %s)", transport, MCPTransportStdio, MCPTransportHTTP, MCPTransportSSE)
+	}
+
+	if s.Timeout < 0 {
+		fail(mcpKeyTimeout, "must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+// ToMap returns the definition as a JSON object, including preserved unknown fields.
+func (s *MCPServer) ToMap() map[string]interface{} {
+	out := make(map[string]interface{}, len(s.Extra)+8)
+	for key, value := range s.Extra {
+		out[key] = value
+	}
+
+	if s.Transport != "" {
+		key := s.transportKey
+		if key == "" {
+			key = mcpKeyType
+		}
+		out[key] = s.Transport
+	}
+	if s.Command != "" {
+		out[mcpKeyCommand] = s.Command
+	}
+	if s.Args != nil {
+		out[mcpKeyArgs] = s.Args
+	}
+	if s.Env != nil {
+		out[mcpKeyEnv] = s.Env
+	}
+	if s.Cwd != "" {
+		out[mcpKeyCwd] = s.Cwd
+	}
+	if s.URL != "" {
+		out[mcpKeyURL] = s.URL
+	}
+	if s.Headers != nil {
+		out[mcpKeyHeaders] = s.Headers
+	}
+	if s.Timeout != 0 {
+		out[mcpKeyTimeout] = s.Timeout
+	}
+	if s.Disabled {
+		out[mcpKeyDisabled] = true
+	}
+	return out
+}
+
+// MarshalJSON writes the definition back in the standard MCP JSON shape.
+func (s *MCPServer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToMap())
+}
+
+// Encode returns the base64 JSON form stored in MCPConfig and mindful.db.
+func (s *MCPServer) Encode() (string, error) {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal server config for %s: %w", s.Name, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeMCPServer parses the base64 JSON form stored in MCPConfig and mindful.db.
+func DecodeMCPServer(name, encoded string) (*MCPServer, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 encoding for server %s: %w", name, err)
+	}
+	return ParseMCPServer(name, data)
+}
+
+// Server returns the typed definition of a stored server.
+func (m *MCPConfig) Server(serverName string) (*MCPServer, error) {
+	if m == nil || m.Servers == nil {
+		return nil, fmt.Errorf("server %s not found", serverName)
+	}
+	encoded, ok := m.Servers[serverName]
+	if !ok {
+		return nil, fmt.Errorf("server %s not found", serverName)
+	}
+	return DecodeMCPServer(serverName, encoded)
+}
+
+// PutServer stores a typed server definition.
+func (m *MCPConfig) PutServer(server *MCPServer) error {
+	encoded, err := server.Encode()
+	if err != nil {
+		return err
+	}
+	if m.Servers == nil {
+		m.Servers = make(map[string]string)
+	}
+	m.Servers[server.Name] = encoded
+	return nil
+}
+
+// validateServerURL accepts absolute http(s) URLs and values that still hold a placeholder.
+func validateServerURL(raw string) error {
+	if strings.Contains(raw, "${") {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("URL must use http or https, got %q", raw)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("URL %q has no host", raw)
+	}
+	return nil
+}
+
+// closestKey returns the known key within a small edit distance of key, if any.
+// Short keys only match one edit away, so custom keys such as "tags" or "id" are not
+// mistaken for typos of "args" or "cwd".
+func closestKey(key string, known []string) string {
+	maxDistance := 2
+	if len(key) < 5 {
+		maxDistance = 1
+	}
+	best, bestDistance := "", maxDistance+1
+	lower := strings.ToLower(key)
+	for _, candidate := range known {
+		if distance := editDistance(lower, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance with adjacent transpositions counted as
+// one edit, so "evn" is one edit from "env".
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Placeholder kinds accepted inside MCP server definitions.
+const (
+	PlaceholderSecret = "secret" // ${secret:NAME}, resolved from the per-user secrets store
+	PlaceholderEnv    = "env"    // ${env:NAME}, resolved from the environment at build time
+)
+
+var placeholderPattern = regexp.MustCompile(`\$\{(secret|env):([A-Za-z0-9_.\-]+)\}`)
+
+// PlaceholderLookup resolves a single placeholder. The boolean reports whether a value exists.
+type PlaceholderLookup func(kind, name string) (string, bool, error)
+
+// ResolvePlaceholders replaces ${secret:NAME} and ${env:NAME} references in every
+// string of a decoded JSON value. Object keys are left untouched. References that
+// cannot be resolved are kept verbatim and returned in order of appearance.
+func ResolvePlaceholders(value interface{}, lookup PlaceholderLookup) (interface{}, []string, error) {
+	var unresolved []string
+	var lookupErr error
+
+	var walk func(interface{}) interface{}
+	walk = func(node interface{}) interface{} {
+		switch typed := node.(type) {
+		case string:
+			return placeholderPattern.ReplaceAllStringFunc(typed, func(match string) string {
+				if lookupErr != nil {
+					return match
+				}
+				parts := placeholderPattern.FindStringSubmatch(match)
+				resolved, ok, err := lookup(parts[1], parts[2])
+				if err != nil {
+					lookupErr = fmt.Errorf("failed to resolve %s: %w", match, err)
+					return match
+				}
+				if !ok {
+					unresolved = append(unresolved, match)
+					return match
+				}
+				return resolved
+			})
+		case map[string]interface{}:
+			out := make(map[string]interface{}, len(typed))
+			for key, child := range typed {
+				out[key] = walk(child)
+			}
+			return out
+		case []interface{}:
+			out := make([]interface{}, len(typed))
+			for i, child := range typed {
+				out[i] = walk(child)
+			}
+			return out
+		default:
+			return node
+		}
+	}
+
+	resolved := walk(value)
+	if lookupErr != nil {
+		return nil, nil, lookupErr
+	}
+	return resolved, unresolved, nil
+}
+
+// isPlaceholder reports whether value consists of a single placeholder.
+func isPlaceholder(value string) bool {
+	loc := placeholderPattern.FindStringIndex(value)
+	return loc != nil && loc[0] == 0 && loc[1] == len(value)
+}
@@ -1,22 +1,113 @@
 package models
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var scopeAnnotationPattern = regexp.MustCompile(`^<!-- scope:(\S+) source:(.*) -->$`)
+
 // BuildArtifacts represents the rendered outputs that should be written into mindful/out.
 type BuildArtifacts struct {
 	Memory     *MemoryArtifact     // Unified memory document for all tools
 	Subagents  []*SubagentArtifact // Collection of rendered subagent files
-	MCPContent []byte              // Serialized MCP configuration (optional)
+	MCPOutputs []*MCPOutput        // MCP configuration rendered per tool (optional)
+	Warnings   []string            // Non-fatal problems found while building
+	Personal   []string            // Files from the user layer that contributed personal content
+	MCPServers []*MCPServerSource  // Where each rendered MCP server came from
+	Tools      []*ToolOutput       // Memory and subagents rendered per tool
+}
+
+// ToolOutput is the memory and subagents rendered for one tool, honouring the
+// coding-agents and frontmatter of each source file.
+type ToolOutput struct {
+	Tool      string              // Tool the files are rendered for
+	Memory    string              // Memory document; empty when no memory applies to the tool
+	Subagents []*SubagentArtifact // Subagents that apply to the tool, with frontmatter in its format
+}
+
+// MCPOutput is the MCP configuration rendered in one tool's format.
+type MCPOutput struct {
+	Tool    string // Tool the file is rendered for
+	Format  string // MCP format (mcpServers, cursor, vscode, zed, codex)
+	Content []byte // Rendered file contents
+}
+
+// MCPServerSource records the scope that supplied a rendered MCP server.
+type MCPServerSource struct {
+	Name     string   // Server name
+	Scope    string   // Winning scope (team, project or user)
+	Source   string   // File the definition was read from
+	Shadowed []string // Lower-precedence scopes that defined the same server
 }
 
 // MemoryArtifact contains the text content of the unified memory file.
 type MemoryArtifact struct {
-	Content     string   // The final memory document text
-	SourcePaths []string // Source files that contributed to the content
+	Content     string        // The final memory document text
+	SourcePaths []string      // Source files that contributed to the content
+	Files       []*MemoryFile // Annotated sections in document order
+}
+
+// MemoryFile is the contribution of one memory source file.
+type MemoryFile struct {
+	Scope      string       // Scope of the layer the file belongs to
+	SourcePath string       // Source file
+	Content    string       // Annotated content without frontmatter
+	Meta       *Frontmatter // Parsed frontmatter; nil when the file has none
 }
 
 // SubagentArtifact captures the rendered content for a single subagent.
 type SubagentArtifact struct {
-	Name       string // Logical name of the subagent (e.g. researcher)
-	FileName   string // File name to use on disk (e.g. researcher.mdc)
-	Content    string // Rendered file contents
-	SourcePath string // Originating file path (useful for diagnostics)
+	Name       string       // Logical name of the subagent (e.g. researcher)
+	FileName   string       // File name to use on disk (e.g. researcher.mdc)
+	Content    string       // Rendered file contents
+	SourcePath string       // Originating file path (useful for diagnostics)
+	Personal   bool         // Comes from the user layer
+	Meta       *Frontmatter // Parsed frontmatter; nil when the file has none
+}
+
+// MemorySection is one scope-annotated block of the unified memory document.
+type MemorySection struct {
+	Scope      string // Scope the block came from (team, project, ...)
+	SourcePath string // Source file that produced the block
+	Content    string // Block text without the annotation line
+}
+
+// PersonalNotice follows the scope annotation of content from the user layer, so it
+// is recognisable wherever the generated files end up.
+const PersonalNotice = "<!-- personal: from your own mindful directory, not shared with the team; do not copy it into shared files -->"
+
+// ScopeAnnotation returns the comment line that precedes scoped content in build artefacts.
+func ScopeAnnotation(scope, sourcePath string) string {
+	return fmt.Sprintf("<!-- scope:%s source:%s -->", scope, sourcePath)
+}
+
+// ParseMemorySections splits a rendered memory document at its scope annotations.
+// Text before the first annotation is ignored.
+func ParseMemorySections(content string) []MemorySection {
+	var sections []MemorySection
+	var current *MemorySection
+	var body []string
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Content = strings.TrimSpace(strings.Join(body, "\n"))
+		sections = append(sections, *current)
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if match := scopeAnnotationPattern.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			flush()
+			current = &MemorySection{Scope: match[1], SourcePath: match[2]}
+			body = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return sections
 }
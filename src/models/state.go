@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// DefaultStateFileName is the file under mindful/ that records what apply created.
+const DefaultStateFileName = ".state.json"
+
+// ApplyState is the persisted record of links and files created by mindful apply.
+type ApplyState struct {
+	Version   int                   `json:"version"`
+	BuildHash string                `json:"build_hash,omitempty"` // Hash of mindful/out at the last apply
+	AppliedAt time.Time             `json:"applied_at"`
+	Tools     map[string]*ToolState `json:"tools"`
+}
+
+// ToolState records the managed paths of a single tool.
+type ToolState struct {
+	BuildHash string       `json:"build_hash,omitempty"`
+	AppliedAt time.Time    `json:"applied_at"`
+	Entries   []StateEntry `json:"entries"`
+}
+
+// StateEntry describes one link, copy or injected file created for a tool.
+type StateEntry struct {
+	Kind    string   `json:"kind"`              // memory, subagents or mcp
+	Path    string   `json:"path"`              // Link path (project-relative when possible)
+	Target  string   `json:"target"`            // Artefact path (project-relative when possible)
+	Mode    string   `json:"mode"`              // Link strategy used to create the path
+	Servers []string `json:"servers,omitempty"` // MCP servers merged into a shared JSON file
+}
+
+// NewApplyState returns an empty state document.
+func NewApplyState() *ApplyState {
+	return &ApplyState{
+		Version: 1,
+		Tools:   make(map[string]*ToolState),
+	}
+}
+
+// ToolNames returns the tools recorded in the state.
+func (s *ApplyState) ToolNames() []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.Tools))
+	for name := range s.Tools {
+		names = append(names, name)
+	}
+	return normaliseToolList(names)
+}
@@ -1,9 +1,22 @@
 package models
 
+// Link states reported for managed paths.
+const (
+	LinkStatusOK       = "ok"       // The path matches the artefact
+	LinkStatusMissing  = "missing"  // Nothing exists at the path yet
+	LinkStatusStale    = "stale"    // Managed by mindful but out of date
+	LinkStatusModified = "modified" // A managed copy was edited by hand
+	LinkStatusConflict = "conflict" // An unmanaged file occupies the path
+	LinkStatusOrphaned = "orphaned" // Recorded by a previous apply but no longer mapped
+)
+
 // SymlinkInfo captures metadata about a symlink that Mindful needs to manage.
 type SymlinkInfo struct {
 	LinkPath    string `json:"link_path"`    // The path of the symlink (project-relative when possible)
 	TargetPath  string `json:"target_path"`  // The target path of the symlink (project-relative when possible)
 	IsValid     bool   `json:"is_valid"`     // True when an existing symlink already points to the target
 	IsDirectory bool   `json:"is_directory"` // Indicates whether the target is a directory symlink
+	Mode        string `json:"mode"`         // Link strategy (symlink, copy or hardlink)
+	Kind        string `json:"kind"`         // Artefact kind (memory, subagents or mcp)
+	Status      string `json:"status"`       // One of the LinkStatus* values
 }
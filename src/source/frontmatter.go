@@ -0,0 +1,247 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mindful/src/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontmatterDelimiter = "---"
+
+// splitFrontmatter separates a leading ---/--- block from the body of normalized
+// content. ok is false when the content does not start with frontmatter.
+func splitFrontmatter(content string) (block, body string, ok bool, err error) {
+	if content != frontmatterDelimiter && !strings.HasPrefix(content, frontmatterDelimiter+"\n") {
+		return "", content, false, nil
+	}
+	lines := strings.Split(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], " \t") == frontmatterDelimiter {
+			return strings.Join(lines[1:i], "\n"), strings.TrimSpace(strings.Join(lines[i+1:], "\n")), true, nil
+		}
+	}
+	return "", "", false, fmt.Errorf("frontmatter is not closed with a '%s' line", frontmatterDelimiter)
+}
+
+// parseFrontmatter reads the frontmatter of a memory or subagent source file and
+// returns it with the remaining body. Errors name the file and the field.
+func parseFrontmatter(path, content string) (*models.Frontmatter, string, error) {
+	block, body, ok, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	if !ok {
+		return nil, content, nil
+	}
+
+	meta := &models.Frontmatter{}
+	if strings.TrimSpace(block) == "" {
+		return meta, body, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &doc); err != nil {
+		return nil, "", fmt.Errorf("%s: invalid frontmatter: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return meta, body, nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("%s: frontmatter must be a list of 'field: value' lines", path)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i].Value, mapping.Content[i+1]
+		if seen[key] {
+			return nil, "", fmt.Errorf("%s: frontmatter field '%s' is set twice", path, key)
+		}
+		seen[key] = true
+
+		fieldErr := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%s: frontmatter field '%s' (line %d): %s", path, key, value.Line+1, fmt.Sprintf(format, args...))
+		}
+
+		var err error
+		switch key {
+		case models.FrontmatterName:
+			meta.Name, err = frontmatterString(value)
+		case models.FrontmatterDescription:
+			meta.Description, err = frontmatterString(value)
+		case models.FrontmatterModel:
+			meta.Model, err = frontmatterString(value)
+		case models.FrontmatterCodingAgents:
+			meta.CodingAgents, err = frontmatterList(value)
+		case models.FrontmatterTags:
+			meta.Tags, err = frontmatterList(value)
+		case models.FrontmatterAllowedTools, models.FrontmatterTools:
+			if seen[models.FrontmatterAllowedTools] && seen[models.FrontmatterTools] {
+				return nil, "", fieldErr("'%s' and '%s' name the same field; keep one", models.FrontmatterTools, models.FrontmatterAllowedTools)
+			}
+			meta.AllowedTools, err = frontmatterList(value)
+		case models.FrontmatterPriority:
+			meta.Priority, err = frontmatterInt(value)
+		case models.FrontmatterEnabled:
+			var enabled bool
+			enabled, err = frontmatterBool(value)
+			meta.Enabled = &enabled
+		default:
+			if suggestion := models.SuggestFrontmatterField(key); suggestion != "" {
+				return nil, "", fieldErr("unknown field (did you mean '%s'?)", suggestion)
+			}
+			var extra interface{}
+			if err := value.Decode(&extra); err != nil {
+				return nil, "", fieldErr("%v", err)
+			}
+			meta.Extra = append(meta.Extra, models.FrontmatterField{Key: key, Value: extra})
+		}
+		if err != nil {
+			return nil, "", fieldErr("%v", err)
+		}
+	}
+	return meta, body, nil
+}
+
+func frontmatterString(node *yaml.Node) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("must be a single line of text")
+	}
+	return strings.TrimSpace(node.Value), nil
+}
+
+// frontmatterList accepts a YAML list or a comma-separated string, the form Claude
+// Code uses for tools.
+func frontmatterList(node *yaml.Node) ([]string, error) {
+	var items []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil, nil
+		}
+		items = strings.Split(node.Value, ",")
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("must be a list of names")
+			}
+			items = append(items, item.Value)
+		}
+	default:
+		return nil, fmt.Errorf("must be a list of names")
+	}
+
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+func frontmatterInt(node *yaml.Node) (int, error) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return 0, fmt.Errorf("must be a whole number, got %q", node.Value)
+	}
+	value, err := strconv.Atoi(node.Value)
+	if err != nil {
+		return 0, fmt.Errorf("must be a whole number, got %q", node.Value)
+	}
+	return value, nil
+}
+
+func frontmatterBool(node *yaml.Node) (bool, error) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+		return false, fmt.Errorf("must be true or false, got %q", node.Value)
+	}
+	var value bool
+	if err := node.Decode(&value); err != nil {
+		return false, fmt.Errorf("must be true or false, got %q", node.Value)
+	}
+	return value, nil
+}
+
+// validateMemoryFrontmatter rejects fields that only mean something for subagents,
+// and fields mindful does not know: memory frontmatter is never passed through.
+func validateMemoryFrontmatter(path string, meta *models.Frontmatter) error {
+	if meta == nil {
+		return nil
+	}
+	for _, field := range meta.Extra {
+		return fmt.Errorf("%s: frontmatter field '%s': unknown field (known: %s)", path, field.Key, strings.Join(models.MemoryFrontmatterFields(), ", "))
+	}
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{models.FrontmatterName, meta.Name != ""},
+		{models.FrontmatterModel, meta.Model != ""},
+		{models.FrontmatterAllowedTools, len(meta.AllowedTools) > 0},
+	} {
+		if field.set {
+			return fmt.Errorf("%s: frontmatter field '%s' only applies to subagents", path, field.name)
+		}
+	}
+	return nil
+}
+
+// renderFrontmatter returns the subagent file for a tool: the frontmatter in the
+// tool's format followed by content. Plain tools, and subagents whose source has
+// no frontmatter, get content unchanged.
+func renderFrontmatter(format string, subagent *models.SubagentArtifact) (string, error) {
+	meta := subagent.Meta
+	if meta == nil || format == models.FrontmatterPlain {
+		return subagent.Content, nil
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value interface{}) error {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("failed to render frontmatter field '%s' of %s: %w", key, subagent.SourcePath, err)
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+		return nil
+	}
+
+	var fields []models.FrontmatterField
+	switch format {
+	case models.FrontmatterClaude:
+		fields = append(fields, models.FrontmatterField{Key: models.FrontmatterName, Value: subagent.Name})
+		if meta.Description != "" {
+			fields = append(fields, models.FrontmatterField{Key: models.FrontmatterDescription, Value: meta.Description})
+		}
+		if len(meta.AllowedTools) > 0 {
+			fields = append(fields, models.FrontmatterField{Key: models.FrontmatterTools, Value: strings.Join(meta.AllowedTools, ", ")})
+		}
+		if meta.Model != "" {
+			fields = append(fields, models.FrontmatterField{Key: models.FrontmatterModel, Value: meta.Model})
+		}
+	case models.FrontmatterCursor:
+		if meta.Description != "" {
+			fields = append(fields, models.FrontmatterField{Key: models.FrontmatterDescription, Value: meta.Description})
+		}
+	default:
+		return "", fmt.Errorf("unknown frontmatter format %q", format)
+	}
+	fields = append(fields, meta.Extra...)
+	if len(fields) == 0 {
+		return subagent.Content, nil
+	}
+
+	for _, field := range fields {
+		if err := add(field.Key, field.Value); err != nil {
+			return "", err
+		}
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to render frontmatter of %s: %w", subagent.SourcePath, err)
+	}
+	return frontmatterDelimiter + "\n" + string(data) + frontmatterDelimiter + "\n" + subagent.Content, nil
+}
@@ -0,0 +1,231 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// GitCheckout describes the cached checkout of a git source.
+type GitCheckout struct {
+	Dir      string // Checkout directory in the user cache
+	Commit   string // Commit checked out now
+	Previous string // Commit checked out before an update; empty after a fresh clone
+	Cloned   bool   // The repository was cloned by this call
+}
+
+// Changed reports whether an update moved the checkout to another commit.
+func (c *GitCheckout) Changed() bool {
+	return c.Cloned || c.Previous != c.Commit
+}
+
+// SyncGitSource makes sure the git source is checked out in the user cache,
+// cloning it on first use. Existing checkouts are used as they are unless update
+// is set, in which case the remote is fetched and ref is checked out again.
+func (m *Manager) SyncGitSource(src *models.GitSource, update bool) (*GitCheckout, error) {
+	dir, err := src.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err := cloneGitSource(src, dir); err != nil {
+			return nil, fmt.Errorf("failed to clone %s: %w", src, err)
+		}
+		commit, err := runGit(dir, "rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		return &GitCheckout{Dir: dir, Commit: commit, Cloned: true}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to inspect cached source %s: %w", dir, err)
+	}
+
+	previous, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("cached source %s is damaged (remove it to clone again): %w", dir, err)
+	}
+	if !update {
+		return &GitCheckout{Dir: dir, Commit: previous, Previous: previous}, nil
+	}
+
+	if _, err := runGit(dir, "fetch", "--quiet", "--prune", "--tags", "--force", "origin"); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", src, err)
+	}
+	if src.Ref == "" {
+		// Follow the remote if its default branch was renamed.
+		_, _ = runGit(dir, "remote", "set-head", "origin", "--auto")
+	}
+	if err := checkoutGitRef(dir, src.Ref); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", src, err)
+	}
+	commit, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return &GitCheckout{Dir: dir, Commit: commit, Previous: previous}, nil
+}
+
+// CheckoutGitCommit returns a checkout of commit that belongs to that commit
+// alone: a local clone of the cached repository in <cache dir>.commits/<commit>.
+// The shared checkout is never moved, so builds pinned at different commits can
+// run at once. The repository is cloned first and fetched when the commit is not
+// known yet.
+func (m *Manager) CheckoutGitCommit(src *models.GitSource, commit string) (*GitCheckout, error) {
+	checkout, err := m.SyncGitSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(checkout.Dir+".commits", commit)
+	if head, err := runGit(dir, "rev-parse", "HEAD"); err == nil && head == commit {
+		return &GitCheckout{Dir: dir, Commit: commit}, nil
+	}
+
+	if _, err := runGit(checkout.Dir, "rev-parse", "--verify", "--quiet", commit+"^{commit}"); err != nil {
+		if _, err := runGit(checkout.Dir, "fetch", "--quiet", "--tags", "--force", "origin"); err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", src, err)
+		}
+	}
+	if err := cloneGitCommit(checkout.Dir, dir, commit); err != nil {
+		return nil, fmt.Errorf("pinned commit %s of %s is not available: %w", commit, src, err)
+	}
+	return &GitCheckout{Dir: dir, Commit: commit}, nil
+}
+
+// cloneGitCommit clones the cached repository into a temporary directory next to
+// dir, checks commit out there and moves it into place, so concurrent builds never
+// see a half-written checkout. Local clones hardlink the objects, and only read
+// the cached repository, so any number of them can run at once.
+func cloneGitCommit(repo, dir, commit string) error {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parent, filepath.Base(dir)+".clone-")
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := runGit(parent, "clone", "--quiet", "--local", "--no-checkout", repo, tmp); err != nil {
+		return err
+	}
+	if _, err := runGit(tmp, "checkout", "--quiet", "--detach", commit); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		if head, headErr := runGit(dir, "rev-parse", "HEAD"); headErr == nil && head == commit {
+			// Another process finished the checkout first.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// GitChanges summarises what moved between two commits of a git source.
+type GitChanges struct {
+	Commits []string // One line per commit, newest first
+	Files   []string // Changed build inputs as "<status>\t<path>"
+}
+
+// DiffGitSource lists the commits and build inputs that changed between from and to.
+func (m *Manager) DiffGitSource(dir, from, to string) (*GitChanges, error) {
+	log, err := runGit(dir, "log", "--oneline", "--no-decorate", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"diff", "--name-status", "--no-renames", from, to, "--", "subagents", models.DefaultStorageFileName}
+	args = append(args, teamMemoryFiles...)
+	files, err := runGit(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &GitChanges{Commits: splitLines(log), Files: splitLines(files)}, nil
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// cloneGitSource clones into a temporary directory next to dir and moves it into
+// place, so concurrent builds never see a half-written checkout.
+func cloneGitSource(src *models.GitSource, dir string) error {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parent, filepath.Base(dir)+".clone-")
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := runGit(parent, "clone", "--quiet", "--no-checkout", src.URL, tmp); err != nil {
+		return err
+	}
+	if err := checkoutGitRef(tmp, src.Ref); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(filepath.Join(dir, ".git")); statErr == nil {
+			// Another process finished cloning first.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// checkoutGitRef detaches the checkout at ref, looked up as a remote branch, a tag
+// and finally a commit. An empty ref selects the remote's default branch.
+func checkoutGitRef(dir, ref string) error {
+	candidates := []string{"origin/HEAD"}
+	if ref != "" {
+		candidates = []string{"refs/remotes/origin/" + ref, "refs/tags/" + ref, ref}
+	}
+
+	for _, candidate := range candidates {
+		commit, err := runGit(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err != nil || commit == "" {
+			continue
+		}
+		_, err = runGit(dir, "checkout", "--quiet", "--force", "--detach", commit)
+		return err
+	}
+
+	if ref == "" {
+		return fmt.Errorf("the remote has no default branch; add #<branch> to the source")
+	}
+	return fmt.Errorf("ref %q is not a branch, tag or commit of the repository", ref)
+}
+
+// runGit runs git in dir and returns its trimmed standard output. Prompts are
+// disabled so a missing credential fails instead of waiting for input.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,88 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mindful/src/models"
+	"mindful/src/storage"
+)
+
+// SnapshotSource records the revision of the team source checked out in dir: the
+// commit for git sources and a content hash of every memory, subagent and MCP
+// store file for local ones.
+func (m *Manager) SnapshotSource(sourceValue, dir string) (*models.SourceLock, error) {
+	gitSource, err := models.ParseGitSource(sourceValue)
+	if err != nil {
+		return nil, err
+	}
+	if gitSource != nil {
+		commit, err := runGit(dir, "rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		return &models.SourceLock{Source: sourceValue, Commit: commit}, nil
+	}
+
+	paths, err := teamSourceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(paths))
+	for _, rel := range paths {
+		sum, err := hashSourceFile(filepath.Join(dir, rel), rel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", filepath.Join(dir, rel), err)
+		}
+		files[filepath.ToSlash(rel)] = "sha256:" + sum
+	}
+	return &models.SourceLock{Source: sourceValue, Files: files}, nil
+}
+
+// hashSourceFile hashes one team source file. The MCP store is hashed by its stored
+// entries rather than its bytes: bolt rewrites pages and metadata on writes that
+// leave the configurations unchanged.
+func hashSourceFile(path, rel string) (string, error) {
+	if rel == models.DefaultStorageFileName {
+		store, err := storage.NewReadOnlyManager(path)
+		if err != nil {
+			return "", err
+		}
+		defer store.Close()
+		return store.DigestMCP()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// teamSourceFiles lists the files of a team source that feed a build, relative to it.
+func teamSourceFiles(teamSourcePath string) ([]string, error) {
+	candidates := append([]string{}, teamMemoryFiles...)
+	candidates = append(candidates, models.DefaultStorageFileName)
+
+	entries, err := os.ReadDir(filepath.Join(teamSourcePath, "subagents"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read subagent directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			candidates = append(candidates, filepath.Join("subagents", entry.Name()))
+		}
+	}
+
+	var files []string
+	for _, rel := range candidates {
+		if info, err := os.Stat(filepath.Join(teamSourcePath, rel)); err == nil && !info.IsDir() {
+			files = append(files, rel)
+		}
+	}
+	return files, nil
+}
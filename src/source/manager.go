@@ -10,6 +10,11 @@ import (
 	"mindful/src/models"
 )
 
+var (
+	teamMemoryFiles    = []string{"memory.md", "memory.mdc"}
+	projectMemoryFiles = []string{"project-memory.mdc", "project-memory.md", "memory.mdc"}
+)
+
 // Manager loads configuration sources and renders unified build artefacts.
 type Manager struct{}
 
@@ -18,20 +23,73 @@ func NewManager() *Manager {
 	return &Manager{}
 }
 
+// Layer is one level of the source hierarchy: an organisation or team source, the
+// project's mindful/ directory or the user's ~/.mindful. Layers are merged in
+// order, so memory sections appear lowest precedence first and a subagent replaces
+// any subagent of the same name from an earlier layer.
+type Layer struct {
+	Name         string   // Layer name shown in diagnostics (org, team, project, user)
+	Scope        string   // Scope written into annotations
+	Source       string   // Source value from mindful.yaml; empty for the project and user layers
+	Dir          string   // Root directory of the layer
+	MemoryFiles  []string // Candidate memory files; the first non-empty one is used
+	SubagentDirs []string // Subagent directories relative to Dir, merged in order
+	ConfigFiles  []string // Other files under Dir that feed a build
+	Personal     bool     // Content belongs to the user and is marked as not shared
+}
+
+// SharedLayer describes a source listed in mindful.yaml, checked out in dir.
+func SharedLayer(name, scope, sourceValue, dir string) Layer {
+	return Layer{
+		Name:         name,
+		Scope:        scope,
+		Source:       sourceValue,
+		Dir:          dir,
+		MemoryFiles:  teamMemoryFiles,
+		SubagentDirs: []string{"subagents"},
+	}
+}
+
+// ProjectLayer describes the project's mindful/ directory.
+func ProjectLayer(projectPath string) Layer {
+	return Layer{
+		Name:         models.ScopeProject,
+		Scope:        models.ScopeProject,
+		Dir:          filepath.Join(projectPath, models.DefaultMindfulDirName),
+		MemoryFiles:  projectMemoryFiles,
+		SubagentDirs: []string{"project-subagents", "subagents"}, // subagents is the legacy location
+		ConfigFiles:  projectMCPFiles,
+	}
+}
+
+// UserLayer describes the user's personal directory (~/.mindful by default).
+func UserLayer(dir string) Layer {
+	return Layer{
+		Name:         models.ScopeUser,
+		Scope:        models.ScopeUser,
+		Dir:          dir,
+		MemoryFiles:  teamMemoryFiles,
+		SubagentDirs: []string{"subagents"},
+		Personal:     true,
+	}
+}
+
 // LoadArtifacts loads memory, subagents, and other assets from the team source and project directories.
 func (m *Manager) LoadArtifacts(teamSourcePath, projectPath string) (*models.BuildArtifacts, error) {
 	if projectPath == "" {
 		return nil, fmt.Errorf("project path cannot be empty")
 	}
+	return m.LoadLayers(defaultLayers(teamSourcePath, projectPath))
+}
 
-	mindfulDir := filepath.Join(projectPath, models.DefaultMindfulDirName)
-
-	memory, err := m.buildMemoryArtifact(teamSourcePath, mindfulDir)
+// LoadLayers merges memory and subagents across layers, lowest precedence first.
+func (m *Manager) LoadLayers(layers []Layer) (*models.BuildArtifacts, error) {
+	memory, err := m.buildMemoryArtifact(layers)
 	if err != nil {
 		return nil, err
 	}
 
-	subagents, err := m.buildSubagentArtifacts(teamSourcePath, mindfulDir)
+	subagents, err := m.buildSubagentArtifacts(layers)
 	if err != nil {
 		return nil, err
 	}
@@ -41,57 +99,96 @@ func (m *Manager) LoadArtifacts(teamSourcePath, projectPath string) (*models.Bui
 		Subagents: subagents,
 	}
 
+	// Record personal content so callers can point it out.
+	for _, layer := range layers {
+		if !layer.Personal || memory == nil {
+			continue
+		}
+		for _, path := range memory.SourcePaths {
+			if filepath.Dir(path) == filepath.Clean(layer.Dir) {
+				artifacts.Personal = append(artifacts.Personal, path)
+			}
+		}
+	}
+	for _, subagent := range subagents {
+		if subagent.Personal {
+			artifacts.Personal = append(artifacts.Personal, subagent.SourcePath)
+		}
+	}
+
 	return artifacts, nil
 }
 
-func (m *Manager) buildMemoryArtifact(teamSourcePath, mindfulDir string) (*models.MemoryArtifact, error) {
-	var segments []string
-	var sources []string
-
+// defaultLayers is the team source (when set) followed by the project.
+func defaultLayers(teamSourcePath, projectPath string) []Layer {
+	var layers []Layer
 	if teamSourcePath != "" {
-		if content, sourcePath, err := m.readOptionalFile(teamSourcePath, []string{"memory.md", "memory.mdc"}); err != nil {
-			return nil, fmt.Errorf("failed to read team memory: %w", err)
-		} else if content != "" {
-			segments = append(segments, annotateContent("team", sourcePath, content))
-			sources = append(sources, sourcePath)
-		}
+		layers = append(layers, SharedLayer(models.ScopeTeam, models.ScopeTeam, "", teamSourcePath))
 	}
+	return append(layers, ProjectLayer(projectPath))
+}
 
-	if content, sourcePath, err := m.readOptionalFile(mindfulDir, []string{"project-memory.mdc", "project-memory.md", "memory.mdc"}); err != nil {
-		return nil, fmt.Errorf("failed to read project memory: %w", err)
-	} else if content != "" {
-		segments = append(segments, annotateContent("project", sourcePath, content))
-		sources = append(sources, sourcePath)
+func (m *Manager) buildMemoryArtifact(layers []Layer) (*models.MemoryArtifact, error) {
+	var files []*models.MemoryFile
+
+	for _, layer := range layers {
+		content, sourcePath, err := m.readOptionalFile(layer.Dir, layer.MemoryFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s memory: %w", layer.Name, err)
+		}
+		if content == "" {
+			continue
+		}
+		meta, body, err := parseFrontmatter(sourcePath, content)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateMemoryFrontmatter(sourcePath, meta); err != nil {
+			return nil, err
+		}
+		if !meta.IsEnabled() || body == "" {
+			continue
+		}
+		files = append(files, &models.MemoryFile{
+			Scope:      layer.Scope,
+			SourcePath: sourcePath,
+			Content:    annotateContent(layer, sourcePath, body),
+			Meta:       meta,
+		})
 	}
 
-	if len(segments) == 0 {
+	if len(files) == 0 {
 		return nil, nil
 	}
 
+	// Higher priority sections come first; layer order breaks ties.
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Meta.PriorityValue() > files[j].Meta.PriorityValue()
+	})
+
+	segments := make([]string, 0, len(files))
+	sources := make([]string, 0, len(files))
+	for _, file := range files {
+		segments = append(segments, file.Content)
+		sources = append(sources, file.SourcePath)
+	}
+
 	return &models.MemoryArtifact{
 		Content:     strings.Join(segments, "\n\n"),
 		SourcePaths: sources,
+		Files:       files,
 	}, nil
 }
 
-func (m *Manager) buildSubagentArtifacts(teamSourcePath, mindfulDir string) ([]*models.SubagentArtifact, error) {
+func (m *Manager) buildSubagentArtifacts(layers []Layer) ([]*models.SubagentArtifact, error) {
 	results := make(map[string]*models.SubagentArtifact)
 
-	// Load team subagents first
-	if teamSourcePath != "" {
-		if err := m.mergeSubagentDir(results, filepath.Join(teamSourcePath, "subagents"), "team"); err != nil {
-			return nil, err
-		}
-	}
-
-	// Project overrides
-	projectDirs := []string{
-		filepath.Join(mindfulDir, "project-subagents"),
-		filepath.Join(mindfulDir, "subagents"), // legacy fallback
-	}
-	for _, dir := range projectDirs {
-		if err := m.mergeSubagentDir(results, dir, "project"); err != nil {
-			return nil, err
+	// Later layers override earlier ones unless those have a higher priority
+	for _, layer := range layers {
+		for _, dir := range layer.SubagentDirs {
+			if err := m.mergeSubagentDir(results, filepath.Join(layer.Dir, dir), layer); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -100,8 +197,10 @@ func (m *Manager) buildSubagentArtifacts(teamSourcePath, mindfulDir string) ([]*
 	}
 
 	names := make([]string, 0, len(results))
-	for name := range results {
-		names = append(names, name)
+	for name, subagent := range results {
+		if subagent.Meta.IsEnabled() {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
 
@@ -113,7 +212,52 @@ func (m *Manager) buildSubagentArtifacts(teamSourcePath, mindfulDir string) ([]*
 	return artifacts, nil
 }
 
-func (m *Manager) mergeSubagentDir(target map[string]*models.SubagentArtifact, dir string, scope string) error {
+// SourceFiles lists the existing files that feed LoadArtifacts, which lets callers
+// detect when mindful/out is older than its inputs.
+func (m *Manager) SourceFiles(teamSourcePath, projectPath string) ([]string, error) {
+	if projectPath == "" {
+		return nil, fmt.Errorf("project path cannot be empty")
+	}
+	return m.LayerFiles(defaultLayers(teamSourcePath, projectPath))
+}
+
+// LayerFiles lists the existing files that feed LoadLayers.
+func (m *Manager) LayerFiles(layers []Layer) ([]string, error) {
+	var candidates []string
+	for _, layer := range layers {
+		for _, name := range layer.MemoryFiles {
+			candidates = append(candidates, filepath.Join(layer.Dir, name))
+		}
+		for _, name := range layer.ConfigFiles {
+			candidates = append(candidates, filepath.Join(layer.Dir, name))
+		}
+		for _, rel := range layer.SubagentDirs {
+			dir := filepath.Join(layer.Dir, rel)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read subagent directory %s: %w", dir, err)
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					candidates = append(candidates, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+	}
+
+	var files []string
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+func (m *Manager) mergeSubagentDir(target map[string]*models.SubagentArtifact, dir string, layer Layer) error {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -138,13 +282,26 @@ func (m *Manager) mergeSubagentDir(target map[string]*models.SubagentArtifact, d
 			return fmt.Errorf("failed to read subagent file %s: %w", path, err)
 		}
 
-		content := normalizeContent(string(data))
+		meta, body, err := parseFrontmatter(path, normalizeContent(string(data)))
+		if err != nil {
+			return err
+		}
+		if meta != nil && meta.Name != "" && meta.Name != name {
+			return fmt.Errorf("%s: frontmatter field '%s' is '%s' but the file is named '%s'", path, models.FrontmatterName, meta.Name, name)
+		}
+		if existing, ok := target[name]; ok && existing.Meta.PriorityValue() > meta.PriorityValue() {
+			// A lower layer outranks this one.
+			continue
+		}
 
+		// A disabled file still overrides, which removes the subagent from lower layers.
 		target[name] = &models.SubagentArtifact{
 			Name:       name,
 			FileName:   entry.Name(),
-			Content:    annotateContent(scope, path, content),
+			Content:    annotateContent(layer, path, body),
 			SourcePath: path,
+			Personal:   layer.Personal,
+			Meta:       meta,
 		}
 	}
 
@@ -176,13 +333,18 @@ func normalizeContent(content string) string {
 	return strings.TrimSpace(content)
 }
 
-func annotateContent(scope, sourcePath, content string) string {
+func annotateContent(layer Layer, sourcePath, content string) string {
 	if strings.TrimSpace(content) == "" {
 		return ""
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("<!-- scope:%s source:%s -->\n", scope, sourcePath))
+	builder.WriteString(models.ScopeAnnotation(layer.Scope, sourcePath))
+	builder.WriteString("\n")
+	if layer.Personal {
+		builder.WriteString(models.PersonalNotice)
+		builder.WriteString("\n")
+	}
 	builder.WriteString(strings.TrimSpace(content))
 	return builder.String()
 }
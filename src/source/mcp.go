@@ -0,0 +1,59 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mindful/src/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectMCPFiles are the project-level MCP definitions, checked in order.
+var projectMCPFiles = []string{"mcp.json", "mcp.yaml", "mcp.yml"}
+
+// LoadProjectMCP reads the project's mindful/mcp.json (or mcp.yaml) and returns its
+// servers with the path they came from. A missing file yields a nil config.
+func (m *Manager) LoadProjectMCP(projectPath string) (*models.MCPConfig, string, error) {
+	if projectPath == "" {
+		return nil, "", fmt.Errorf("project path cannot be empty")
+	}
+
+	mindfulDir := filepath.Join(projectPath, models.DefaultMindfulDirName)
+	for _, name := range projectMCPFiles {
+		path := filepath.Join(mindfulDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if filepath.Ext(name) != ".json" {
+			if data, err = yamlToJSON(data); err != nil {
+				return nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+
+		cfg, err := models.FromMCPJSON(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return cfg, path, nil
+	}
+	return nil, "", nil
+}
+
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,99 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// ToolTarget is a tool that build renders memory and subagents for.
+type ToolTarget struct {
+	Name        string // Tool name from the mapping
+	Memory      bool   // The tool links memory
+	Subagents   bool   // The tool links subagents
+	Frontmatter string // Frontmatter format of its subagent files
+}
+
+// RenderTools renders the loaded memory and subagents once per target, keeping
+// only files whose coding-agents include the tool and rewriting subagent
+// frontmatter into the tool's format. known lists every configured tool so a
+// misspelt coding agent is reported instead of silently matching nothing.
+func (m *Manager) RenderTools(artifacts *models.BuildArtifacts, targets []ToolTarget, known []string) ([]*models.ToolOutput, error) {
+	if artifacts == nil {
+		return nil, nil
+	}
+	if err := validateCodingAgents(artifacts, known); err != nil {
+		return nil, err
+	}
+
+	var outputs []*models.ToolOutput
+	for _, target := range targets {
+		output := &models.ToolOutput{Tool: target.Name}
+
+		if target.Memory && artifacts.Memory != nil {
+			var segments []string
+			for _, file := range artifacts.Memory.Files {
+				if file.Meta.AppliesTo(target.Name) {
+					segments = append(segments, file.Content)
+				}
+			}
+			output.Memory = strings.Join(segments, "\n\n")
+		}
+
+		if target.Subagents {
+			for _, subagent := range artifacts.Subagents {
+				if !subagent.Meta.AppliesTo(target.Name) {
+					continue
+				}
+				content, err := renderFrontmatter(target.Frontmatter, subagent)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", target.Name, err)
+				}
+				rendered := *subagent
+				rendered.Content = content
+				output.Subagents = append(output.Subagents, &rendered)
+			}
+		}
+
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+func validateCodingAgents(artifacts *models.BuildArtifacts, known []string) error {
+	check := func(path string, meta *models.Frontmatter) error {
+		if meta == nil {
+			return nil
+		}
+		for _, agent := range meta.CodingAgents {
+			if !containsFold(known, agent) {
+				return fmt.Errorf("%s: frontmatter field '%s': unknown coding agent '%s' (known: %s)", path, models.FrontmatterCodingAgents, agent, strings.Join(known, ", "))
+			}
+		}
+		return nil
+	}
+
+	if artifacts.Memory != nil {
+		for _, file := range artifacts.Memory.Files {
+			if err := check(file.SourcePath, file.Meta); err != nil {
+				return err
+			}
+		}
+	}
+	for _, subagent := range artifacts.Subagents {
+		if err := check(subagent.SourcePath, subagent.Meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// PassphraseEnv holds the passphrase used to derive the storage key.
+	PassphraseEnv = "MINDFUL_PASSPHRASE"
+	// KeyFileEnv points at a file holding a hex encoded 32-byte key.
+	KeyFileEnv = "MINDFUL_KEY_FILE"
+
+	encryptedPrefix  = "enc:v1:"
+	keySize          = 32
+	saltSize         = 16
+	kdfIterations    = 210000
+	keyCheckVerifier = "mindful-key-check"
+)
+
+// ErrKeyMissing is returned when encrypted values are read without a usable key.
+var ErrKeyMissing = errors.New("encryption key not available")
+
+// KeySource describes where the storage encryption key comes from.
+// A passphrase takes precedence over a key file.
+type KeySource struct {
+	Passphrase string // Secret passed through PBKDF2 with the salt stored in the database
+	KeyFile    string // Path of a file holding a hex encoded key, created on first use
+}
+
+// KeySourceFromEnv reads MINDFUL_PASSPHRASE and MINDFUL_KEY_FILE, defaulting the key
+// file to the user configuration directory so it never lives in the project.
+func KeySourceFromEnv() KeySource {
+	source := KeySource{
+		Passphrase: os.Getenv(PassphraseEnv),
+		KeyFile:    os.Getenv(KeyFileEnv),
+	}
+	if source.KeyFile == "" {
+		source.KeyFile = DefaultKeyFile()
+	}
+	return source
+}
+
+// DefaultKeyFile returns the key file used when MINDFUL_KEY_FILE is not set.
+func DefaultKeyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mindful", "key")
+}
+
+// Describe names the key source for messages.
+func (k KeySource) Describe() string {
+	if k.Passphrase != "" {
+		return "passphrase from " + PassphraseEnv
+	}
+	if k.KeyFile != "" {
+		return "key file " + k.KeyFile
+	}
+	return "no key"
+}
+
+// missingKeyError explains how to provide a key.
+func missingKeyError(detail string) error {
+	hint := fmt.Sprintf("set %s, or %s to a key file", PassphraseEnv, KeyFileEnv)
+	if def := DefaultKeyFile(); def != "" {
+		hint += fmt.Sprintf(" (default %s)", def)
+	}
+	hint += "; run mindful secrets init-key to create a new key file, or copy the team's key there"
+	return fmt.Errorf("%w: %s; %s", ErrKeyMissing, detail, hint)
+}
+
+// loadKeyFile reads a hex encoded key. A missing file is never generated here: a key
+// created silently on one machine would lock teammates out of a shared database.
+func loadKeyFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, missingKeyError("no key file location available")
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("key file %s must contain %d hex encoded bytes", path, keySize)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	return nil, missingKeyError(fmt.Sprintf("key file %s does not exist", path))
+}
+
+// GenerateKeyFile writes a new random key to path with 0600 permissions.
+func GenerateKeyFile(path string) error {
+	key, err := randomBytes(keySize)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(path, key)
+}
+
+func writeKeyFile(path string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write key file %s: %w", path, err)
+	}
+	return nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return buf, nil
+}
+
+// deriveKey stretches a passphrase into a key with PBKDF2-HMAC-SHA256.
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2SHA256([]byte(passphrase), salt, kdfIterations, keySize)
+}
+
+// pbkdf2SHA256 implements RFC 8018 PBKDF2 with HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, length int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (length + hashLen - 1) / hashLen
+
+	out := make([]byte, 0, blocks*hashLen)
+	var counter [4]byte
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:length]
+}
+
+// sealer encrypts and decrypts stored values with AES-256-GCM.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise cipher: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts value, binding it to name so records cannot be swapped.
+func (s *sealer) seal(name string, value []byte) (string, error) {
+	nonce, err := randomBytes(s.aead.NonceSize())
+	if err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, value, []byte(name))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *sealer) open(name, value string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted value for %s: %w", name, err)
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(raw) < nonceSize {
+		return nil, fmt.Errorf("malformed encrypted value for %s", name)
+	}
+	plain, err := s.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: wrong key or corrupted data", name)
+	}
+	return plain, nil
+}
+
+// isEncrypted reports whether a stored value carries the encryption prefix.
+func isEncrypted(value []byte) bool {
+	return strings.HasPrefix(string(value), encryptedPrefix)
+}
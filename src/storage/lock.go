@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// LockTimeout is how long opening a database waits for another process to release it.
+var LockTimeout = 5 * time.Second
+
+var (
+	// ErrLocked is returned when the database stays locked for longer than LockTimeout.
+	ErrLocked = errors.New("database is locked")
+	// ErrReadOnly is returned by mutating calls on a manager opened read-only.
+	ErrReadOnly = errors.New("database was opened read-only")
+)
+
+// lockInfo is written next to a database while a process holds its write lock,
+// so commands that time out can say who is holding it.
+type lockInfo struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+func lockInfoPath(storagePath string) string {
+	return storagePath + ".lock"
+}
+
+// openDB opens the bolt database, waiting at most LockTimeout for the file lock.
+// Read-only handles share the lock with each other; writers hold it exclusively.
+func openDB(storagePath string, readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(storagePath, 0600, &bolt.Options{Timeout: LockTimeout, ReadOnly: readOnly})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, lockedError(storagePath)
+	}
+	return db, err
+}
+
+// lockedError describes the process holding the database, when it is known.
+func lockedError(storagePath string) error {
+	data, err := os.ReadFile(lockInfoPath(storagePath))
+	var info lockInfo
+	if err != nil || json.Unmarshal(data, &info) != nil || info.PID == 0 {
+		return fmt.Errorf("%w by another mindful process (waited %s)", ErrLocked, LockTimeout)
+	}
+	return fmt.Errorf("%w by PID %d (%s, since %s); wait for it to finish or stop it",
+		ErrLocked, info.PID, info.Command, info.Since.Format(time.RFC3339))
+}
+
+func writeLockInfo(storagePath string) error {
+	data, err := json.Marshal(lockInfo{
+		PID:     os.Getpid(),
+		Command: strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "),
+		Since:   time.Now().Round(time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lockInfoPath(storagePath), data, 0600)
+}
+
+func removeLockInfo(storagePath string) {
+	os.Remove(lockInfoPath(storagePath))
+}
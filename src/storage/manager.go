@@ -1,21 +1,53 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"mindful/src/models"
+
 	bolt "go.etcd.io/bbolt"
 )
 
-// Manager implements StorageManager interface for BoltDB storage
+var (
+	mcpBucket     = []byte("mcp")
+	secretsBucket = []byte("secrets")
+	metaBucket    = []byte("meta")
+
+	// encryptedBuckets hold values sealed with the storage key.
+	encryptedBuckets = [][]byte{mcpBucket, secretsBucket}
+
+	metaKeyKind  = []byte("key_kind")  // "passphrase" or "keyfile"
+	metaKeySalt  = []byte("key_salt")  // PBKDF2 salt for passphrase keys
+	metaKeyCheck = []byte("key_check") // Encrypted verifier used to detect a wrong key
+)
+
+const (
+	keyKindPassphrase = "passphrase"
+	keyKindFile       = "keyfile"
+)
+
+// Manager implements StorageManager interface for BoltDB storage.
+// MCP configurations are encrypted at rest with AES-256-GCM.
 type Manager struct {
-	db   *bolt.DB
-	path string
+	db       *bolt.DB
+	path     string
+	keys     KeySource
+	sealer   *sealer // Unlocked lazily on first encrypt/decrypt
+	readOnly bool    // Opened with a shared lock; mutating calls fail with ErrReadOnly
 }
 
-// NewManager creates a new StorageManager instance
+// NewManager creates a new StorageManager instance using the key source from the environment
 func NewManager(storagePath string) (*Manager, error) {
+	return NewManagerWithKeys(storagePath, KeySourceFromEnv())
+}
+
+// NewManagerWithKeys creates a new StorageManager instance with an explicit key source
+func NewManagerWithKeys(storagePath string, keys KeySource) (*Manager, error) {
 	if storagePath == "" {
 		return nil, fmt.Errorf("storage path cannot be empty")
 	}
@@ -26,27 +58,91 @@ func NewManager(storagePath string) (*Manager, error) {
 	}
 
 	// Open BoltDB database
-	db, err := bolt.Open(storagePath, 0600, nil)
+	db, err := openDB(storagePath, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if err := writeLockInfo(storagePath); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to record database lock: %w", err)
+	}
 
-	// Create MCP bucket if it doesn't exist
-	err = db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte("mcp"))
-		return err
+	// Create MCP bucket if it doesn't exist. Opening an initialised database must
+	// not commit a transaction: that rewrites the file even though nothing changed.
+	buckets := [][]byte{mcpBucket, metaBucket}
+	err = db.View(func(tx *bolt.Tx) error {
+		for i := len(buckets) - 1; i >= 0; i-- {
+			if tx.Bucket(buckets[i]) != nil {
+				buckets = append(buckets[:i], buckets[i+1:]...)
+			}
+		}
+		return nil
 	})
+	if err == nil && len(buckets) > 0 {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, name := range buckets {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
 	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to create MCP bucket: %w", err)
+		removeLockInfo(storagePath)
+		return nil, fmt.Errorf("failed to create storage buckets: %w", err)
 	}
 
 	return &Manager{
 		db:   db,
 		path: storagePath,
+		keys: keys,
+	}, nil
+}
+
+// NewReadOnlyManager opens an existing database for reading using the key source
+// from the environment. Any number of readers can share the database; a missing
+// file is reported as an error satisfying os.IsNotExist.
+func NewReadOnlyManager(storagePath string) (*Manager, error) {
+	return NewReadOnlyManagerWithKeys(storagePath, KeySourceFromEnv())
+}
+
+// NewReadOnlyManagerWithKeys opens an existing database for reading with an explicit key source
+func NewReadOnlyManagerWithKeys(storagePath string, keys KeySource) (*Manager, error) {
+	if storagePath == "" {
+		return nil, fmt.Errorf("storage path cannot be empty")
+	}
+	if _, err := os.Stat(storagePath); err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(storagePath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &Manager{
+		db:       db,
+		path:     storagePath,
+		keys:     keys,
+		readOnly: true,
 	}, nil
 }
 
+// ReadOnly reports whether the manager was opened with NewReadOnlyManager.
+func (m *Manager) ReadOnly() bool {
+	return m.readOnly
+}
+
+// writable fails mutating calls on read-only managers.
+func (m *Manager) writable() error {
+	if m.readOnly {
+		return fmt.Errorf("cannot modify %s: %w", m.path, ErrReadOnly)
+	}
+	return nil
+}
+
 // StoreMCP stores an MCP server configuration
 func (m *Manager) StoreMCP(serverName string, config string) error {
 	if serverName == "" {
@@ -56,14 +152,34 @@ func (m *Manager) StoreMCP(serverName string, config string) error {
 	if config == "" {
 		return fmt.Errorf("config cannot be empty")
 	}
+	if err := m.writable(); err != nil {
+		return err
+	}
+
+	server, err := models.DecodeMCPServer(serverName, config)
+	if err != nil {
+		return err
+	}
+	if err := server.Validate(); err != nil {
+		return err
+	}
+
+	sealer, err := m.unlock(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealer.seal(serverName, []byte(config))
+	if err != nil {
+		return err
+	}
 
 	return m.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("mcp"))
+		bucket := tx.Bucket(mcpBucket)
 		if bucket == nil {
 			return fmt.Errorf("mcp bucket not found")
 		}
 		
-		return bucket.Put([]byte(serverName), []byte(config))
+		return bucket.Put([]byte(serverName), []byte(sealed))
 	})
 }
 
@@ -75,9 +191,9 @@ func (m *Manager) RetrieveMCP(serverName string) (string, error) {
 
 	var config string
 	err := m.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("mcp"))
+		bucket := tx.Bucket(mcpBucket)
 		if bucket == nil {
-			return fmt.Errorf("mcp bucket not found")
+			return fmt.Errorf("server %s not found", serverName)
 		}
 		
 		data := bucket.Get([]byte(serverName))
@@ -88,8 +204,11 @@ func (m *Manager) RetrieveMCP(serverName string) (string, error) {
 		config = string(data)
 		return nil
 	})
+	if err != nil {
+		return "", err
+	}
 
-	return config, err
+	return m.reveal(serverName, config)
 }
 
 // ListMCP lists all stored MCP server configurations
@@ -97,9 +216,10 @@ func (m *Manager) ListMCP() (map[string]string, error) {
 	configs := make(map[string]string)
 
 	err := m.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("mcp"))
+		bucket := tx.Bucket(mcpBucket)
 		if bucket == nil {
-			return fmt.Errorf("mcp bucket not found")
+			// Read-only handles cannot create buckets; a missing one is empty.
+			return nil
 		}
 
 		return bucket.ForEach(func(key, value []byte) error {
@@ -107,8 +227,45 @@ func (m *Manager) ListMCP() (map[string]string, error) {
 			return nil
 		})
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return configs, err
+	for name, value := range configs {
+		plain, err := m.reveal(name, value)
+		if err != nil {
+			return nil, err
+		}
+		configs[name] = plain
+	}
+
+	return configs, nil
+}
+
+// DigestMCP returns a SHA-256 over the stored MCP entries as they are kept at rest.
+// Unlike a hash of the file it ignores page layout and transaction metadata, so it
+// only changes when a server is stored, removed or re-encrypted. No key is needed.
+func (m *Manager) DigestMCP() (string, error) {
+	hash := sha256.New()
+	err := m.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(mcpBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			for _, field := range [][]byte{key, value} {
+				var size [8]byte
+				binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+				hash.Write(size[:])
+				hash.Write(field)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read MCP configurations: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // DeleteMCP deletes an MCP server configuration
@@ -116,9 +273,12 @@ func (m *Manager) DeleteMCP(serverName string) error {
 	if serverName == "" {
 		return fmt.Errorf("server name cannot be empty")
 	}
+	if err := m.writable(); err != nil {
+		return err
+	}
 
 	return m.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("mcp"))
+		bucket := tx.Bucket(mcpBucket)
 		if bucket == nil {
 			return fmt.Errorf("mcp bucket not found")
 		}
@@ -132,10 +292,231 @@ func (m *Manager) DeleteMCP(serverName string) error {
 	})
 }
 
+// reveal decrypts a stored value; values written before encryption are returned as is.
+func (m *Manager) reveal(name, value string) (string, error) {
+	if !isEncrypted([]byte(value)) {
+		return value, nil
+	}
+	sealer, err := m.unlock(false)
+	if err != nil {
+		return "", err
+	}
+	plain, err := sealer.open(name, value)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// unlock derives the encryption key and checks it against the verifier stored in
+// the database. With create set, a database without a key is initialised instead.
+func (m *Manager) unlock(create bool) (*sealer, error) {
+	if m.sealer != nil {
+		return m.sealer, nil
+	}
+
+	var kind string
+	var salt, check []byte
+	if err := m.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if meta == nil {
+			return nil
+		}
+		kind = string(meta.Get(metaKeyKind))
+		salt = append([]byte(nil), meta.Get(metaKeySalt)...)
+		check = append([]byte(nil), meta.Get(metaKeyCheck)...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read key metadata: %w", err)
+	}
+	initialised := len(check) > 0
+
+	switch {
+	case initialised && kind == keyKindPassphrase && m.keys.Passphrase == "":
+		return nil, missingKeyError(fmt.Sprintf("%s is encrypted with a passphrase but %s is not set", m.path, PassphraseEnv))
+	case initialised && kind == keyKindFile && m.keys.Passphrase != "":
+		return nil, fmt.Errorf("%s is encrypted with a key file; unset %s and point %s at it", m.path, PassphraseEnv, KeyFileEnv)
+	}
+
+	newKind := keyKindFile
+	var key []byte
+	if m.keys.Passphrase != "" {
+		newKind = keyKindPassphrase
+		if len(salt) == 0 {
+			if initialised || !create {
+				return nil, missingKeyError(fmt.Sprintf("%s has no key salt", m.path))
+			}
+			var err error
+			if salt, err = randomBytes(saltSize); err != nil {
+				return nil, err
+			}
+		}
+		key = deriveKey(m.keys.Passphrase, salt)
+	} else {
+		var err error
+		if key, err = loadKeyFile(m.keys.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	sealer, err := newSealer(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if initialised {
+		if plain, err := sealer.open(string(metaKeyCheck), string(check)); err != nil || string(plain) != keyCheckVerifier {
+			return nil, fmt.Errorf("%w: the %s does not match the key %s was encrypted with", ErrKeyMissing, m.keys.Describe(), m.path)
+		}
+	} else {
+		if !create {
+			return nil, missingKeyError(fmt.Sprintf("%s holds encrypted values but no key metadata", m.path))
+		}
+		if err := m.writable(); err != nil {
+			return nil, err
+		}
+		if err := m.db.Update(func(tx *bolt.Tx) error {
+			return writeKeyMeta(tx, sealer, newKind, salt)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to store key metadata: %w", err)
+		}
+	}
+
+	m.sealer = sealer
+	return sealer, nil
+}
+
+// CheckKey verifies that the configured key opens the database. Databases that
+// hold no encrypted values yet always pass.
+func (m *Manager) CheckKey() error {
+	initialised := false
+	if err := m.db.View(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(metaBucket); meta != nil {
+			initialised = meta.Get(metaKeyCheck) != nil
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if !initialised {
+		return nil
+	}
+	_, err := m.unlock(false)
+	return err
+}
+
+// RotateKey re-encrypts every stored value with the key from next and returns the
+// number of values rewritten. Key file sources must already exist; see GenerateKeyFile.
+func (m *Manager) RotateKey(next KeySource) (int, error) {
+	if err := m.writable(); err != nil {
+		return 0, err
+	}
+	type record struct {
+		bucket []byte
+		name   []byte
+		plain  string
+	}
+
+	var records []record
+	if err := m.db.View(func(tx *bolt.Tx) error {
+		for _, name := range encryptedBuckets {
+			bucket := tx.Bucket(name)
+			if bucket == nil {
+				continue
+			}
+			if err := bucket.ForEach(func(key, value []byte) error {
+				records = append(records, record{bucket: name, name: append([]byte(nil), key...), plain: string(value)})
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	for i := range records {
+		revealed, err := m.reveal(string(records[i].name), records[i].plain)
+		if err != nil {
+			return 0, err
+		}
+		records[i].plain = revealed
+	}
+
+	kind := keyKindFile
+	var key, salt []byte
+	var err error
+	if next.Passphrase != "" {
+		kind = keyKindPassphrase
+		if salt, err = randomBytes(saltSize); err != nil {
+			return 0, err
+		}
+		key = deriveKey(next.Passphrase, salt)
+	} else if key, err = loadKeyFile(next.KeyFile); err != nil {
+		return 0, err
+	}
+
+	sealer, err := newSealer(key)
+	if err != nil {
+		return 0, err
+	}
+
+	err = m.db.Update(func(tx *bolt.Tx) error {
+		for _, rec := range records {
+			sealed, err := sealer.seal(string(rec.name), []byte(rec.plain))
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(rec.bucket).Put(rec.name, []byte(sealed)); err != nil {
+				return err
+			}
+		}
+		return writeKeyMeta(tx, sealer, kind, salt)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to re-encrypt storage: %w", err)
+	}
+
+	m.keys = next
+	m.sealer = sealer
+	return len(records), nil
+}
+
+func writeKeyMeta(tx *bolt.Tx, sealer *sealer, kind string, salt []byte) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	check, err := sealer.seal(string(metaKeyCheck), []byte(keyCheckVerifier))
+	if err != nil {
+		return err
+	}
+	if err := meta.Put(metaKeyKind, []byte(kind)); err != nil {
+		return err
+	}
+	if len(salt) > 0 {
+		if err := meta.Put(metaKeySalt, salt); err != nil {
+			return err
+		}
+	} else if err := meta.Delete(metaKeySalt); err != nil {
+		return err
+	}
+	return meta.Put(metaKeyCheck, []byte(check))
+}
+
+// Path returns the database file path.
+func (m *Manager) Path() string {
+	return m.path
+}
+
 // Close closes the database connection
 func (m *Manager) Close() error {
-	if m.db != nil {
-		return m.db.Close()
+	if m.db == nil {
+		return nil
 	}
-	return nil
+	if !m.readOnly {
+		removeLockInfo(m.path)
+	}
+	return m.db.Close()
 }
\ No newline at end of file
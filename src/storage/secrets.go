@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// SecretsDBEnv overrides the location of the per-user secrets database.
+const SecretsDBEnv = "MINDFUL_SECRETS_DB"
+
+// SecretsPath returns the per-user database that holds secrets referenced by
+// ${secret:NAME} placeholders. It lives outside any project or team source.
+func SecretsPath() (string, error) {
+	if path := os.Getenv(SecretsDBEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "mindful", "secrets.db"), nil
+}
+
+// NewSecretStore opens the per-user secrets database at path for writing using the
+// key source from the environment. Only this database gets a secrets bucket; MCP
+// stores never hold secrets.
+func NewSecretStore(path string) (*Manager, error) {
+	return NewSecretStoreWithKeys(path, KeySourceFromEnv())
+}
+
+// NewSecretStoreWithKeys opens the per-user secrets database with an explicit key source
+func NewSecretStoreWithKeys(path string, keys KeySource) (*Manager, error) {
+	store, err := NewManagerWithKeys(path, keys)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(secretsBucket)
+		return err
+	}); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to create secrets bucket: %w", err)
+	}
+	return store, nil
+}
+
+// StoreSecret stores an encrypted secret value
+func (m *Manager) StoreSecret(name string, value string) error {
+	if name == "" {
+		return fmt.Errorf("secret name cannot be empty")
+	}
+	if err := m.writable(); err != nil {
+		return err
+	}
+
+	sealer, err := m.unlock(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealer.seal(name, []byte(value))
+	if err != nil {
+		return err
+	}
+
+	return m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		if bucket == nil {
+			return fmt.Errorf("%s is not a secrets database", m.path)
+		}
+		return bucket.Put([]byte(name), []byte(sealed))
+	})
+}
+
+// RetrieveSecret returns a secret value and whether it exists
+func (m *Manager) RetrieveSecret(name string) (string, bool, error) {
+	var value []byte
+	if err := m.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(secretsBucket); bucket != nil {
+			value = append([]byte(nil), bucket.Get([]byte(name))...)
+		}
+		return nil
+	}); err != nil {
+		return "", false, err
+	}
+	if value == nil {
+		return "", false, nil
+	}
+
+	plain, err := m.reveal(name, string(value))
+	if err != nil {
+		return "", true, err
+	}
+	return plain, true, nil
+}
+
+// ListSecrets returns the names of stored secrets
+func (m *Manager) ListSecrets() ([]string, error) {
+	var names []string
+	err := m.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, _ []byte) error {
+			names = append(names, string(key))
+			return nil
+		})
+	})
+	return names, err
+}
+
+// DeleteSecret removes a secret
+func (m *Manager) DeleteSecret(name string) error {
+	if err := m.writable(); err != nil {
+		return err
+	}
+	return m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(secretsBucket)
+		if bucket == nil || bucket.Get([]byte(name)) == nil {
+			return fmt.Errorf("secret %s not found", name)
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
@@ -0,0 +1,383 @@
+package symlink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mindful/src/models"
+)
+
+// backupTimeFormat names backup directories so they sort chronologically.
+const backupTimeFormat = "20060102-150405"
+
+// Backup collects the files moved aside by a single adopt run.
+type Backup struct {
+	dir      string
+	manifest models.BackupManifest
+}
+
+// Name returns the backup identifier accepted by Restore.
+func (b *Backup) Name() string {
+	return b.manifest.Name
+}
+
+// Dir returns the absolute backup directory.
+func (b *Backup) Dir() string {
+	return b.dir
+}
+
+// Entries returns the files adopted so far.
+func (b *Backup) Entries() []models.BackupEntry {
+	return b.manifest.Entries
+}
+
+// Save writes the backup manifest. Nothing is written when no file was adopted.
+func (b *Backup) Save() error {
+	if len(b.manifest.Entries) == 0 {
+		return nil
+	}
+	return writeManifest(b.dir, &b.manifest)
+}
+
+// NewBackup reserves a timestamped directory under mindful/backup.
+// The directory is only created once a file is adopted.
+func (m *Manager) NewBackup() (*Backup, error) {
+	now := time.Now().UTC()
+	base := now.Format(backupTimeFormat)
+
+	name := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(filepath.Join(m.resolver.BackupDir(), name)); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to inspect backup directory: %w", err)
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return &Backup{
+		dir:      filepath.Join(m.resolver.BackupDir(), name),
+		manifest: models.BackupManifest{Name: name, CreatedAt: now},
+	}, nil
+}
+
+// Adopt moves unmanaged or hand-edited files that block a tool's links into backup,
+// so the next Apply can create the links. It returns the entries that were adopted.
+func (m *Manager) Adopt(toolName string, backup *Backup) ([]models.BackupEntry, error) {
+	// Targets are not verified: adopted content may be what produces them.
+	plans, err := m.plan(toolName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var adopted []models.BackupEntry
+	var errs []error
+	for _, plan := range plans {
+		if plan.info.Status != models.LinkStatusConflict && plan.info.Status != models.LinkStatusModified {
+			continue
+		}
+
+		info, err := os.Lstat(plan.linkAbs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to inspect %s: %w", plan.linkAbs, err))
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			errs = append(errs, fmt.Errorf("cannot adopt %s: existing path is not a regular file", plan.linkAbs))
+			continue
+		}
+
+		entry := models.BackupEntry{
+			Tool:   toolName,
+			Kind:   plan.kind,
+			Mode:   plan.mode,
+			Path:   plan.info.LinkPath,
+			Target: plan.info.TargetPath,
+			File:   backupFileName(plan.info.LinkPath),
+		}
+		if err := moveFile(plan.linkAbs, filepath.Join(backup.dir, entry.File)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		backup.manifest.Entries = append(backup.manifest.Entries, entry)
+		adopted = append(adopted, entry)
+	}
+
+	return adopted, errors.Join(errs...)
+}
+
+// ListBackups returns the available backups, oldest first.
+func (m *Manager) ListBackups() ([]*models.BackupManifest, error) {
+	entries, err := os.ReadDir(m.resolver.BackupDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var manifests []*models.BackupManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := m.LoadBackup(entry.Name())
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Name < manifests[j].Name
+	})
+	return manifests, nil
+}
+
+// LoadBackup reads the manifest of a named backup.
+func (m *Manager) LoadBackup(name string) (*models.BackupManifest, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid backup name %q", name)
+	}
+
+	path := filepath.Join(m.resolver.BackupDir(), name, models.BackupManifestFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("backup %s not found: %w", name, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var manifest models.BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	manifest.Name = name
+	return &manifest, nil
+}
+
+// Restore copies the files of a backup back to their original paths, replacing the
+// links mindful created there. Paths that were edited after adoption are only
+// replaced with force. Restored paths are dropped from the apply state.
+func (m *Manager) Restore(name string, force bool) ([]models.BackupEntry, error) {
+	manifest, err := m.LoadBackup(name)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(m.resolver.BackupDir(), name)
+
+	var restored []models.BackupEntry
+	var errs []error
+	for _, entry := range manifest.Entries {
+		linkAbs, _ := m.resolver.ResolveLink(entry.Path)
+		source := filepath.Join(dir, filepath.FromSlash(entry.File))
+		if !isWithin(dir, source) {
+			errs = append(errs, fmt.Errorf("backup entry %s escapes the backup directory", entry.File))
+			continue
+		}
+		if _, err := os.Stat(source); err != nil {
+			errs = append(errs, fmt.Errorf("backup copy of %s is unavailable: %w", entry.Path, err))
+			continue
+		}
+
+		if err := m.clearForRestore(linkAbs, entry, force); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := copyFile(source, linkAbs); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		restored = append(restored, entry)
+	}
+
+	if err := m.forgetPaths(restored); err != nil {
+		errs = append(errs, err)
+	}
+
+	return restored, errors.Join(errs...)
+}
+
+// clearForRestore removes whatever mindful left at linkAbs so the original can return.
+func (m *Manager) clearForRestore(linkAbs string, entry models.BackupEntry, force bool) error {
+	info, err := os.Lstat(linkAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect %s: %w", linkAbs, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot restore %s: existing path is a directory", entry.Path)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 && !force {
+		state, err := m.LoadState()
+		if err != nil {
+			return err
+		}
+		servers := mergedServers(m.resolver, state, linkAbs)
+		owned, err := writtenByMindful(linkAbs, m.resolver.ResolveTarget(entry.Target), entry.Mode, info, servers)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return fmt.Errorf("%s changed since it was adopted (use --force to overwrite)", entry.Path)
+		}
+	}
+
+	if err := os.Remove(linkAbs); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+	}
+	return nil
+}
+
+// writtenByMindful reports whether a regular file holds only what mindful put there.
+// servers lists the MCP servers the apply state records for a merged JSON file.
+func writtenByMindful(linkAbs, targetAbs, mode string, info os.FileInfo, servers []string) (bool, error) {
+	switch mode {
+	case models.LinkModeInject:
+		content, _, err := readUserFile(linkAbs)
+		if err != nil {
+			return false, err
+		}
+		doc, err := parseInjected(content)
+		if err != nil {
+			return false, nil
+		}
+		return doc.hasBlocks && strings.TrimSpace(doc.render(nil)) == "", nil
+	case models.LinkModeMerge:
+		content, _, err := readUserFile(linkAbs)
+		if err != nil {
+			return false, err
+		}
+		if isTOMLPath(linkAbs) {
+			doc, err := parseTOMLDocument(content)
+			if err != nil || len(doc.owned) == 0 {
+				return false, nil
+			}
+			doc.strip()
+			return doc.isEmpty(), nil
+		}
+		doc, err := parseMCPDocument([]byte(content), "", servers)
+		if err != nil || len(doc.owned) == 0 {
+			return false, nil
+		}
+		doc.upsert(nil)
+		return doc.isEmpty(), nil
+	}
+	return isOwnedFile(linkAbs, targetAbs, mode, info)
+}
+
+// forgetPaths drops restored paths from the apply state so later runs leave them alone.
+func (m *Manager) forgetPaths(entries []models.BackupEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	state, err := m.LoadState()
+	if err != nil {
+		return err
+	}
+
+	restored := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		linkAbs, _ := m.resolver.ResolveLink(entry.Path)
+		restored[linkAbs] = struct{}{}
+	}
+
+	for tool, toolState := range state.Tools {
+		kept := toolState.Entries[:0]
+		for _, entry := range toolState.Entries {
+			linkAbs, _ := m.resolver.ResolveLink(entry.Path)
+			if _, ok := restored[linkAbs]; !ok {
+				kept = append(kept, entry)
+			}
+		}
+		toolState.Entries = kept
+		if len(kept) == 0 {
+			delete(state.Tools, tool)
+		}
+	}
+
+	return m.SaveState(state)
+}
+
+// backupFileName maps an adopted path to its location inside a backup directory.
+func backupFileName(linkPath string) string {
+	clean := filepath.Clean(linkPath)
+	if filepath.IsAbs(clean) {
+		clean = filepath.Join("_abs", strings.TrimPrefix(clean, filepath.VolumeName(clean)))
+	}
+	return filepath.ToSlash(filepath.Join("files", clean))
+}
+
+func writeManifest(dir string, manifest *models.BackupManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return writeFileAtomic(filepath.Join(dir, models.BackupManifestFileName), append(data, '\n'), 0o644)
+}
+
+// moveFile renames src to dst, falling back to copy and delete across filesystems.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", src, err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst, preserving the permission bits.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
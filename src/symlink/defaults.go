@@ -2,6 +2,7 @@ package symlink
 
 import (
 	_ "embed"
+	"fmt"
 	"sync"
 
 	"gopkg.in/yaml.v3"
@@ -30,3 +31,12 @@ func DefaultConfig() (*models.SymlinkConfig, error) {
 	})
 	return defaultConfig, defaultConfigErr
 }
+
+// ResolveConfig merges project-level overrides from mindful.yaml over the default mapping.
+func ResolveConfig(overrides map[string]*models.ToolSymlinkOverride) (*models.SymlinkConfig, error) {
+	base, err := DefaultConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default symlink configuration: %w", err)
+	}
+	return base.Merge(overrides), nil
+}
@@ -0,0 +1,336 @@
+package symlink
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"mindful/src/models"
+)
+
+const (
+	injectBeginFormat   = "<!-- mindful:begin scope=%s -->"
+	injectEndFormat     = "<!-- mindful:end scope=%s -->"
+	injectHeadingFormat = "## Mindful Memory (scope: %s)"
+)
+
+var (
+	injectBeginPattern = regexp.MustCompile(`^<!-- mindful:begin scope=(\S+) -->$`)
+	injectEndPattern   = regexp.MustCompile(`^<!-- mindful:end scope=(\S+) -->$`)
+)
+
+// injectedDocument is a user-owned file split into user text and mindful blocks.
+// Parts keep their original bytes, so rendering only touches the blocks.
+type injectedDocument struct {
+	parts     []injectedPart // User text and mindful blocks in file order
+	eol       string         // Line ending used by the file
+	hasBlocks bool           // Whether the file already contains mindful blocks
+}
+
+// injectedPart is a run of user lines (scope is empty) or one mindful block,
+// including its line terminators.
+type injectedPart struct {
+	scope string
+	text  string
+}
+
+// parseInjected splits content at mindful begin/end markers.
+func parseInjected(content string) (*injectedDocument, error) {
+	doc := &injectedDocument{eol: "\n"}
+	if i := strings.Index(content, "\n"); i > 0 && content[i-1] == '\r' {
+		doc.eol = "\r\n"
+	}
+	if content == "" {
+		return doc, nil
+	}
+
+	var user, block strings.Builder
+	openScope := ""
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if openScope != "" {
+			block.WriteString(line)
+			if match := injectEndPattern.FindStringSubmatch(trimmed); match != nil && match[1] == openScope {
+				doc.parts = append(doc.parts, injectedPart{scope: openScope, text: block.String()})
+				block.Reset()
+				openScope = ""
+			}
+			continue
+		}
+
+		if match := injectBeginPattern.FindStringSubmatch(trimmed); match != nil {
+			if user.Len() > 0 {
+				doc.parts = append(doc.parts, injectedPart{text: user.String()})
+				user.Reset()
+			}
+			openScope = match[1]
+			doc.hasBlocks = true
+			block.WriteString(line)
+			continue
+		}
+		user.WriteString(line)
+	}
+
+	if openScope != "" {
+		return nil, fmt.Errorf("unterminated mindful block for scope %s", openScope)
+	}
+	if user.Len() > 0 {
+		doc.parts = append(doc.parts, injectedPart{text: user.String()})
+	}
+
+	return doc, nil
+}
+
+// render returns the document with each existing block replaced in place by the
+// section of the same scope. Blocks without a section are removed together with
+// the blank line that separates them; sections without a block are inserted next
+// to the blocks of their neighbouring sections, or appended to the file. User text
+// and line endings are kept as they are. Passing no sections strips every block.
+func (d *injectedDocument) render(sections []models.MemorySection) string {
+	// Pair existing blocks with sections of the same scope, in order.
+	matched := make(map[int]int)
+	used := make([]bool, len(sections))
+	for i, part := range d.parts {
+		if part.scope == "" {
+			continue
+		}
+		for j, section := range sections {
+			if !used[j] && section.Scope == part.scope {
+				matched[i] = j
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	// New sections go before the block of the next matched section; the rest
+	// follow the last matched block.
+	insertBefore := make(map[int][]models.MemorySection)
+	var pending []models.MemorySection
+	last := -1
+	for j, section := range sections {
+		if !used[j] {
+			pending = append(pending, section)
+			continue
+		}
+		for i, k := range matched {
+			if k == j {
+				insertBefore[i] = append(insertBefore[i], pending...)
+				pending = nil
+				if i > last {
+					last = i
+				}
+			}
+		}
+	}
+
+	var out strings.Builder
+	appendBlocks := func(blocks []models.MemorySection) {
+		for _, section := range blocks {
+			text := out.String()
+			if text != "" && !strings.HasSuffix(text, "\n") {
+				out.WriteString(d.eol)
+			}
+			if text != "" {
+				out.WriteString(d.eol)
+			}
+			out.WriteString(d.renderBlock(section))
+			out.WriteString(d.eol)
+		}
+	}
+
+	for i, part := range d.parts {
+		if part.scope == "" {
+			out.WriteString(part.text)
+			continue
+		}
+		j, ok := matched[i]
+		if !ok {
+			// Drop the separator mindful wrote before the block.
+			text := out.String()
+			if strings.HasSuffix(text, d.eol+d.eol) || text == d.eol {
+				out.Reset()
+				out.WriteString(strings.TrimSuffix(text, d.eol))
+			}
+			continue
+		}
+		for _, section := range insertBefore[i] {
+			out.WriteString(d.renderBlock(section))
+			out.WriteString(d.eol + d.eol)
+		}
+		out.WriteString(d.renderBlock(sections[j]))
+		if strings.HasSuffix(part.text, "\n") {
+			out.WriteString(d.eol)
+		}
+		if i == last {
+			appendBlocks(pending)
+			pending = nil
+		}
+	}
+	appendBlocks(pending)
+
+	return out.String()
+}
+
+// renderBlock renders a section with the document's line endings.
+func (d *injectedDocument) renderBlock(section models.MemorySection) string {
+	block := strings.ReplaceAll(renderInjectedBlock(section), "\r\n", "\n")
+	return strings.ReplaceAll(block, "\n", d.eol)
+}
+
+func renderInjectedBlock(section models.MemorySection) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(injectBeginFormat, section.Scope))
+	builder.WriteString("\n")
+	builder.WriteString(fmt.Sprintf(injectHeadingFormat, section.Scope))
+	builder.WriteString("\n\n")
+	if content := strings.TrimSpace(section.Content); content != "" {
+		builder.WriteString(content)
+		builder.WriteString("\n")
+	}
+	builder.WriteString(fmt.Sprintf(injectEndFormat, section.Scope))
+	return builder.String()
+}
+
+// readInjectTarget loads the memory sections that should be injected.
+func readInjectTarget(targetAbs string) ([]models.MemorySection, error) {
+	data, err := os.ReadFile(targetAbs)
+	if err != nil {
+		return nil, err
+	}
+	return models.ParseMemorySections(string(data)), nil
+}
+
+// readUserFile returns the contents of a user-owned file, treating symlinks and
+// missing files as empty so they are replaced by a real file on the next apply.
+func readUserFile(path string) (string, bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return "", true, nil
+	}
+	if !info.Mode().IsRegular() {
+		return "", true, fmt.Errorf("cannot inject into %s: existing path is not a regular file", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), true, nil
+}
+
+// evaluateInjected classifies a file that should carry injected memory blocks.
+func evaluateInjected(linkAbs, targetAbs string) (string, error) {
+	content, exists, err := readUserFile(linkAbs)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return models.LinkStatusMissing, nil
+	}
+
+	if info, err := os.Lstat(linkAbs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return models.LinkStatusStale, nil
+	}
+
+	doc, err := parseInjected(content)
+	if err != nil {
+		return models.LinkStatusConflict, nil
+	}
+
+	sections, err := readInjectTarget(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if doc.hasBlocks {
+				return models.LinkStatusOK, nil
+			}
+			return models.LinkStatusMissing, nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", targetAbs, err)
+	}
+
+	if doc.render(sections) == content {
+		return models.LinkStatusOK, nil
+	}
+	if doc.hasBlocks {
+		return models.LinkStatusStale, nil
+	}
+	return models.LinkStatusMissing, nil
+}
+
+// writeInjected updates the mindful blocks inside linkAbs, leaving other content untouched.
+func writeInjected(linkAbs, targetAbs string) error {
+	sections, err := readInjectTarget(targetAbs)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", targetAbs, err)
+	}
+
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return err
+	}
+
+	doc, err := parseInjected(content)
+	if err != nil {
+		return fmt.Errorf("cannot update %s: %w", linkAbs, err)
+	}
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Lstat(linkAbs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		// Replace a link from a previous mode rather than writing through it.
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to replace existing symlink %s: %w", linkAbs, err)
+		}
+	} else if err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return writeFileAtomic(linkAbs, []byte(doc.render(sections)), perm)
+}
+
+// removeInjected strips mindful blocks from linkAbs and deletes the file if nothing else remains.
+func removeInjected(linkAbs string) error {
+	info, err := os.Lstat(linkAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s: %w", linkAbs, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+		}
+		return nil
+	}
+
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return err
+	}
+	doc, err := parseInjected(content)
+	if err != nil {
+		return fmt.Errorf("cannot clean %s: %w", linkAbs, err)
+	}
+	if !doc.hasBlocks {
+		return nil
+	}
+
+	remaining := doc.render(nil)
+	if strings.TrimSpace(remaining) == "" {
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+		}
+		return nil
+	}
+	return writeFileAtomic(linkAbs, []byte(remaining), info.Mode().Perm())
+}
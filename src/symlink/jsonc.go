@@ -0,0 +1,369 @@
+package symlink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsoncObject locates an object and its members in JSON with comments and trailing
+// commas (JSONC, as used by Zed and VS Code settings), so single members can be
+// rewritten while every other byte of the file is kept.
+type jsoncObject struct {
+	open, close   int // Offsets of the braces
+	members       []jsoncMember
+	trailingComma bool // A comma follows the last member
+	tailStart     int  // Start of the trivia before the closing brace
+}
+
+// jsoncMember is one "key": value pair. Its chunk starts right after the opening
+// brace or the previous comma, so it carries the comments and blank lines above it.
+type jsoncMember struct {
+	key                  string
+	chunkStart, keyStart int
+	valueStart, valueEnd int
+}
+
+// jsoncEdit describes the changes rewrite makes to an object's members.
+type jsoncEdit struct {
+	remove map[string]bool            // Members to drop
+	text   map[string]string          // Members whose value is replaced by rendered text
+	values map[string]json.RawMessage // Members to replace, or append in key order, in the object's layout
+}
+
+// parseJSONC locates the top-level object of src.
+func parseJSONC(src string) (*jsoncObject, error) {
+	i := skipJSONCTrivia(src, 0)
+	if i >= len(src) || src[i] != '{' {
+		return nil, jsoncError(src, i, "expected a top-level object")
+	}
+	obj, end, err := parseJSONCObject(src, i)
+	if err != nil {
+		return nil, err
+	}
+	if i = skipJSONCTrivia(src, end); i < len(src) {
+		return nil, jsoncError(src, i, "unexpected content after the top-level object")
+	}
+	return obj, nil
+}
+
+// parseJSONCObject parses the object whose opening brace is at src[open] and returns
+// it with the offset just past its closing brace.
+func parseJSONCObject(src string, open int) (*jsoncObject, int, error) {
+	obj := &jsoncObject{open: open, tailStart: open + 1}
+	chunkStart := open + 1
+	i := skipJSONCTrivia(src, chunkStart)
+	for {
+		if i >= len(src) {
+			return nil, 0, jsoncError(src, i, "unterminated object")
+		}
+		if src[i] == '}' {
+			obj.close = i
+			return obj, i + 1, nil
+		}
+		if src[i] != '"' {
+			return nil, 0, jsoncError(src, i, "expected a string key")
+		}
+
+		member := jsoncMember{chunkStart: chunkStart, keyStart: i}
+		end, err := scanJSONCString(src, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal([]byte(src[i:end]), &member.key); err != nil {
+			return nil, 0, jsoncError(src, i, "invalid key")
+		}
+		if i = skipJSONCTrivia(src, end); i >= len(src) || src[i] != ':' {
+			return nil, 0, jsoncError(src, i, "expected ':' after key")
+		}
+		member.valueStart = skipJSONCTrivia(src, i+1)
+		if member.valueEnd, err = scanJSONCValue(src, member.valueStart); err != nil {
+			return nil, 0, err
+		}
+		obj.members = append(obj.members, member)
+		obj.trailingComma = false
+		obj.tailStart = member.valueEnd
+
+		i = skipJSONCTrivia(src, member.valueEnd)
+		if i < len(src) && src[i] == ',' {
+			chunkStart = i + 1
+			obj.trailingComma = true
+			obj.tailStart = chunkStart
+			i = skipJSONCTrivia(src, chunkStart)
+		} else if i < len(src) && src[i] != '}' {
+			return nil, 0, jsoncError(src, i, "expected ',' or '}'")
+		}
+	}
+}
+
+// member returns the last member named key; later duplicates win, as in encoding/json.
+func (o *jsoncObject) member(key string) (jsoncMember, bool) {
+	for i := len(o.members) - 1; i >= 0; i-- {
+		if o.members[i].key == key {
+			return o.members[i], true
+		}
+	}
+	return jsoncMember{}, false
+}
+
+// rewrite returns the object's text with the edit applied. Kept members, comments and
+// layout are copied unchanged; new values follow the indentation of existing members.
+func (o *jsoncObject) rewrite(src string, edit jsoncEdit) (string, error) {
+	layout := o.layout(src)
+
+	var parts []string
+	present := make(map[string]bool, len(o.members))
+	for _, member := range o.members {
+		present[member.key] = true
+		if edit.remove[member.key] {
+			continue
+		}
+		value, ok := edit.text[member.key]
+		if raw, set := edit.values[member.key]; set {
+			rendered, err := layout.value(raw)
+			if err != nil {
+				return "", err
+			}
+			value, ok = rendered, true
+		}
+		if ok {
+			parts = append(parts, src[member.chunkStart:member.valueStart]+value)
+			continue
+		}
+		parts = append(parts, src[member.chunkStart:member.valueEnd])
+	}
+
+	var added []string
+	for key := range edit.values {
+		if !present[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		value, err := layout.value(edit.values[key])
+		if err != nil {
+			return "", err
+		}
+		name, _ := json.Marshal(key)
+		parts = append(parts, layout.newline+layout.indent+string(name)+layout.colon+value)
+	}
+
+	tail := src[o.tailStart:o.close]
+	if len(o.members) == 0 {
+		tail = strings.TrimRight(tail, " \t\r\n")
+		if len(parts) > 0 {
+			// Comments inside an empty object stay above the new members.
+			parts[0] = tail + parts[0]
+			tail = layout.newline + layout.closeIndent
+		}
+	}
+	if len(parts) == 0 {
+		if strings.TrimSpace(tail) == "" {
+			return "{}", nil
+		}
+		return "{" + tail + "}", nil
+	}
+
+	text := "{" + strings.Join(parts, ",")
+	if o.trailingComma {
+		text += ","
+	}
+	return text + tail + "}", nil
+}
+
+// jsoncLayout is the formatting of an object's members.
+type jsoncLayout struct {
+	newline     string // "\n" for one member per line, "" for single-line objects
+	indent      string // Indentation of member lines
+	unit        string // One level of indentation
+	colon       string // Separator between key and value
+	closeIndent string // Indentation of the closing brace
+}
+
+func (o *jsoncObject) layout(src string) jsoncLayout {
+	closeIndent := lineIndent(src, o.open)
+	if len(o.members) == 0 {
+		if strings.Contains(strings.TrimSpace(src), "\n") {
+			return jsoncLayout{newline: "\n", indent: closeIndent + "  ", unit: "  ", colon: ": ", closeIndent: closeIndent}
+		}
+		return jsoncLayout{colon: ":"}
+	}
+
+	first := o.members[0]
+	lineStart := strings.LastIndexByte(src[:first.keyStart], '\n') + 1
+	if lineStart <= o.open {
+		// The first member shares the brace's line: keep the object on one line.
+		colon := ":"
+		if strings.HasPrefix(src[first.keyStart:first.valueStart], `"`+first.key+`": `) {
+			colon = ": "
+		}
+		return jsoncLayout{colon: colon}
+	}
+
+	indent := src[lineStart:first.keyStart]
+	if strings.TrimSpace(indent) != "" {
+		indent = closeIndent + "  "
+	}
+	unit := strings.TrimPrefix(indent, closeIndent)
+	if unit == "" || !strings.HasPrefix(indent, closeIndent) {
+		unit = "  "
+	}
+	return jsoncLayout{newline: "\n", indent: indent, unit: unit, colon: ": ", closeIndent: closeIndent}
+}
+
+// value renders a member value in the layout's indentation.
+func (l jsoncLayout) value(raw json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	if l.newline == "" {
+		err = json.Compact(&buf, raw)
+	} else {
+		err = json.Indent(&buf, raw, l.indent, l.unit)
+	}
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// lineIndent returns the whitespace that starts the line containing src[offset].
+func lineIndent(src string, offset int) string {
+	lineStart := strings.LastIndexByte(src[:offset], '\n') + 1
+	end := lineStart
+	for end < offset && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[lineStart:end]
+}
+
+// stripJSONC converts JSONC to plain JSON by dropping comments and trailing commas.
+// It reports whether the text contained comments.
+func stripJSONC(src string) (string, bool) {
+	var out strings.Builder
+	comments := false
+	for i := 0; i < len(src); {
+		switch {
+		case src[i] == '"':
+			end, err := scanJSONCString(src, i)
+			if err != nil {
+				end = len(src)
+			}
+			out.WriteString(src[i:end])
+			i = end
+		case strings.HasPrefix(src[i:], "//") || strings.HasPrefix(src[i:], "/*"):
+			comments = true
+			i = skipJSONCComment(src, i)
+		case src[i] == ',':
+			next := skipJSONCTrivia(src, i+1)
+			if next < len(src) && (src[next] == '}' || src[next] == ']') {
+				i++
+				continue
+			}
+			out.WriteByte(',')
+			i++
+		default:
+			out.WriteByte(src[i])
+			i++
+		}
+	}
+	return out.String(), comments
+}
+
+// skipJSONCTrivia returns the offset of the next character that is neither
+// whitespace nor part of a comment.
+func skipJSONCTrivia(src string, i int) int {
+	for i < len(src) {
+		switch {
+		case src[i] == ' ' || src[i] == '\t' || src[i] == '\r' || src[i] == '\n':
+			i++
+		case strings.HasPrefix(src[i:], "//") || strings.HasPrefix(src[i:], "/*"):
+			i = skipJSONCComment(src, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipJSONCComment returns the offset just past the comment starting at src[i].
+func skipJSONCComment(src string, i int) int {
+	if strings.HasPrefix(src[i:], "//") {
+		if end := strings.IndexByte(src[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(src)
+	}
+	if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+		return i + 2 + end + 2
+	}
+	return len(src)
+}
+
+// scanJSONCString returns the offset just past the string starting at src[i].
+func scanJSONCString(src string, i int) (int, error) {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		case '\n':
+			return 0, jsoncError(src, i, "unterminated string")
+		}
+	}
+	return 0, jsoncError(src, i, "unterminated string")
+}
+
+// scanJSONCValue returns the offset just past the value starting at src[i].
+func scanJSONCValue(src string, i int) (int, error) {
+	if i >= len(src) {
+		return 0, jsoncError(src, i, "expected a value")
+	}
+	switch src[i] {
+	case '{':
+		_, end, err := parseJSONCObject(src, i)
+		return end, err
+	case '[':
+		j := skipJSONCTrivia(src, i+1)
+		for {
+			if j >= len(src) {
+				return 0, jsoncError(src, i, "unterminated array")
+			}
+			if src[j] == ']' {
+				return j + 1, nil
+			}
+			end, err := scanJSONCValue(src, j)
+			if err != nil {
+				return 0, err
+			}
+			j = skipJSONCTrivia(src, end)
+			if j < len(src) && src[j] == ',' {
+				j = skipJSONCTrivia(src, j+1)
+			} else if j < len(src) && src[j] != ']' {
+				return 0, jsoncError(src, j, "expected ',' or ']'")
+			}
+		}
+	case '"':
+		return scanJSONCString(src, i)
+	}
+
+	j := i
+	for j < len(src) && !strings.ContainsRune(",}] \t\r\n/", rune(src[j])) {
+		j++
+	}
+	if j == i {
+		return 0, jsoncError(src, i, "expected a value")
+	}
+	return j, nil
+}
+
+func jsoncError(src string, offset int, message string) error {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	line := strings.Count(src[:offset], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, message)
+}
@@ -0,0 +1,75 @@
+package symlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsManagedFile reports whether path is a link or copy that mindful created from outDir,
+// as opposed to a file the user wrote.
+func IsManagedFile(path, outDir string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		dest, err := os.Readlink(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		if !filepath.IsAbs(dest) {
+			dest = filepath.Join(filepath.Dir(path), dest)
+		}
+		return isWithin(outDir, dest), nil
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	_, managed, err := readStamp(path, data)
+	return managed, err
+}
+
+// StripManagedContent removes what mindful injected or merged into a user-owned file,
+// returning only the user's own content. servers lists the MCP servers the apply state
+// records as merged into a JSON file (see MergedServers).
+func StripManagedContent(path string, data []byte, servers []string) ([]byte, error) {
+	if isTOMLPath(path) {
+		doc, err := parseTOMLDocument(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if len(doc.owned) == 0 {
+			return data, nil
+		}
+		doc.strip()
+		return doc.render(), nil
+	}
+	if isJSONPath(path) {
+		doc, err := parseMCPDocument(data, "", servers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if len(doc.owned) == 0 {
+			return data, nil
+		}
+		doc.upsert(nil)
+		return doc.render()
+	}
+
+	doc, err := parseInjected(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if !doc.hasBlocks {
+		return data, nil
+	}
+	return []byte(strings.TrimSpace(doc.render(nil))), nil
+}
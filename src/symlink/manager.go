@@ -11,6 +11,9 @@ import (
 	"mindful/src/models"
 )
 
+// ErrToolNotConfigured is returned when a tool has no usable link mapping.
+var ErrToolNotConfigured = errors.New("no symlink configuration")
+
 // Manager orchestrates planning, creation, validation, and cleanup of symlinks.
 type Manager struct {
 	projectPath string
@@ -54,9 +57,10 @@ func (m *Manager) PlanSymlinks(toolName string) ([]models.SymlinkInfo, error) {
 	return infos, nil
 }
 
-// ListSymlinks reports the current state of symlinks for a tool.
+// ListSymlinks reports the current state of symlinks for a tool, including paths
+// recorded by a previous apply that are no longer part of the mapping.
 func (m *Manager) ListSymlinks(toolName string) ([]models.SymlinkInfo, error) {
-	plans, err := m.plan(toolName, false)
+	plans, err := m.planWithRecorded(toolName)
 	if err != nil {
 		return nil, err
 	}
@@ -69,18 +73,35 @@ func (m *Manager) ListSymlinks(toolName string) ([]models.SymlinkInfo, error) {
 	return infos, nil
 }
 
+// ApplyOptions tunes how Apply treats paths that mindful does not fully own.
+type ApplyOptions struct {
+	Force bool // Overwrite hand-edited copies and unmanaged files
+}
+
 // CreateSymlinks ensures all declared symlinks exist and point to mindful/out artifacts.
 func (m *Manager) CreateSymlinks(toolName string) error {
+	return m.Apply(toolName, ApplyOptions{})
+}
+
+// Apply materialises every declared artefact for a tool using its configured link mode.
+func (m *Manager) Apply(toolName string, opts ApplyOptions) error {
 	plans, err := m.plan(toolName, true)
 	if err != nil {
 		return err
 	}
 
 	var errs []error
+	applied := make([]*plannedLink, 0, len(plans))
 	for _, plan := range plans {
-		if err := m.ensureSymlink(plan); err != nil {
+		if err := m.ensureLink(plan, opts); err != nil {
 			errs = append(errs, err)
+			continue
 		}
+		applied = append(applied, plan)
+	}
+
+	if err := m.recordApply(toolName, applied); err != nil {
+		errs = append(errs, err)
 	}
 
 	return errors.Join(errs...)
@@ -91,16 +112,23 @@ func (m *Manager) UpdateSymlinks(toolName string) error {
 	return m.CreateSymlinks(toolName)
 }
 
-// CleanupSymlinks removes the symlinks declared for a tool.
+// CleanupSymlinks removes the symlinks declared for a tool, along with any paths
+// recorded for it by a previous apply.
 func (m *Manager) CleanupSymlinks(toolName string) error {
-	plans, err := m.plan(toolName, false)
+	plans, err := m.planWithRecorded(toolName)
 	if err != nil {
 		return err
 	}
 
 	var errs []error
 	for _, plan := range plans {
-		if err := m.removeSymlink(plan); err != nil {
+		if err := m.removeLink(plan); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := m.ForgetTool(toolName); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -135,29 +163,69 @@ func (m *Manager) plan(toolName string, verifyTargets bool) ([]*plannedLink, err
 
 	toolConfig, ok := m.config.ToolConfig(toolName)
 	if !ok || toolConfig == nil || toolConfig.IsEmpty() {
-		return nil, fmt.Errorf("no symlink configuration for tool %q", toolName)
+		return nil, fmt.Errorf("%w for tool %q", ErrToolNotConfigured, toolName)
+	}
+
+	state, err := m.LoadState()
+	if err != nil {
+		return nil, err
 	}
 
-	planner := newPlanner(m.resolver, toolConfig)
+	planner := newPlanner(m.resolver, toolName, toolConfig)
+	if toolState, ok := state.Tools[toolName]; ok {
+		for _, entry := range toolState.Entries {
+			if len(entry.Servers) > 0 {
+				linkAbs, _ := m.resolver.ResolveLink(entry.Path)
+				planner.merged[filepath.Clean(linkAbs)] = entry.Servers
+			}
+		}
+	}
 	return planner.buildPlans(verifyTargets)
 }
 
-func (m *Manager) ensureSymlink(plan *plannedLink) error {
-	if plan == nil {
-		return nil
+// planWithRecorded returns the current plan followed by recorded entries that
+// are no longer mapped. Tools that were unmapped since the last apply yield only
+// their recorded entries.
+func (m *Manager) planWithRecorded(toolName string) ([]*plannedLink, error) {
+	plans, err := m.plan(toolName, false)
+	if err != nil && !errors.Is(err, ErrToolNotConfigured) {
+		return nil, err
 	}
+	planErr := err
 
-	// Quick exit when the existing symlink is already correct.
-	if plan.info.IsValid {
+	recorded, err := m.recordedOnly(toolName, plans)
+	if err != nil {
+		return nil, err
+	}
+	if planErr != nil && len(recorded) == 0 {
+		return nil, planErr
+	}
+
+	return append(plans, recorded...), nil
+}
+
+func (m *Manager) ensureLink(plan *plannedLink, opts ApplyOptions) error {
+	if plan == nil {
 		return nil
 	}
 
-	// Refuse to overwrite an existing non-symlink to avoid destroying user files.
-	if stat, err := os.Lstat(plan.linkAbs); err == nil {
-		if stat.Mode()&os.ModeSymlink == 0 {
+	switch plan.info.Status {
+	case models.LinkStatusOK:
+		// Quick exit when the existing link or copy is already correct.
+		return nil
+	case models.LinkStatusConflict:
+		if !opts.Force {
 			return fmt.Errorf(
-				"cannot create symlink at %s: a regular file or directory already exists. "+
-					"Please back up and remove it before rerunning mindful apply",
+				"cannot create %s at %s: a regular file or directory already exists. "+
+					"Please back up and remove it, or rerun mindful apply --force to overwrite it",
+				plan.mode, plan.info.LinkPath,
+			)
+		}
+	case models.LinkStatusModified:
+		if !opts.Force {
+			return fmt.Errorf(
+				"refusing to overwrite %s: the managed copy was edited by hand. "+
+					"Move your changes into the mindful sources, or rerun mindful apply --force to discard them",
 				plan.info.LinkPath,
 			)
 		}
@@ -167,6 +235,32 @@ func (m *Manager) ensureSymlink(plan *plannedLink) error {
 		return fmt.Errorf("failed to prepare directory for %s: %w", plan.linkAbs, err)
 	}
 
+	switch plan.mode {
+	case models.LinkModeCopy:
+		return m.writeCopy(plan)
+	case models.LinkModeInject:
+		return writeInjected(plan.linkAbs, plan.targetAbs)
+	case models.LinkModeMerge:
+		servers, err := writeMerged(plan.linkAbs, plan.targetAbs, m.mergeOwner(), plan.servers, opts.Force)
+		if err != nil {
+			return err
+		}
+		plan.servers = servers
+		return nil
+	case models.LinkModeHardlink:
+		if err := m.clearExistingPath(plan.linkAbs); err != nil {
+			return err
+		}
+		if err := os.Link(plan.targetAbs, plan.linkAbs); err != nil {
+			return fmt.Errorf("failed to create hardlink %s -> %s: %w", plan.linkAbs, plan.targetAbs, err)
+		}
+		return nil
+	default:
+		return m.writeSymlink(plan)
+	}
+}
+
+func (m *Manager) writeSymlink(plan *plannedLink) error {
 	if err := m.clearExistingPath(plan.linkAbs); err != nil {
 		return err
 	}
@@ -193,11 +287,41 @@ func (m *Manager) ensureSymlink(plan *plannedLink) error {
 	return nil
 }
 
-func (m *Manager) removeSymlink(plan *plannedLink) error {
+func (m *Manager) writeCopy(plan *plannedLink) error {
+	content, err := os.ReadFile(plan.targetAbs)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", plan.targetAbs, err)
+	}
+
+	stamped, err := stampContent(plan.linkAbs, content)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(plan.linkAbs, stamped, artifactPerm(plan.kind))
+}
+
+// artifactPerm returns the permissions for materialised copies; MCP files carry
+// resolved credentials and are kept private to the user.
+func artifactPerm(kind string) os.FileMode {
+	if kind == models.ArtifactMCP {
+		return 0o600
+	}
+	return 0o644
+}
+
+func (m *Manager) removeLink(plan *plannedLink) error {
 	if plan == nil {
 		return nil
 	}
 
+	switch plan.mode {
+	case models.LinkModeInject:
+		return removeInjected(plan.linkAbs)
+	case models.LinkModeMerge:
+		return removeMerged(plan.linkAbs, m.mergeOwner(), plan.servers)
+	}
+
 	info, err := os.Lstat(plan.linkAbs)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -207,16 +331,47 @@ func (m *Manager) removeSymlink(plan *plannedLink) error {
 	}
 
 	if info.Mode()&os.ModeSymlink == 0 {
-		// Skip non-symlink paths to avoid accidental data loss.
-		return nil
+		owned, err := isOwnedFile(plan.linkAbs, plan.targetAbs, plan.mode, info)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			// Skip unmanaged or hand-edited files to avoid accidental data loss.
+			return nil
+		}
 	}
 
 	if err := os.Remove(plan.linkAbs); err != nil {
-		return fmt.Errorf("failed to remove symlink %s: %w", plan.linkAbs, err)
+		return fmt.Errorf("failed to remove %s: %w", plan.linkAbs, err)
 	}
 	return nil
 }
 
+// isOwnedFile reports whether a regular file was written by mindful and is safe to remove.
+func isOwnedFile(linkAbs, targetAbs, mode string, info os.FileInfo) (bool, error) {
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	if mode == models.LinkModeHardlink {
+		if targetInfo, err := os.Stat(targetAbs); err == nil && os.SameFile(info, targetInfo) {
+			return true, nil
+		}
+	}
+
+	data, err := os.ReadFile(linkAbs)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", linkAbs, err)
+	}
+	stamp, ok, err := readStamp(linkAbs, data)
+	if err != nil || !ok {
+		return false, err
+	}
+	return !stamp.Modified(), nil
+}
+
+// clearExistingPath removes a symlink or regular file so a new link can take its place.
+// Callers are responsible for deciding whether a regular file may be replaced.
 func (m *Manager) clearExistingPath(linkPath string) error {
 	info, err := os.Lstat(linkPath)
 	if err != nil {
@@ -226,14 +381,42 @@ func (m *Manager) clearExistingPath(linkPath string) error {
 		return fmt.Errorf("failed to inspect %s: %w", linkPath, err)
 	}
 
-	if info.Mode()&os.ModeSymlink != 0 {
-		if err := os.Remove(linkPath); err != nil {
-			return fmt.Errorf("failed to replace existing symlink %s: %w", linkPath, err)
-		}
-		return nil
+	if info.IsDir() {
+		return fmt.Errorf("cannot replace %s: existing path is a directory", linkPath)
 	}
 
-	return fmt.Errorf("cannot replace %s: existing path is not a symlink", linkPath)
+	if err := os.Remove(linkPath); err != nil {
+		return fmt.Errorf("failed to replace existing path %s: %w", linkPath, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	return nil
 }
 
 // plannedLink keeps bookkeeping information for symlink operations.
@@ -241,18 +424,25 @@ type plannedLink struct {
 	info      models.SymlinkInfo
 	linkAbs   string
 	targetAbs string
+	mode      string
+	kind      string
+	servers   []string // MCP servers mindful owns in a merged JSON file
 }
 
 // planner transforms tool configuration into executable plans.
 type planner struct {
+	tool     string
 	config   *models.ToolSymlinkConfig
 	resolver *Resolver
+	merged   map[string][]string // Merged JSON file -> servers recorded by the last apply
 }
 
-func newPlanner(resolver *Resolver, config *models.ToolSymlinkConfig) *planner {
+func newPlanner(resolver *Resolver, toolName string, config *models.ToolSymlinkConfig) *planner {
 	return &planner{
+		tool:     toolName,
 		config:   config,
 		resolver: resolver,
+		merged:   make(map[string][]string),
 	}
 }
 
@@ -280,18 +470,39 @@ func (p *planner) buildPlans(verifyTargets bool) ([]*plannedLink, error) {
 	return plans, nil
 }
 
+func (p *planner) modeFor(kind string) (string, error) {
+	mode := p.config.ModeFor(kind)
+	if !models.SupportsLinkMode(kind, mode) {
+		return "", fmt.Errorf("unsupported link mode %q for %s", mode, kind)
+	}
+	return mode, nil
+}
+
 func (p *planner) planMemory(verify bool) (*plannedLink, error) {
 	if p.config == nil || strings.TrimSpace(p.config.Memory) == "" {
 		return nil, nil
 	}
-	return p.planSingle(p.config.Memory, p.resolver.MemoryArtifact(), verify)
+	mode, err := p.modeFor(models.ArtifactMemory)
+	if err != nil {
+		return nil, err
+	}
+	return p.planSingle(models.ArtifactMemory, p.config.Memory, p.resolver.MemoryArtifact(p.tool), mode, verify)
 }
 
 func (p *planner) planMCP(verify bool) (*plannedLink, error) {
 	if p.config == nil || strings.TrimSpace(p.config.MCP) == "" {
 		return nil, nil
 	}
-	return p.planSingle(p.config.MCP, p.resolver.MCPArtifact(), verify)
+	mode, err := p.modeFor(models.ArtifactMCP)
+	if err != nil {
+		return nil, err
+	}
+	format := p.config.MCPFormatName()
+	target := p.resolver.MCPArtifact(p.tool, format)
+	if mode == models.LinkModeMerge && isTOMLPath(p.config.MCP) != isTOMLPath(target) {
+		return nil, fmt.Errorf("cannot merge %s MCP configuration into %s: file types differ", format, p.config.MCP)
+	}
+	return p.planSingle(models.ArtifactMCP, p.config.MCP, target, mode, verify)
 }
 
 func (p *planner) planSubagents(verify bool) ([]*plannedLink, error) {
@@ -300,7 +511,12 @@ func (p *planner) planSubagents(verify bool) ([]*plannedLink, error) {
 		return nil, nil
 	}
 
-	entries, err := os.ReadDir(p.resolver.SubagentDir())
+	mode, err := p.modeFor(models.ArtifactSubagents)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(p.resolver.SubagentDir(p.tool))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -315,9 +531,9 @@ func (p *planner) planSubagents(verify bool) ([]*plannedLink, error) {
 		}
 		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
 		linkPath := strings.ReplaceAll(template, SubagentPlaceholder, name)
-		target := filepath.Join(p.resolver.SubagentDir(), entry.Name())
+		target := filepath.Join(p.resolver.SubagentDir(p.tool), entry.Name())
 
-		plan, err := p.planSingle(linkPath, target, verify)
+		plan, err := p.planSingle(models.ArtifactSubagents, linkPath, target, mode, verify)
 		if err != nil {
 			return nil, err
 		}
@@ -329,7 +545,7 @@ func (p *planner) planSubagents(verify bool) ([]*plannedLink, error) {
 	return plans, nil
 }
 
-func (p *planner) planSingle(linkTemplate, target string, verify bool) (*plannedLink, error) {
+func (p *planner) planSingle(kind, linkTemplate, target, mode string, verify bool) (*plannedLink, error) {
 	linkAbs, linkRel := p.resolver.ResolveLink(linkTemplate)
 	targetAbs := p.resolver.ResolveTarget(target)
 	targetRel := p.resolver.RelativeToProject(targetAbs)
@@ -344,6 +560,9 @@ func (p *planner) planSingle(linkTemplate, target string, verify bool) (*planned
 			return nil, fmt.Errorf("failed to stat target %s: %w", targetAbs, err)
 		}
 		isDir = info.IsDir()
+		if isDir && mode != models.LinkModeSymlink {
+			return nil, fmt.Errorf("target %s is a directory and can only be symlinked", targetAbs)
+		}
 	}
 
 	info := models.SymlinkInfo{
@@ -351,54 +570,123 @@ func (p *planner) planSingle(linkTemplate, target string, verify bool) (*planned
 		TargetPath:  targetRel,
 		IsDirectory: isDir,
 		IsValid:     false,
+		Mode:        mode,
+		Kind:        kind,
 	}
 
-	exists, isValid, err := p.evaluateExistingLink(linkAbs, targetAbs)
+	var servers []string
+	if mode == models.LinkModeMerge {
+		servers = p.merged[filepath.Clean(linkAbs)]
+	}
+
+	status, err := p.evaluateExisting(linkAbs, targetAbs, mode, servers)
 	if err != nil {
 		return nil, err
 	}
 
-	if exists && isValid {
-		info.IsValid = true
-	}
+	info.Status = status
+	info.IsValid = status == models.LinkStatusOK
 
 	return &plannedLink{
 		info:      info,
 		linkAbs:   linkAbs,
 		targetAbs: targetAbs,
+		mode:      mode,
+		kind:      kind,
+		servers:   servers,
 	}, nil
 }
 
-func (p *planner) evaluateExistingLink(linkAbs, targetAbs string) (bool, bool, error) {
+// evaluateExisting inspects the path at linkAbs and classifies it for the given mode.
+// servers lists the MCP servers the apply state records for a merged JSON file.
+func (p *planner) evaluateExisting(linkAbs, targetAbs, mode string, servers []string) (string, error) {
+	switch mode {
+	case models.LinkModeInject:
+		return evaluateInjected(linkAbs, targetAbs)
+	case models.LinkModeMerge:
+		return evaluateMerged(linkAbs, targetAbs, mergeOwner(p.resolver.ProjectPath()), servers)
+	}
+
 	stat, err := os.Lstat(linkAbs)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return false, false, nil
+			return models.LinkStatusMissing, nil
 		}
-		return false, false, fmt.Errorf("failed to inspect %s: %w", linkAbs, err)
+		return "", fmt.Errorf("failed to inspect %s: %w", linkAbs, err)
 	}
 
-	if stat.Mode()&os.ModeSymlink == 0 {
-		return true, false, nil
+	if stat.Mode()&os.ModeSymlink != 0 {
+		if mode != models.LinkModeSymlink {
+			// A symlink left behind by a previous mode; safe to replace.
+			return models.LinkStatusStale, nil
+		}
+		matches, err := symlinkPointsTo(linkAbs, targetAbs)
+		if err != nil {
+			return "", err
+		}
+		if matches {
+			return models.LinkStatusOK, nil
+		}
+		return models.LinkStatusStale, nil
 	}
 
-	dest, err := os.Readlink(linkAbs)
+	if !stat.Mode().IsRegular() {
+		return models.LinkStatusConflict, nil
+	}
+
+	if mode == models.LinkModeHardlink {
+		if targetInfo, err := os.Stat(targetAbs); err == nil && os.SameFile(stat, targetInfo) {
+			return models.LinkStatusOK, nil
+		}
+	}
+
+	data, err := os.ReadFile(linkAbs)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", linkAbs, err)
+	}
+	stamp, managed, err := readStamp(linkAbs, data)
 	if err != nil {
-		return true, false, fmt.Errorf("failed to read symlink %s: %w", linkAbs, err)
+		return "", err
+	}
+	if !managed {
+		return models.LinkStatusConflict, nil
+	}
+	if stamp.Modified() {
+		return models.LinkStatusModified, nil
+	}
+	if mode != models.LinkModeCopy {
+		return models.LinkStatusStale, nil
 	}
 
-	if !filepath.IsAbs(dest) {
-		dest = filepath.Join(filepath.Dir(linkAbs), dest)
+	target, err := os.ReadFile(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing to compare against until the next build.
+			return models.LinkStatusOK, nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", targetAbs, err)
 	}
+	targetHash, err := artifactHash(linkAbs, target)
+	if err != nil {
+		return "", err
+	}
+	if targetHash != stamp.RecordedHash {
+		return models.LinkStatusStale, nil
+	}
+	return models.LinkStatusOK, nil
+}
 
-	dest = filepath.Clean(dest)
-	targetAbs = filepath.Clean(targetAbs)
+func symlinkPointsTo(linkAbs, targetAbs string) (bool, error) {
+	dest, err := os.Readlink(linkAbs)
+	if err != nil {
+		return false, fmt.Errorf("failed to read symlink %s: %w", linkAbs, err)
+	}
 
-	if pathsEqual(dest, targetAbs) {
-		return true, true, nil
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(linkAbs), dest)
 	}
 
-	return true, false, nil
+	return pathsEqual(dest, targetAbs), nil
 }
 
 func pathsEqual(a, b string) bool {
@@ -0,0 +1,168 @@
+package symlink
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// jsonMarkerKey is the top-level key used to stamp managed JSON files.
+	jsonMarkerKey = "_mindful"
+	// textMarkerFormat is the comment line used to stamp managed text files.
+	textMarkerFormat = "<!-- mindful:managed sha256=%s (generated copy, edit the mindful sources instead) -->"
+	// tomlMarkerFormat is the comment line used to stamp managed TOML files.
+	tomlMarkerFormat = "# mindful:managed sha256=%s (generated copy, edit the mindful sources instead)"
+	// tomlMergeMarkerFormat lists the [mcp_servers.*] tables one project merged into a TOML file.
+	tomlMergeMarkerFormat = "# mindful:mcp_servers %s project=%s (managed by mindful apply, edit the mindful sources instead)"
+)
+
+var (
+	textMarkerPattern      = regexp.MustCompile(`^<!-- mindful:managed sha256=([0-9a-f]{64})\b.*-->$`)
+	tomlMarkerPattern      = regexp.MustCompile(`^# mindful:managed sha256=([0-9a-f]{64})\b.*$`)
+	tomlMergeMarkerPattern = regexp.MustCompile(`^# mindful:mcp_servers (\[[^\]]*\]) project=("(?:[^"\\]|\\.)*")`)
+)
+
+// textMarker returns the marker line format and pattern for a non-JSON managed file.
+func textMarker(path string) (string, *regexp.Regexp) {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return tomlMarkerFormat, tomlMarkerPattern
+	}
+	return textMarkerFormat, textMarkerPattern
+}
+
+// managedStamp describes the marker found in a materialised copy.
+type managedStamp struct {
+	RecordedHash string // Hash written into the marker when the copy was created
+	BodyHash     string // Hash of the current content with the marker removed
+}
+
+// Modified reports whether the copy was edited after mindful wrote it.
+func (s managedStamp) Modified() bool {
+	return s.RecordedHash != s.BodyHash
+}
+
+// jsonMarker is the value stored under jsonMarkerKey.
+type jsonMarker struct {
+	Managed bool   `json:"managed"`
+	SHA256  string `json:"sha256"`
+}
+
+// isJSONPath reports whether the managed file should be stamped as JSON.
+func isJSONPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// artifactHash returns the hash mindful records for artefact content.
+func artifactHash(path string, content []byte) (string, error) {
+	if isJSONPath(path) {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		delete(doc, jsonMarkerKey)
+		return canonicalJSONHash(doc)
+	}
+	return hashBytes(content), nil
+}
+
+// stampContent returns the artefact content with a managed-file marker embedded.
+func stampContent(path string, content []byte) ([]byte, error) {
+	hash, err := artifactHash(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSONPath(path) {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		doc[jsonMarkerKey] = jsonMarker{Managed: true, SHA256: hash}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		return append(data, '\n'), nil
+	}
+
+	format, _ := textMarker(path)
+	marker := fmt.Sprintf(format, hash) + "\n"
+	offset := frontmatterEnd(content)
+
+	var buf bytes.Buffer
+	buf.Write(content[:offset])
+	buf.WriteString(marker)
+	buf.Write(content[offset:])
+	return buf.Bytes(), nil
+}
+
+// readStamp extracts the managed-file marker from a materialised copy.
+// The boolean result is false when the file carries no mindful marker.
+func readStamp(path string, data []byte) (managedStamp, bool, error) {
+	if isJSONPath(path) {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(data, &doc); err != nil {
+			// Unparseable JSON cannot be ours; treat as unmanaged.
+			return managedStamp{}, false, nil
+		}
+		raw, ok := doc[jsonMarkerKey]
+		if !ok {
+			return managedStamp{}, false, nil
+		}
+		var marker jsonMarker
+		if err := json.Unmarshal(raw, &marker); err != nil || !marker.Managed {
+			return managedStamp{}, false, nil
+		}
+		bodyHash, err := artifactHash(path, data)
+		if err != nil {
+			return managedStamp{}, false, err
+		}
+		return managedStamp{RecordedHash: marker.SHA256, BodyHash: bodyHash}, true, nil
+	}
+
+	_, pattern := textMarker(path)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		match := pattern.FindSubmatch(bytes.TrimRight(line, "\r\n"))
+		if match == nil {
+			continue
+		}
+		body := append(bytes.Join(lines[:i], nil), bytes.Join(lines[i+1:], nil)...)
+		return managedStamp{RecordedHash: string(match[1]), BodyHash: hashBytes(body)}, true, nil
+	}
+
+	return managedStamp{}, false, nil
+}
+
+// frontmatterEnd returns the offset just after a leading YAML frontmatter block,
+// so markers never break tools that require frontmatter on the first line.
+func frontmatterEnd(content []byte) int {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return 0
+	}
+	end := bytes.Index(content[4:], []byte("\n---\n"))
+	if end < 0 {
+		return 0
+	}
+	return 4 + end + len("\n---\n")
+}
+
+func canonicalJSONHash(doc interface{}) (string, error) {
+	// encoding/json sorts map keys, which makes the output canonical for hashing.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
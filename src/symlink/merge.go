@@ -0,0 +1,467 @@
+package symlink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// mcpServersKey is the object in MCP JSON files that holds server definitions.
+const mcpServersKey = "mcpServers"
+
+// mcpServersKeys are the server objects of the JSON formats mindful renders
+// (mcpServers, VS Code servers and Zed context_servers).
+var mcpServersKeys = []string{mcpServersKey, "servers", "context_servers"}
+
+// mergeMarker is the value older versions stored under jsonMarkerKey to record server
+// ownership. It is still read so that ownership carries over into the apply state, and
+// is dropped the next time the file is written.
+type mergeMarker struct {
+	Managed bool     `json:"managed,omitempty"` // Set by copy mode; every server was written by mindful
+	Servers []string `json:"servers,omitempty"` // Servers upserted by merge mode
+	Key     string   `json:"key,omitempty"`     // Object holding the servers when it is not mcpServers
+}
+
+// mcpDocument is a parsed MCP JSON file with its servers split out. The file may be
+// JSONC (Zed's settings.json has comments and trailing commas), so it is rewritten by
+// editing only the server members and the rest of the text is kept byte for byte.
+type mcpDocument struct {
+	src      string                     // Content the document was parsed from
+	root     *jsoncObject               // Top-level object, nil for an empty file
+	key      string                     // Top-level object that holds the servers
+	others   int                        // Top-level keys other than servers and the marker
+	comments bool                       // The file contains comments
+	parsed   map[string]json.RawMessage // Servers as found in the file
+	servers  map[string]json.RawMessage // Server name -> raw definition
+	owned    map[string]struct{}        // Servers mindful wrote on a previous apply
+}
+
+// parseMCPDocument splits an MCP JSON file. owned lists the servers the apply state
+// records for the file. An empty serversKey uses the key recorded in a legacy mindful
+// marker, then the first known server object present, then mcpServers.
+func parseMCPDocument(data []byte, serversKey string, owned []string) (*mcpDocument, error) {
+	doc := &mcpDocument{
+		src:     string(data),
+		key:     serversKey,
+		parsed:  make(map[string]json.RawMessage),
+		servers: make(map[string]json.RawMessage),
+		owned:   make(map[string]struct{}),
+	}
+	for _, name := range owned {
+		doc.owned[name] = struct{}{}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		if doc.key == "" {
+			doc.key = mcpServersKey
+		}
+		return doc, nil
+	}
+
+	plain, comments := stripJSONC(doc.src)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(plain), &fields); err != nil {
+		return nil, err
+	}
+	root, err := parseJSONC(doc.src)
+	if err != nil {
+		return nil, err
+	}
+	doc.root, doc.comments = root, comments
+
+	var marker mergeMarker
+	hasMarker := false
+	if raw, ok := fields[jsonMarkerKey]; ok {
+		hasMarker = json.Unmarshal(raw, &marker) == nil
+		delete(fields, jsonMarkerKey)
+	}
+
+	if doc.key == "" {
+		doc.key = marker.Key
+	}
+	if doc.key == "" {
+		for _, key := range mcpServersKeys {
+			if _, ok := fields[key]; ok {
+				doc.key = key
+				break
+			}
+		}
+	}
+	if doc.key == "" {
+		doc.key = mcpServersKey
+	}
+
+	if raw, ok := fields[doc.key]; ok {
+		if err := json.Unmarshal(raw, &doc.servers); err != nil || doc.servers == nil {
+			return nil, fmt.Errorf("%s must be an object", doc.key)
+		}
+		delete(fields, doc.key)
+	}
+	doc.others = len(fields)
+	for name, server := range doc.servers {
+		doc.parsed[name] = server
+	}
+
+	if hasMarker {
+		if marker.Managed {
+			for name := range doc.servers {
+				doc.owned[name] = struct{}{}
+			}
+		}
+		for _, name := range marker.Servers {
+			doc.owned[name] = struct{}{}
+		}
+	}
+
+	return doc, nil
+}
+
+// clashes lists desired servers that the file already defines differently and mindful
+// did not write. Identical definitions are adopted, so a lost apply state is harmless.
+func (d *mcpDocument) clashes(desired map[string]json.RawMessage) []string {
+	var names []string
+	for name, server := range desired {
+		existing, exists := d.servers[name]
+		if !exists || jsonEqual(existing, server) {
+			continue
+		}
+		if _, owned := d.owned[name]; !owned {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// upsert replaces previously owned servers with the desired set; mindful wins on name clashes.
+func (d *mcpDocument) upsert(desired map[string]json.RawMessage) {
+	for name := range d.owned {
+		delete(d.servers, name)
+	}
+	d.owned = make(map[string]struct{}, len(desired))
+	for name, server := range desired {
+		d.servers[name] = server
+		d.owned[name] = struct{}{}
+	}
+}
+
+// isEmpty reports whether nothing but mindful bookkeeping would remain in the file.
+func (d *mcpDocument) isEmpty() bool {
+	return d.others == 0 && len(d.servers) == 0 && !d.comments
+}
+
+// ownedNames returns the servers mindful owns in the document, sorted.
+func (d *mcpDocument) ownedNames() []string {
+	names := make([]string, 0, len(d.owned))
+	for name := range d.owned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matches reports whether the document already carries exactly the desired servers.
+func (d *mcpDocument) matches(desired map[string]json.RawMessage) bool {
+	if len(d.owned) != len(desired) {
+		return false
+	}
+	for name, server := range desired {
+		if _, ok := d.owned[name]; !ok {
+			return false
+		}
+		if !jsonEqual(d.servers[name], server) {
+			return false
+		}
+	}
+	return true
+}
+
+// render returns the file with the server changes applied. Only changed servers are
+// rewritten, and a legacy mindful marker is dropped; everything else is kept as is.
+func (d *mcpDocument) render() ([]byte, error) {
+	if d.root == nil {
+		data, err := json.MarshalIndent(map[string]interface{}{d.key: d.servers}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	servers := jsoncEdit{remove: make(map[string]bool), values: make(map[string]json.RawMessage)}
+	for name := range d.parsed {
+		if _, ok := d.servers[name]; !ok {
+			servers.remove[name] = true
+		}
+	}
+	for name, server := range d.servers {
+		if parsed, ok := d.parsed[name]; !ok || !jsonEqual(parsed, server) {
+			servers.values[name] = server
+		}
+	}
+
+	root := jsoncEdit{remove: map[string]bool{jsonMarkerKey: true}, text: make(map[string]string)}
+	if member, ok := d.root.member(d.key); ok {
+		object, _, err := parseJSONCObject(d.src, member.valueStart)
+		if err != nil {
+			return nil, err
+		}
+		text, err := object.rewrite(d.src, servers)
+		if err != nil {
+			return nil, err
+		}
+		root.text[d.key] = text
+	} else if len(d.servers) > 0 {
+		data, err := json.Marshal(d.servers)
+		if err != nil {
+			return nil, err
+		}
+		root.values = map[string]json.RawMessage{d.key: data}
+	}
+
+	text, err := d.root.rewrite(d.src, root)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(d.src[:d.root.open] + text + d.src[d.root.close+1:]), nil
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var left, right bytes.Buffer
+	if json.Compact(&left, a) != nil || json.Compact(&right, b) != nil {
+		return false
+	}
+	return bytes.Equal(left.Bytes(), right.Bytes())
+}
+
+// readMergeTarget loads the servers mindful wants to upsert and the object that holds them.
+func readMergeTarget(targetAbs string) (map[string]json.RawMessage, string, error) {
+	data, err := os.ReadFile(targetAbs)
+	if err != nil {
+		return nil, "", err
+	}
+	doc, err := parseMCPDocument(data, "", nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", targetAbs, err)
+	}
+	return doc.servers, doc.key, nil
+}
+
+// evaluateMerged classifies a JSON or TOML file that should contain mindful-managed servers.
+// project identifies the servers a shared TOML file holds for this project; owned lists
+// the servers the apply state records for a JSON file.
+func evaluateMerged(linkAbs, targetAbs, project string, owned []string) (string, error) {
+	if isTOMLPath(linkAbs) {
+		return evaluateMergedTOML(linkAbs, targetAbs, project)
+	}
+
+	content, exists, err := readUserFile(linkAbs)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return models.LinkStatusMissing, nil
+	}
+	if info, err := os.Lstat(linkAbs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return models.LinkStatusStale, nil
+	}
+
+	desired, key, err := readMergeTarget(targetAbs)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	targetMissing := err != nil
+
+	doc, err := parseMCPDocument([]byte(content), key, owned)
+	if err != nil {
+		// Refuse to touch a file we cannot parse without losing the user's content.
+		return models.LinkStatusConflict, nil
+	}
+
+	if targetMissing {
+		if len(doc.owned) > 0 {
+			return models.LinkStatusOK, nil
+		}
+		return models.LinkStatusMissing, nil
+	}
+
+	if doc.matches(desired) {
+		return models.LinkStatusOK, nil
+	}
+	return models.LinkStatusStale, nil
+}
+
+// writeMerged upserts mindful servers into linkAbs, preserving unrelated keys and user servers,
+// and returns the servers mindful now owns there for the apply state.
+// Servers the user defined under the same name are only replaced when force is set.
+func writeMerged(linkAbs, targetAbs, project string, owned []string, force bool) ([]string, error) {
+	data, servers, err := mergedContent(linkAbs, targetAbs, project, owned, force)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := artifactPerm(models.ArtifactMCP)
+	info, statErr := os.Lstat(linkAbs)
+	if statErr == nil && info.Mode()&os.ModeSymlink != 0 {
+		// Replace a link from a previous mode rather than writing through it.
+		if err := os.Remove(linkAbs); err != nil {
+			return nil, fmt.Errorf("failed to replace existing symlink %s: %w", linkAbs, err)
+		}
+	} else if statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := writeFileAtomic(linkAbs, data, perm); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// mergedContent returns what writeMerged would write to linkAbs and the servers mindful
+// would own there. TOML files record ownership themselves, so no servers are returned.
+func mergedContent(linkAbs, targetAbs, project string, owned []string, force bool) ([]byte, []string, error) {
+	if isTOMLPath(linkAbs) {
+		data, err := mergedTOMLContent(linkAbs, targetAbs, project, force)
+		return data, nil, err
+	}
+
+	desired, key, err := readMergeTarget(targetAbs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", targetAbs, err)
+	}
+
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := parseMCPDocument([]byte(content), key, owned)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot merge into %s: %w", linkAbs, err)
+	}
+	if clashes := doc.clashes(desired); len(clashes) > 0 && !force {
+		return nil, nil, mergeClashError(linkAbs, clashes)
+	}
+	doc.upsert(desired)
+
+	data, err := doc.render()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render %s: %w", linkAbs, err)
+	}
+	return data, doc.ownedNames(), nil
+}
+
+// mergeOwner identifies the project in files that several projects merge into.
+func (m *Manager) mergeOwner() string {
+	return mergeOwner(m.projectPath)
+}
+
+func mergeOwner(projectPath string) string {
+	if abs, err := filepath.Abs(projectPath); err == nil {
+		return abs
+	}
+	return filepath.Clean(projectPath)
+}
+
+// mergeClashError refuses to take over servers mindful did not write.
+func mergeClashError(linkAbs string, names []string) error {
+	return fmt.Errorf(
+		"refusing to merge into %s: MCP server(s) %s already exist there and were not written by mindful. "+
+			"Rename or remove them, or rerun mindful apply --force to replace them",
+		linkAbs, strings.Join(names, ", "),
+	)
+}
+
+// removeMerged prunes mindful-owned servers and deletes the file if nothing else remains.
+// owned lists the servers the apply state records for a JSON file.
+func removeMerged(linkAbs, project string, owned []string) error {
+	info, err := os.Lstat(linkAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s: %w", linkAbs, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+		}
+		return nil
+	}
+	if isTOMLPath(linkAbs) {
+		return removeMergedTOML(linkAbs, info, project)
+	}
+
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return err
+	}
+	doc, err := parseMCPDocument([]byte(content), "", owned)
+	if err != nil {
+		// Leave unparseable files alone.
+		return nil
+	}
+	if len(doc.owned) == 0 {
+		return nil
+	}
+
+	doc.upsert(nil)
+	if doc.isEmpty() {
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+		}
+		return nil
+	}
+
+	data, err := doc.render()
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", linkAbs, err)
+	}
+	return writeFileAtomic(linkAbs, data, info.Mode().Perm())
+}
+
+// MergePreview is the change apply would make to a file it merges servers into.
+type MergePreview struct {
+	Path   string // Link path as reported by PlanSymlinks
+	Before []byte // Current content, empty when the file does not exist
+	After  []byte // Content after the merge
+}
+
+// Diff renders the preview as a unified diff.
+func (p MergePreview) Diff() string {
+	return UnifiedDiff(p.Path, p.Before, p.After)
+}
+
+// PreviewMerges returns the pending changes to merged files for a tool without
+// writing anything.
+func (m *Manager) PreviewMerges(toolName string, opts ApplyOptions) ([]MergePreview, error) {
+	plans, err := m.plan(toolName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var previews []MergePreview
+	for _, plan := range plans {
+		if plan.mode != models.LinkModeMerge {
+			continue
+		}
+		if plan.info.Status != models.LinkStatusMissing && plan.info.Status != models.LinkStatusStale {
+			continue
+		}
+
+		before, _, err := readUserFile(plan.linkAbs)
+		if err != nil {
+			return nil, err
+		}
+		after, _, err := mergedContent(plan.linkAbs, plan.targetAbs, m.mergeOwner(), plan.servers, opts.Force)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, MergePreview{Path: plan.info.LinkPath, Before: []byte(before), After: after})
+	}
+	return previews, nil
+}
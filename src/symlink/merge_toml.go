@@ -0,0 +1,507 @@
+package symlink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mindful/src/models"
+)
+
+// tomlTable is one [header] section of a TOML file, with the comment lines that
+// introduce it. Content before the first header is a table without a header.
+type tomlTable struct {
+	lead   []string // Comment lines directly above the header
+	header string   // Raw header line, empty for the preamble
+	server string   // Server name for [mcp_servers.<name>] and its subtables
+	body   []string // Lines up to the next header
+	owner  string   // Project that merged the table, empty for the user's own tables
+}
+
+// tomlDocument is a TOML file split into tables, with the servers mindful owns.
+// The file may be shared by several projects (~/.codex/config.toml), so ownership
+// is recorded per project and each project only replaces its own tables.
+type tomlDocument struct {
+	tables []*tomlTable
+	owned  map[string]string // Server name -> project that merged it
+}
+
+// isTOMLPath reports whether a merged file is TOML rather than JSON.
+func isTOMLPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+// parseTOMLDocument splits TOML content into tables without interpreting values, so
+// comments, formatting and settings mindful does not manage survive a rewrite.
+func parseTOMLDocument(content string) (*tomlDocument, error) {
+	doc := &tomlDocument{owned: make(map[string]string)}
+	current := &tomlTable{}
+	doc.tables = append(doc.tables, current)
+	if content == "" {
+		return doc, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	inMultiline := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if inMultiline == "" {
+			if match := tomlMergeMarkerPattern.FindStringSubmatch(trimmed); match != nil {
+				var names []string
+				var project string
+				if err := json.Unmarshal([]byte(match[1]), &names); err != nil {
+					return nil, fmt.Errorf("invalid mindful marker %q: %w", trimmed, err)
+				}
+				if err := json.Unmarshal([]byte(match[2]), &project); err != nil {
+					return nil, fmt.Errorf("invalid mindful marker %q: %w", trimmed, err)
+				}
+				for _, name := range names {
+					doc.owned[name] = project
+				}
+				continue
+			}
+
+			if strings.HasPrefix(trimmed, "[") {
+				path, err := parseTOMLHeader(trimmed)
+				if err != nil {
+					return nil, err
+				}
+				next := &tomlTable{header: line}
+				if len(path) >= 2 && path[0] == models.MCPServersKey(models.MCPFormatCodex) && !strings.HasPrefix(trimmed, "[[") {
+					next.server = path[1]
+				}
+				// Comments right above a header describe the table that follows.
+				current.body, next.lead = splitTrailingComments(current.body)
+				current = next
+				doc.tables = append(doc.tables, current)
+				continue
+			}
+		}
+
+		inMultiline = trackMultiline(line, inMultiline)
+		current.body = append(current.body, line)
+	}
+
+	for _, table := range doc.tables {
+		if owner, ok := doc.owned[table.server]; ok && table.server != "" {
+			table.owner = owner
+			table.body = trimTrailingBlank(table.body)
+		}
+	}
+	return doc, nil
+}
+
+// trackMultiline returns the multi-line string delimiter still open after line.
+func trackMultiline(line, open string) string {
+	for _, delim := range []string{`"""`, `'''`} {
+		if open != "" && open != delim {
+			continue
+		}
+		if strings.Count(line, delim)%2 == 1 {
+			if open == "" {
+				return delim
+			}
+			return ""
+		}
+	}
+	return open
+}
+
+// splitTrailingComments moves the comment block at the end of lines (blank lines
+// before it stay put) into a separate slice.
+func splitTrailingComments(lines []string) ([]string, []string) {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		start = i
+	}
+	if start == len(lines) {
+		return lines, nil
+	}
+	return lines[:start], append([]string{}, lines[start:]...)
+}
+
+// parseTOMLHeader returns the dotted key path of a [table] or [[array]] header.
+func parseTOMLHeader(line string) ([]string, error) {
+	inner := line
+	open, close := "[", "]"
+	if strings.HasPrefix(inner, "[[") {
+		open, close = "[[", "]]"
+	}
+	inner = strings.TrimPrefix(inner, open)
+	end := tomlHeaderEnd(inner, close)
+	if end < 0 {
+		return nil, fmt.Errorf("malformed TOML table header %q", line)
+	}
+	rest := strings.TrimSpace(inner[end+len(close):])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, fmt.Errorf("malformed TOML table header %q", line)
+	}
+
+	var path []string
+	key := strings.TrimSpace(inner[:end])
+	for key != "" {
+		var part string
+		switch key[0] {
+		case '"':
+			closing := closingQuote(key, '"')
+			if closing < 0 {
+				return nil, fmt.Errorf("malformed TOML table header %q", line)
+			}
+			if err := json.Unmarshal([]byte(key[:closing+1]), &part); err != nil {
+				return nil, fmt.Errorf("malformed TOML table header %q: %w", line, err)
+			}
+			key = key[closing+1:]
+		case '\'':
+			closing := strings.IndexByte(key[1:], '\'')
+			if closing < 0 {
+				return nil, fmt.Errorf("malformed TOML table header %q", line)
+			}
+			part = key[1 : closing+1]
+			key = key[closing+2:]
+		default:
+			dot := strings.IndexByte(key, '.')
+			if dot < 0 {
+				dot = len(key)
+			}
+			part = strings.TrimSpace(key[:dot])
+			key = key[dot:]
+		}
+		path = append(path, part)
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			break
+		}
+		if key[0] != '.' {
+			return nil, fmt.Errorf("malformed TOML table header %q", line)
+		}
+		key = strings.TrimSpace(key[1:])
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("malformed TOML table header %q", line)
+	}
+	return path, nil
+}
+
+// tomlHeaderEnd finds the closing bracket of a header, skipping quoted keys.
+func tomlHeaderEnd(inner, close string) int {
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '"':
+			closing := closingQuote(inner[i:], '"')
+			if closing < 0 {
+				return -1
+			}
+			i += closing
+		case '\'':
+			closing := strings.IndexByte(inner[i+1:], '\'')
+			if closing < 0 {
+				return -1
+			}
+			i += closing + 1
+		default:
+			if strings.HasPrefix(inner[i:], close) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// closingQuote returns the index of the quote ending the basic string at s[0].
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// servers returns the text of every server table, keyed by server name.
+func (d *tomlDocument) servers() map[string]string {
+	servers := make(map[string]string)
+	for _, table := range d.tables {
+		if table.server == "" {
+			continue
+		}
+		lines := append([]string{table.header}, trimTrailingBlank(table.body)...)
+		servers[table.server] += strings.Join(lines, "\n") + "\n"
+	}
+	return servers
+}
+
+// ownedBy lists the servers project merged into the file.
+func (d *tomlDocument) ownedBy(project string) []string {
+	var names []string
+	for name, owner := range d.owned {
+		if owner == project {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clashes describes desired servers that the file already defines but project did
+// not merge: the user's own tables and tables merged by other projects.
+func (d *tomlDocument) clashes(desired *tomlDocument, project string) []string {
+	existing := d.servers()
+	seen := make(map[string]struct{})
+	var clashes []string
+	for _, table := range desired.tables {
+		if _, ok := existing[table.server]; !ok || table.server == "" {
+			continue
+		}
+		if _, ok := seen[table.server]; ok {
+			continue
+		}
+		seen[table.server] = struct{}{}
+		switch owner, owned := d.owned[table.server]; {
+		case !owned:
+			clashes = append(clashes, table.server)
+		case owner != project:
+			clashes = append(clashes, fmt.Sprintf("%s (merged by %s)", table.server, owner))
+		}
+	}
+	sort.Strings(clashes)
+	return clashes
+}
+
+// upsert replaces the servers project merged before with the desired tables, which
+// also replace any other table of the same name. They take the place of the first
+// table removed, or go last. Tables of other projects are left alone.
+func (d *tomlDocument) upsert(desired *tomlDocument, project string) {
+	d.replace(desired, project, func(owner string) bool { return owner == project })
+}
+
+// strip removes every table mindful merged, whichever project it belongs to.
+func (d *tomlDocument) strip() {
+	d.replace(nil, "", func(string) bool { return true })
+}
+
+func (d *tomlDocument) replace(desired *tomlDocument, project string, drop func(owner string) bool) {
+	var incoming []*tomlTable
+	names := make(map[string]struct{})
+	if desired != nil {
+		for _, table := range desired.tables {
+			if table.server == "" {
+				continue
+			}
+			table.body = trimTrailingBlank(table.body)
+			table.lead = nil
+			table.owner = project
+			incoming = append(incoming, table)
+			names[table.server] = struct{}{}
+		}
+	}
+
+	tables := make([]*tomlTable, 0, len(d.tables)+len(incoming))
+	insertAt := -1
+	for _, table := range d.tables {
+		owner, owned := d.owned[table.server]
+		_, replaced := names[table.server]
+		if table.server == "" || (!(owned && drop(owner)) && !replaced) {
+			tables = append(tables, table)
+			continue
+		}
+		if insertAt < 0 {
+			insertAt = len(tables)
+		}
+		if len(table.lead) > 0 {
+			// Keep the user's comments even though the table they introduced is gone.
+			tables = append(tables, &tomlTable{lead: table.lead})
+		}
+	}
+	if insertAt < 0 {
+		insertAt = len(tables)
+	}
+	d.tables = append(tables[:insertAt], append(incoming, tables[insertAt:]...)...)
+
+	for name, owner := range d.owned {
+		if drop(owner) {
+			delete(d.owned, name)
+		}
+	}
+	for name := range names {
+		d.owned[name] = project
+	}
+}
+
+// isEmpty reports whether the file holds nothing but blank lines.
+func (d *tomlDocument) isEmpty() bool {
+	for _, table := range d.tables {
+		if table.header != "" || len(table.lead) > 0 || len(trimTrailingBlank(table.body)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the document already carries exactly the desired servers
+// for project.
+func (d *tomlDocument) matches(desired *tomlDocument, project string) bool {
+	want := desired.servers()
+	if len(d.ownedBy(project)) != len(want) {
+		return false
+	}
+	have := d.servers()
+	for name, text := range want {
+		if d.owned[name] != project || have[name] != text {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *tomlDocument) render() []byte {
+	names := make(map[string][]string)
+	for name, owner := range d.owned {
+		names[owner] = append(names[owner], name)
+	}
+
+	var out []string
+	marked := make(map[string]bool)
+	for i, table := range d.tables {
+		managed := table.owner != ""
+		if managed && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		if managed && !marked[table.owner] {
+			sort.Strings(names[table.owner])
+			encodedNames, _ := json.Marshal(names[table.owner])
+			encodedProject, _ := json.Marshal(table.owner)
+			out = append(out, fmt.Sprintf(tomlMergeMarkerFormat, encodedNames, encodedProject))
+			marked[table.owner] = true
+		}
+		out = append(out, table.lead...)
+		if table.header != "" {
+			out = append(out, table.header)
+		}
+		out = append(out, table.body...)
+		if managed && i+1 < len(d.tables) {
+			out = append(out, "")
+		}
+	}
+
+	out = trimTrailingBlank(out)
+	if len(out) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+func trimTrailingBlank(lines []string) []string {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[:end]
+}
+
+// readTOMLMergeTarget loads the server tables mindful wants to upsert.
+func readTOMLMergeTarget(targetAbs string) (*tomlDocument, error) {
+	data, err := os.ReadFile(targetAbs)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseTOMLDocument(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", targetAbs, err)
+	}
+	return doc, nil
+}
+
+// evaluateMergedTOML classifies a TOML file that should contain mindful-managed servers.
+func evaluateMergedTOML(linkAbs, targetAbs, project string) (string, error) {
+	content, exists, err := readUserFile(linkAbs)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return models.LinkStatusMissing, nil
+	}
+	if info, err := os.Lstat(linkAbs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return models.LinkStatusStale, nil
+	}
+
+	doc, err := parseTOMLDocument(content)
+	if err != nil {
+		return models.LinkStatusConflict, nil
+	}
+
+	desired, err := readTOMLMergeTarget(targetAbs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if len(doc.ownedBy(project)) > 0 {
+				return models.LinkStatusOK, nil
+			}
+			return models.LinkStatusMissing, nil
+		}
+		return "", err
+	}
+
+	if doc.matches(desired, project) {
+		return models.LinkStatusOK, nil
+	}
+	return models.LinkStatusStale, nil
+}
+
+// mergedTOMLContent returns linkAbs with the servers of targetAbs upserted for project.
+func mergedTOMLContent(linkAbs, targetAbs, project string, force bool) ([]byte, error) {
+	desired, err := readTOMLMergeTarget(targetAbs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", targetAbs, err)
+	}
+
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseTOMLDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge into %s: %w", linkAbs, err)
+	}
+	if clashes := doc.clashes(desired, project); len(clashes) > 0 && !force {
+		return nil, mergeClashError(linkAbs, clashes)
+	}
+	doc.upsert(desired, project)
+	return doc.render(), nil
+}
+
+// removeMergedTOML prunes the tables project merged and deletes the file if nothing
+// else remains.
+func removeMergedTOML(linkAbs string, info os.FileInfo, project string) error {
+	content, _, err := readUserFile(linkAbs)
+	if err != nil {
+		return err
+	}
+	doc, err := parseTOMLDocument(content)
+	if err != nil || len(doc.ownedBy(project)) == 0 {
+		// Leave unparseable files, and files this project did not merge into, alone.
+		return nil
+	}
+
+	doc.upsert(nil, project)
+	if doc.isEmpty() {
+		if err := os.Remove(linkAbs); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", linkAbs, err)
+		}
+		return nil
+	}
+	return writeFileAtomic(linkAbs, doc.render(), info.Mode().Perm())
+}
@@ -0,0 +1,141 @@
+package symlink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FindOrphans lists managed subagent links that no longer correspond to a built artefact.
+// Only dangling symlinks into mindful/out and unmodified managed copies are reported.
+func (m *Manager) FindOrphans(toolName string) ([]string, error) {
+	orphans, err := m.findOrphans(toolName)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(orphans))
+	for _, path := range orphans {
+		paths = append(paths, m.resolver.RelativeToProject(path))
+	}
+	return paths, nil
+}
+
+// PruneOrphans removes the paths reported by FindOrphans and returns them.
+func (m *Manager) PruneOrphans(toolName string) ([]string, error) {
+	orphans, err := m.findOrphans(toolName)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var errs []error
+	for _, path := range orphans {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove orphan %s: %w", path, err))
+			continue
+		}
+		removed = append(removed, m.resolver.RelativeToProject(path))
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+func (m *Manager) findOrphans(toolName string) ([]string, error) {
+	toolConfig, ok := m.config.ToolConfig(toolName)
+	if !ok || toolConfig == nil {
+		return nil, fmt.Errorf("%w for tool %q", ErrToolNotConfigured, toolName)
+	}
+
+	template := strings.TrimSpace(toolConfig.Subagents)
+	if template == "" {
+		return nil, nil
+	}
+
+	plans, err := m.plan(toolName, false)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]struct{}, len(plans))
+	for _, plan := range plans {
+		current[filepath.Clean(plan.linkAbs)] = struct{}{}
+	}
+
+	pattern, _ := m.resolver.ResolveLink(strings.ReplaceAll(template, SubagentPlaceholder, "*"))
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subagent template %q: %w", template, err)
+	}
+
+	var orphans []string
+	for _, match := range matches {
+		if _, ok := current[filepath.Clean(match)]; ok {
+			continue
+		}
+
+		orphan, err := m.isOrphan(match)
+		if err != nil {
+			return nil, err
+		}
+		if orphan {
+			orphans = append(orphans, match)
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// isOrphan reports whether path is a leftover mindful link or copy that is safe to delete.
+func (m *Manager) isOrphan(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		dest, err := os.Readlink(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		if !filepath.IsAbs(dest) {
+			dest = filepath.Join(filepath.Dir(path), dest)
+		}
+		if !isWithin(m.outPath, dest) {
+			return false, nil
+		}
+		if _, err := os.Stat(dest); err == nil {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	stamp, managed, err := readStamp(path, data)
+	if err != nil || !managed {
+		return false, err
+	}
+	return !stamp.Modified(), nil
+}
+
+// isWithin reports whether path is located inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -1,7 +1,9 @@
 package symlink
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
 	"mindful/src/models"
 )
@@ -38,23 +40,40 @@ func (r *Resolver) OutDir() string {
 	return r.outDir
 }
 
-// SubagentDir returns mindful/out/subagents.
-func (r *Resolver) SubagentDir() string {
-	return filepath.Join(r.outDir, "subagents")
+// SubagentDir returns mindful/out/<tool>/subagents, the subagents rendered for the tool.
+func (r *Resolver) SubagentDir(toolName string) string {
+	return filepath.Join(r.outDir, toolName, "subagents")
 }
 
-// MemoryArtifact returns mindful/out/memory.md.
-func (r *Resolver) MemoryArtifact() string {
-	return filepath.Join(r.outDir, "memory.md")
+// MemoryArtifact returns mindful/out/<tool>/memory.md, the memory rendered for the tool.
+func (r *Resolver) MemoryArtifact(toolName string) string {
+	return filepath.Join(r.outDir, toolName, "memory.md")
 }
 
-// MCPArtifact returns mindful/out/mcp.json.
-func (r *Resolver) MCPArtifact() string {
-	return filepath.Join(r.outDir, "mcp.json")
+// MCPArtifact returns mindful/out/<tool>/<file>, the tool's MCP file in its format.
+func (r *Resolver) MCPArtifact(toolName, format string) string {
+	return filepath.Join(r.outDir, toolName, models.MCPFormatFileName(format))
+}
+
+// StatePath returns mindful/.state.json.
+func (r *Resolver) StatePath() string {
+	return filepath.Join(r.mindfulDir, models.DefaultStateFileName)
+}
+
+// BackupDir returns mindful/backup.
+func (r *Resolver) BackupDir() string {
+	return filepath.Join(r.mindfulDir, models.DefaultBackupDirName)
 }
 
 // ResolveLink resolves a configured link path to both absolute and project-relative forms.
+// Paths starting with ~/ are taken from the home directory and reported as absolute.
 func (r *Resolver) ResolveLink(linkPath string) (string, string) {
+	if rest, ok := strings.CutPrefix(filepath.ToSlash(linkPath), "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			abs := filepath.Join(home, filepath.FromSlash(rest))
+			return abs, abs
+		}
+	}
 	if filepath.IsAbs(linkPath) {
 		abs := filepath.Clean(linkPath)
 		return abs, r.RelativeToProject(abs)
@@ -0,0 +1,277 @@
+package symlink
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mindful/src/models"
+)
+
+// LoadState reads mindful/.state.json, returning an empty state when none exists yet.
+func (m *Manager) LoadState() (*models.ApplyState, error) {
+	return loadState(m.resolver.StatePath())
+}
+
+func loadState(path string) (*models.ApplyState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return models.NewApplyState(), nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	state := models.NewApplyState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if state.Tools == nil {
+		state.Tools = make(map[string]*models.ToolState)
+	}
+	return state, nil
+}
+
+// SaveState writes mindful/.state.json, removing it once no tool has managed paths.
+func (m *Manager) SaveState(state *models.ApplyState) error {
+	path := m.resolver.StatePath()
+	if state == nil || len(state.Tools) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode apply state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// MergedServers returns the MCP servers the apply state of projectPath records as
+// merged into the JSON file at path.
+func MergedServers(projectPath, path string) ([]string, error) {
+	resolver := NewResolver(projectPath)
+	state, err := loadState(resolver.StatePath())
+	if err != nil {
+		return nil, err
+	}
+	return mergedServers(resolver, state, path), nil
+}
+
+// mergedServers looks up the servers recorded for a merged file across every tool.
+func mergedServers(resolver *Resolver, state *models.ApplyState, path string) []string {
+	path = filepath.Clean(path)
+	for _, toolState := range state.Tools {
+		for _, entry := range toolState.Entries {
+			if linkAbs, _ := resolver.ResolveLink(entry.Path); filepath.Clean(linkAbs) == path {
+				if len(entry.Servers) > 0 {
+					return entry.Servers
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RecordedTools lists the tools that have paths recorded in the apply state.
+func (m *Manager) RecordedTools() ([]string, error) {
+	state, err := m.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	return state.ToolNames(), nil
+}
+
+// ForgetTool removes every path recorded for a tool and drops it from the apply state.
+// It is used when a tool is disabled or its mapping disappears.
+func (m *Manager) ForgetTool(toolName string) error {
+	state, err := m.LoadState()
+	if err != nil {
+		return err
+	}
+
+	toolState, ok := state.Tools[toolName]
+	if !ok {
+		return nil
+	}
+
+	if err := m.removeRecorded(toolState.Entries, nil); err != nil {
+		return err
+	}
+
+	delete(state.Tools, toolName)
+	return m.SaveState(state)
+}
+
+// recordApply replaces the recorded entries for a tool and removes paths that are no longer planned.
+func (m *Manager) recordApply(toolName string, plans []*plannedLink) error {
+	state, err := m.LoadState()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]struct{}, len(plans))
+	entries := make([]models.StateEntry, 0, len(plans))
+	for _, plan := range plans {
+		current[filepath.Clean(plan.linkAbs)] = struct{}{}
+		entries = append(entries, models.StateEntry{
+			Kind:    plan.kind,
+			Path:    plan.info.LinkPath,
+			Target:  plan.info.TargetPath,
+			Mode:    plan.mode,
+			Servers: plan.servers,
+		})
+	}
+
+	var removeErr error
+	if previous, ok := state.Tools[toolName]; ok {
+		removeErr = m.removeRecorded(previous.Entries, current)
+	}
+
+	buildHash, err := m.buildHash()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	state.BuildHash = buildHash
+	state.AppliedAt = now
+	state.Tools[toolName] = &models.ToolState{
+		BuildHash: buildHash,
+		AppliedAt: now,
+		Entries:   entries,
+	}
+
+	if err := m.SaveState(state); err != nil {
+		return err
+	}
+	return removeErr
+}
+
+// recordedOnly returns the recorded entries for a tool that are absent from plans.
+func (m *Manager) recordedOnly(toolName string, plans []*plannedLink) ([]*plannedLink, error) {
+	state, err := m.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	toolState, ok := state.Tools[toolName]
+	if !ok {
+		return nil, nil
+	}
+
+	current := make(map[string]struct{}, len(plans))
+	for _, plan := range plans {
+		current[filepath.Clean(plan.linkAbs)] = struct{}{}
+	}
+
+	var recorded []*plannedLink
+	for _, entry := range toolState.Entries {
+		plan := m.planFromEntry(entry)
+		if _, ok := current[filepath.Clean(plan.linkAbs)]; ok {
+			continue
+		}
+		recorded = append(recorded, plan)
+	}
+	return recorded, nil
+}
+
+// removeRecorded removes recorded entries whose paths are not in keep.
+func (m *Manager) removeRecorded(entries []models.StateEntry, keep map[string]struct{}) error {
+	var errs []error
+	for _, entry := range entries {
+		plan := m.planFromEntry(entry)
+		if _, ok := keep[filepath.Clean(plan.linkAbs)]; ok {
+			continue
+		}
+		if plan.mode == models.LinkModeSymlink && !m.pointsIntoOut(plan.linkAbs) {
+			// The path was replaced by something mindful did not create.
+			continue
+		}
+		if err := m.removeLink(plan); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) planFromEntry(entry models.StateEntry) *plannedLink {
+	linkAbs, linkRel := m.resolver.ResolveLink(entry.Path)
+	targetAbs := m.resolver.ResolveTarget(entry.Target)
+	return &plannedLink{
+		info: models.SymlinkInfo{
+			LinkPath:   linkRel,
+			TargetPath: m.resolver.RelativeToProject(targetAbs),
+			Mode:       entry.Mode,
+			Kind:       entry.Kind,
+			Status:     models.LinkStatusOrphaned,
+		},
+		linkAbs:   linkAbs,
+		targetAbs: targetAbs,
+		mode:      entry.Mode,
+		kind:      entry.Kind,
+		servers:   entry.Servers,
+	}
+}
+
+func (m *Manager) pointsIntoOut(linkAbs string) bool {
+	dest, err := os.Readlink(linkAbs)
+	if err != nil {
+		return false
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(linkAbs), dest)
+	}
+	return isWithin(m.outPath, dest)
+}
+
+// buildHash fingerprints the files currently in mindful/out.
+func (m *Manager) buildHash() (string, error) {
+	var files []string
+	err := filepath.WalkDir(m.outPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to scan %s: %w", m.outPath, err)
+	}
+	sort.Strings(files)
+
+	hasher := sha256.New()
+	for _, path := range files {
+		rel, _ := filepath.Rel(m.outPath, path)
+		io.WriteString(hasher, filepath.ToSlash(rel))
+		hasher.Write([]byte{0})
+
+		file, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		_, err = io.Copy(hasher, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		hasher.Write([]byte{0})
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
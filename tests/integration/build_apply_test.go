@@ -78,21 +78,27 @@ func TestBuildAndApplyPipeline(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadArtifacts: %v", err)
 	}
+	if err := ctx.RenderToolOutputs(artifacts); err != nil {
+		t.Fatalf("RenderToolOutputs: %v", err)
+	}
 	if err := ctx.WriteArtifacts(artifacts); err != nil {
 		t.Fatalf("WriteArtifacts: %v", err)
 	}
 
 	// Provide a dummy MCP configuration so the default mapping can create links.
-	mcpPath := filepath.Join(projectDir, "mindful", "out", "mcp.json")
+	mcpPath := filepath.Join(projectDir, "mindful", "out", "claude", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(mcpPath), 0o755); err != nil {
+		t.Fatalf("create mcp dir: %v", err)
+	}
 	if err := os.WriteFile(mcpPath, []byte("{}"), 0o644); err != nil {
 		t.Fatalf("write mcp.json: %v", err)
 	}
 
 	// Ensure artefacts were written
-	if _, err := os.Stat(filepath.Join(projectDir, "mindful", "out", "memory.md")); err != nil {
+	if _, err := os.Stat(filepath.Join(projectDir, "mindful", "out", "claude", "memory.md")); err != nil {
 		t.Fatalf("memory artifact missing: %v", err)
 	}
-	if _, err := os.Stat(filepath.Join(projectDir, "mindful", "out", "subagents", "researcher.mdc")); err != nil {
+	if _, err := os.Stat(filepath.Join(projectDir, "mindful", "out", "claude", "subagents", "researcher.mdc")); err != nil {
 		t.Fatalf("subagent artifact missing: %v", err)
 	}
 
@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mindful/src/models"
+)
+
+func TestBuildRendersOnlyEnabledTools(t *testing.T) {
+	projectDir := newCLIProject(t)
+	outDir := filepath.Join(projectDir, models.DefaultMindfulDirName, "out")
+	writeTeamFile(t, filepath.Join(filepath.Dir(projectDir), "team"), "memory.md", "Team rules\n")
+	if _, stderr, err := runMindful(t, projectDir, "mcp", "add", "docs", "--command", "npx"); err != nil {
+		t.Fatalf("mcp add: %v\n%s", err, stderr)
+	}
+
+	// Output left behind by a tool that has since been disabled.
+	staleDir := filepath.Join(outDir, "cursor")
+	if err := os.MkdirAll(staleDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(staleDir, "mcp.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write stale output: %v", err)
+	}
+
+	_, stderr, err := runMindful(t, projectDir, "build")
+	if err != nil {
+		t.Fatalf("build: %v\n%s", err, stderr)
+	}
+	if strings.Contains(stderr, "cursor") || strings.Contains(stderr, "codex") {
+		t.Errorf("expected no warnings about disabled tools, got:\n%s", stderr)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatalf("read %s: %v", outDir, err)
+	}
+	var tools []string
+	for _, entry := range entries {
+		tools = append(tools, entry.Name())
+	}
+	if len(tools) != 1 || tools[0] != "claude" {
+		t.Fatalf("expected only claude output in %s, got %v", outDir, tools)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "claude", "memory.md")); err != nil {
+		t.Fatalf("expected claude memory to be built: %v", err)
+	}
+}
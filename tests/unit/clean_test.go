@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"mindful/src/models"
+	"mindful/src/symlink"
+)
+
+func TestCleanRemovesLinksOfUnmappedTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation on Windows requires special privileges")
+	}
+
+	projectDir := newCLIProject(t)
+	outDir := filepath.Join(projectDir, "mindful", "out", "gemini")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "memory.md"), []byte("memory"), 0o644); err != nil {
+		t.Fatalf("write memory: %v", err)
+	}
+
+	// Link GEMINI.md with a mapping mindful.yaml does not have, so only the state
+	// file remembers the tool.
+	manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"gemini": {Memory: "GEMINI.md"},
+	}))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("gemini"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	unmapped, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(nil))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if _, err := unmapped.PruneOrphans("gemini"); !errors.Is(err, symlink.ErrToolNotConfigured) {
+		t.Fatalf("expected ErrToolNotConfigured, got %v", err)
+	}
+
+	stdout, stderr, err := runMindful(t, projectDir, "clean")
+	if err != nil {
+		t.Fatalf("clean: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "✓ gemini cleaned") {
+		t.Errorf("expected gemini to be cleaned, got:\n%s", stdout)
+	}
+	if _, err := os.Lstat(filepath.Join(projectDir, "GEMINI.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected GEMINI.md to be removed, err=%v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mindful/src/cli"
+	"mindful/src/config"
+	"mindful/src/models"
+	"mindful/src/storage"
+)
+
+// newCLIProject creates a project with mindful.yaml under an isolated home and
+// cache, so commands never see the developer's own ~/.mindful. The default key
+// file is generated, as mindful secrets init-key does.
+func newCLIProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tempDir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, "cache"))
+	t.Setenv(models.UserDirEnv, "")
+
+	teamDir := filepath.Join(tempDir, "team")
+	projectDir := filepath.Join(tempDir, "project")
+	for _, dir := range []string{teamDir, filepath.Join(projectDir, models.DefaultMindfulDirName)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	cfg := &models.ProjectConfig{
+		Name:               "demo",
+		Version:            "1.0.0",
+		Source:             teamDir,
+		EnableCodingAgents: []string{"claude"},
+	}
+	if err := config.NewManager().SaveProject(projectDir, cfg); err != nil {
+		t.Fatalf("save project config: %v", err)
+	}
+	if err := storage.GenerateKeyFile(storage.DefaultKeyFile()); err != nil {
+		t.Fatalf("generate key file: %v", err)
+	}
+	return projectDir
+}
+
+// runMindful runs the CLI against projectDir and returns its output.
+func runMindful(t *testing.T, projectDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer os.Chdir(cwd)
+
+	var stdout, stderr bytes.Buffer
+	cmd := cli.NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SilenceUsage = true
+	cmd.SetArgs(append([]string{"--project", projectDir}, args...))
+	err = cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
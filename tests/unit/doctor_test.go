@@ -0,0 +1,222 @@
+package unit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"mindful/src/config"
+	"mindful/src/models"
+)
+
+// newDoctorProject builds and applies a project whose team source has memory and a
+// reviewer subagent. MCP is left unmapped because the team has no mindful.db.
+func newDoctorProject(t *testing.T) (projectDir, teamDir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation on Windows requires special privileges")
+	}
+
+	projectDir = newCLIProject(t)
+	teamDir = filepath.Join(filepath.Dir(projectDir), "team")
+
+	manager := config.NewManager()
+	cfg, err := manager.LoadProject(projectDir)
+	if err != nil {
+		t.Fatalf("load project config: %v", err)
+	}
+	noMCP := ""
+	cfg.Symlinks = map[string]*models.ToolSymlinkOverride{"claude": {MCP: &noMCP}}
+	if err := manager.SaveProject(projectDir, cfg); err != nil {
+		t.Fatalf("save project config: %v", err)
+	}
+
+	writeTeamFile(t, teamDir, "memory.md", "Team rules\n")
+	writeTeamFile(t, teamDir, filepath.Join("subagents", "reviewer.md"), "You review code.\n")
+	for _, args := range [][]string{{"build"}, {"apply"}} {
+		if _, stderr, err := runMindful(t, projectDir, args...); err != nil {
+			t.Fatalf("%s: %v\n%s", args[0], err, stderr)
+		}
+	}
+	return projectDir, teamDir
+}
+
+func writeTeamFile(t *testing.T, teamDir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(teamDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+}
+
+func assertContains(t *testing.T, output string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestDoctorReportsHealthyProject(t *testing.T) {
+	projectDir, _ := newDoctorProject(t)
+
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	if err != nil {
+		t.Fatalf("doctor: %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "✓ mindful/out is up to date", "✓ claude links are healthy", "No problems found.")
+}
+
+func TestDoctorRelinksBrokenSymlink(t *testing.T) {
+	projectDir, _ := newDoctorProject(t)
+	claudePath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.Remove(claudePath); err != nil {
+		t.Fatalf("remove CLAUDE.md: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(projectDir, "missing.md"), claudePath); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	if err == nil || err.Error() != "1 problem(s) need attention" {
+		t.Fatalf("expected one problem, got %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "✗ claude: CLAUDE.md is a broken symlink", "Run mindful doctor --fix")
+
+	stdout, _, err = runMindful(t, projectDir, "doctor", "--fix")
+	if err != nil {
+		t.Fatalf("doctor --fix: %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "fixed: relinked CLAUDE.md", "No problems found.")
+	if data, err := os.ReadFile(claudePath); err != nil || !strings.Contains(string(data), "Team rules") {
+		t.Fatalf("expected CLAUDE.md to link to the built memory, got %q (err=%v)", data, err)
+	}
+}
+
+func TestDoctorRefusesToReplaceBlockingFile(t *testing.T) {
+	projectDir, _ := newDoctorProject(t)
+	claudePath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.Remove(claudePath); err != nil {
+		t.Fatalf("remove CLAUDE.md: %v", err)
+	}
+	if err := os.WriteFile(claudePath, []byte("my own notes\n"), 0o644); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	// Without --fix nothing is attempted, so nothing is reported as "not touched".
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	if err == nil || err.Error() != "1 problem(s) need attention" {
+		t.Fatalf("expected the blocking file to be a problem, got %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout,
+		"✗ claude: CLAUDE.md is a regular file blocking the link\n  CLAUDE.md is a regular file blocking the link (move it away, or use mindful apply --force)",
+		"Run mindful doctor --fix",
+	)
+	if strings.Contains(stdout, "Not touched") {
+		t.Errorf("expected no \"Not touched\" summary without --fix, got:\n%s", stdout)
+	}
+
+	stdout, _, err = runMindful(t, projectDir, "doctor", "--fix")
+	if err == nil || err.Error() != "1 problem(s) need attention" {
+		t.Fatalf("expected the blocking file to stay a problem, got %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout,
+		"✗ claude: CLAUDE.md is a regular file blocking the link",
+		"Not touched (needs manual attention):",
+		"  - CLAUDE.md is a regular file blocking the link (move it away, or use mindful apply --force)",
+	)
+	if strings.Contains(stdout, "fixed:") || strings.Contains(stdout, "Run mindful doctor --fix") {
+		t.Errorf("expected nothing to be fixed and no --fix hint under --fix, got:\n%s", stdout)
+	}
+	if data, _ := os.ReadFile(claudePath); string(data) != "my own notes\n" {
+		t.Fatalf("expected the user's file to be left alone, got %q", data)
+	}
+}
+
+func TestDoctorRebuildsStaleOutput(t *testing.T) {
+	projectDir, teamDir := newDoctorProject(t)
+	writeTeamFile(t, teamDir, "memory.md", "New team rules\n")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(teamDir, "memory.md"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	if err == nil {
+		t.Fatalf("expected stale output to be a problem:\n%s", stdout)
+	}
+	assertContains(t, stdout, "✗ mindful/out is older than "+filepath.Join(teamDir, "memory.md"))
+
+	stdout, _, err = runMindful(t, projectDir, "doctor", "--fix")
+	if err != nil {
+		t.Fatalf("doctor --fix: %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "fixed: rebuilt mindful/out", "No problems found.")
+	data, err := os.ReadFile(filepath.Join(projectDir, "mindful", "out", "claude", "memory.md"))
+	if err != nil || !strings.Contains(string(data), "New team rules") {
+		t.Fatalf("expected the rebuilt memory, got %q (err=%v)", data, err)
+	}
+}
+
+func TestDoctorPrunesOrphanedSubagentLinks(t *testing.T) {
+	projectDir, teamDir := newDoctorProject(t)
+	linkPath := filepath.Join(".claude", "agents", "reviewer.mindful.md")
+	if err := os.Remove(filepath.Join(teamDir, "subagents", "reviewer.md")); err != nil {
+		t.Fatalf("remove subagent: %v", err)
+	}
+	if _, stderr, err := runMindful(t, projectDir, "build"); err != nil {
+		t.Fatalf("build: %v\n%s", err, stderr)
+	}
+
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	if err == nil || err.Error() != "1 problem(s) need attention" {
+		t.Fatalf("expected the orphan to be counted once, got %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "✗ claude: orphaned subagent link "+linkPath)
+
+	stdout, _, err = runMindful(t, projectDir, "doctor", "--fix")
+	if err != nil {
+		t.Fatalf("doctor --fix: %v\n%s", err, stdout)
+	}
+	assertContains(t, stdout, "fixed: pruned "+linkPath)
+	if _, err := os.Lstat(filepath.Join(projectDir, linkPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be pruned, err=%v", linkPath, err)
+	}
+}
+
+func TestDoctorReportsPathsMissingFromGitIgnore(t *testing.T) {
+	projectDir, _ := newDoctorProject(t)
+	if out, err := exec.Command("git", "init", "-q", projectDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".gitignore"), []byte("mindful/out\n"), 0o644); err != nil {
+		t.Fatalf("write .gitignore: %v", err)
+	}
+
+	stdout, _, err := runMindful(t, projectDir, "doctor")
+	uncovered := []string{
+		filepath.Join("mindful", models.DefaultStateFileName),
+		"CLAUDE.md",
+		filepath.Join(".claude", "agents", "reviewer.mindful.md"),
+	}
+	if err == nil || err.Error() != "3 problem(s) need attention" {
+		t.Fatalf("expected %d uncovered paths, got %v\n%s", len(uncovered), err, stdout)
+	}
+	for _, path := range uncovered {
+		assertContains(t, stdout, "✗ "+path+" is not covered by .gitignore\n  add "+path+" to .gitignore")
+	}
+	if strings.Contains(stdout, "✗ mindful/out is not covered") {
+		t.Errorf("expected mindful/out to be covered, got:\n%s", stdout)
+	}
+	if data, _ := os.ReadFile(filepath.Join(projectDir, ".gitignore")); string(data) != "mindful/out\n" {
+		t.Errorf("expected doctor to leave .gitignore alone, got %q", data)
+	}
+}
@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mindful/src/config"
+	"mindful/src/importer"
+	"mindful/src/models"
+)
+
+type memoryStore struct {
+	servers map[string]string
+}
+
+func (s *memoryStore) ListMCP() (map[string]string, error) {
+	out := make(map[string]string, len(s.servers))
+	for name, cfg := range s.servers {
+		out[name] = cfg
+	}
+	return out, nil
+}
+
+func (s *memoryStore) StoreMCP(serverName string, config string) error {
+	s.servers[serverName] = config
+	return nil
+}
+
+func TestImporterScansAndDeduplicates(t *testing.T) {
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"CLAUDE.md":                   "# Notes\nUse tabs.\n",
+		"AGENTS.md":                   "# Notes\r\nUse tabs.\r\n",
+		".claude/agents/rev.md":       "Review code\n",
+		".cursor/rules/rev.mdc":       "Review code\n",
+		".cursor/rules/style.mdc":     "---\nalwaysApply: true\n---\nPrefer small PRs\n",
+		".claude/agents/x.mindful.md": "generated\n",
+		".mcp.json":                   `{"mcpServers":{"fs":{"command":"npx","args":["fs"]}}}`,
+		".cursor/mcp.json":            `{"mcpServers":{"fs":{"args":["fs"],"command":"npx"}}}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+
+	items, err := importer.Scan(projectDir, nil)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(items) != 7 {
+		t.Fatalf("expected 7 items (generated files skipped), got %d: %+v", len(items), items)
+	}
+
+	store := &memoryStore{servers: map[string]string{}}
+	imp := importer.New(projectDir, store)
+	changes, err := imp.Plan(items)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	actions := make(map[string]string)
+	for _, change := range changes {
+		actions[change.Source] = change.Action
+	}
+	expected := map[string]string{
+		"CLAUDE.md":               importer.ActionCreate,
+		"AGENTS.md":               importer.ActionSkip,
+		".claude/agents/rev.md":   importer.ActionCreate,
+		".cursor/rules/rev.mdc":   importer.ActionSkip,
+		".cursor/rules/style.mdc": importer.ActionAppend,
+		".mcp.json":               importer.ActionStore,
+		".cursor/mcp.json":        importer.ActionSkip,
+	}
+	for source, action := range expected {
+		if actions[source] != action {
+			t.Fatalf("expected %s to %s, got %q (changes: %+v)", source, action, actions[source], changes)
+		}
+	}
+
+	if err := imp.Apply(changes); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	memory, err := os.ReadFile(filepath.Join(projectDir, "mindful", "project-memory.mdc"))
+	if err != nil {
+		t.Fatalf("read memory: %v", err)
+	}
+	if strings.Count(string(memory), "Use tabs.") != 1 || !strings.Contains(string(memory), "Prefer small PRs") {
+		t.Fatalf("unexpected project memory:\n%s", memory)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "mindful", "project-subagents", "rev.mdc")); err != nil {
+		t.Fatalf("expected imported subagent: %v", err)
+	}
+	if _, ok := store.servers["fs"]; !ok || len(store.servers) != 1 {
+		t.Fatalf("expected one stored MCP server, got %v", store.servers)
+	}
+
+	again, err := imp.Plan(items)
+	if err != nil {
+		t.Fatalf("second Plan: %v", err)
+	}
+	for _, change := range again {
+		if change.Action != importer.ActionSkip {
+			t.Fatalf("expected re-import to be a no-op, got %+v", change)
+		}
+	}
+}
+
+func TestImportPreviewDoesNotOpenTheStoreForWriting(t *testing.T) {
+	projectDir := newCLIProject(t)
+	teamDB := filepath.Join(filepath.Dir(projectDir), "team", models.DefaultStorageFileName)
+	mcpJSON := `{"mcpServers":{"docs":{"command":"npx","args":["docs-server"]}}}`
+	if err := os.WriteFile(filepath.Join(projectDir, ".mcp.json"), []byte(mcpJSON), 0o644); err != nil {
+		t.Fatalf("write .mcp.json: %v", err)
+	}
+
+	stdout, stderr, err := runMindful(t, projectDir, "import", "--dry-run")
+	if err != nil {
+		t.Fatalf("import --dry-run: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "docs") {
+		t.Fatalf("expected the docs server in the preview, got:\n%s", stdout)
+	}
+	if _, err := os.Stat(teamDB); !os.IsNotExist(err) {
+		t.Fatalf("expected the preview not to create %s, err=%v", teamDB, err)
+	}
+
+	// A git team source cannot be written, but it can still be previewed.
+	remote, _ := newGitRemote(t)
+	manager := config.NewManager()
+	cfg, err := manager.LoadProject(projectDir)
+	if err != nil {
+		t.Fatalf("load project config: %v", err)
+	}
+	cfg.Source = models.GitSourcePrefix + "file://" + remote
+	if err := manager.SaveProject(projectDir, cfg); err != nil {
+		t.Fatalf("save project config: %v", err)
+	}
+	if _, stderr, err := runMindful(t, projectDir, "import", "--dry-run"); err != nil {
+		t.Fatalf("import --dry-run with a git source: %v\n%s", err, stderr)
+	}
+	if _, _, err := runMindful(t, projectDir, "import", "--yes"); err == nil || !strings.Contains(err.Error(), "is a git repository") {
+		t.Fatalf("expected writing MCP servers into a git source to fail, got %v", err)
+	}
+}
+
+func TestImportWithoutMCPLeavesTheStoreAlone(t *testing.T) {
+	projectDir := newCLIProject(t)
+	teamDB := filepath.Join(filepath.Dir(projectDir), "team", models.DefaultStorageFileName)
+	if err := os.WriteFile(filepath.Join(projectDir, "CLAUDE.md"), []byte("Project rules\n"), 0o644); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	if _, stderr, err := runMindful(t, projectDir, "import", "--yes"); err != nil {
+		t.Fatalf("import: %v\n%s", err, stderr)
+	}
+	if _, err := os.Stat(teamDB); !os.IsNotExist(err) {
+		t.Fatalf("expected memory-only import not to create %s, err=%v", teamDB, err)
+	}
+	data, err := os.ReadFile(filepath.Join(projectDir, models.DefaultMindfulDirName, "project-memory.mdc"))
+	if err != nil || !strings.Contains(string(data), "Project rules") {
+		t.Fatalf("expected the imported project memory, got %q (err=%v)", data, err)
+	}
+}
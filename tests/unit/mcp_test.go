@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"mindful/src/models"
+)
+
+func TestMaskSecretsHidesEnvAndHeaders(t *testing.T) {
+	server := map[string]interface{}{
+		"command": "npx",
+		"env":     map[string]interface{}{"TOKEN": "ghp_1234567890abcd", "SHORT": "abc"},
+		"headers": map[string]interface{}{"Authorization": "Bearer secret-value"},
+	}
+
+	masked := models.MaskSecrets(server)
+
+	env := masked["env"].(map[string]interface{})
+	if env["TOKEN"] != "****abcd" || env["SHORT"] != "****" {
+		t.Fatalf("unexpected masked env: %v", env)
+	}
+	headers := masked["headers"].(map[string]interface{})
+	if headers["Authorization"] != "****alue" {
+		t.Fatalf("unexpected masked headers: %v", headers)
+	}
+	if masked["command"] != "npx" {
+		t.Fatalf("expected non-secret fields to be kept, got %v", masked)
+	}
+
+	original := server["env"].(map[string]interface{})
+	if original["TOKEN"] != "ghp_1234567890abcd" {
+		t.Fatalf("MaskSecrets must not modify its input")
+	}
+}
+
+func TestResolvePlaceholdersReportsUnresolved(t *testing.T) {
+	server := map[string]interface{}{
+		"command": "npx",
+		"args":    []interface{}{"--token=${secret:token}"},
+		"env": map[string]interface{}{
+			"KEY":     "${env:API_KEY}",
+			"MISSING": "${secret:absent}",
+		},
+	}
+	lookup := func(kind, name string) (string, bool, error) {
+		values := map[string]string{"secret:token": "t0k3n", "env:API_KEY": "sk-1"}
+		value, ok := values[kind+":"+name]
+		return value, ok, nil
+	}
+
+	resolved, unresolved, err := models.ResolvePlaceholders(server, lookup)
+	if err != nil {
+		t.Fatalf("ResolvePlaceholders: %v", err)
+	}
+
+	out := resolved.(map[string]interface{})
+	if out["args"].([]interface{})[0] != "--token=t0k3n" {
+		t.Fatalf("expected secret to be substituted in args, got %v", out["args"])
+	}
+	env := out["env"].(map[string]interface{})
+	if env["KEY"] != "sk-1" || env["MISSING"] != "${secret:absent}" {
+		t.Fatalf("unexpected env after resolution: %v", env)
+	}
+	if len(unresolved) != 1 || unresolved[0] != "${secret:absent}" {
+		t.Fatalf("expected one unresolved reference, got %v", unresolved)
+	}
+
+	masked := models.MaskSecrets(server)["env"].(map[string]interface{})
+	if masked["KEY"] != "${env:API_KEY}" {
+		t.Fatalf("expected placeholders to stay visible when masking, got %v", masked["KEY"])
+	}
+}
+
+func TestMCPServerRoundTripsUnknownFields(t *testing.T) {
+	input := `{"transport":"stdio","command":"npx","args":["-y","server"],"timeout":5000,"alwaysAllow":["read"]}`
+
+	server, err := models.ParseMCPServer("github", []byte(input))
+	if err != nil {
+		t.Fatalf("ParseMCPServer failed: %v", err)
+	}
+	if err := server.Validate(); err != nil {
+		t.Fatalf("expected valid server, got %v", err)
+	}
+	if server.Command != "npx" || server.Timeout != 5000 || len(server.Args) != 2 {
+		t.Fatalf("unexpected typed fields: %+v", server)
+	}
+
+	data, err := json.Marshal(server)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var got, want map[string]interface{}
+	json.Unmarshal(data, &got)
+	json.Unmarshal([]byte(input), &want)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("round trip changed the definition:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func TestMCPServerValidationNamesServerAndField(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		want  []string
+	}{
+		"typo":        {`{"comand":"npx"}`, []string{`field "comand": unknown field (did you mean "command"?)`, `field "command": required for stdio servers`}},
+		"swapped":     {`{"command":"npx","evn":{}}`, []string{`field "evn": unknown field (did you mean "env"?)`}},
+		"http-stdio":  {`{"type":"http","command":"npx"}`, []string{`field "url": required for http servers`, `field "command": not allowed for http servers`}},
+		"bad-url":     {`{"type":"sse","url":"ftp://example.com"}`, []string{`field "url": URL must use http or https`}},
+		"bad-type":    {`{"type":"websocket","url":"https://example.com"}`, []string{`field "type": unknown transport "websocket"`}},
+		"wrong-shape": {`{"command":"npx","args":"-y"}`, []string{`field "args": must be an array of strings`}},
+	}
+
+	for name, tc := range cases {
+		server, err := models.ParseMCPServer("svc", []byte(tc.input))
+		if err == nil {
+			err = server.Validate()
+		}
+		if err == nil {
+			t.Fatalf("%s: expected a validation error", name)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(err.Error(), `MCP server "svc": `+want) {
+				t.Fatalf("%s: expected %q in error, got:\n%v", name, want, err)
+			}
+		}
+	}
+}
+
+func TestMCPSettingsSelectServers(t *testing.T) {
+	settings := &models.MCPSettings{
+		Include: []string{"github", "db-*", "jira"},
+		Exclude: []string{"db-prod"},
+		Overrides: map[string]*models.MCPServerOverride{
+			"github": {Args: []string{"--read-only"}},
+			"slack":  {Env: map[string]string{"CHANNEL": "dev"}},
+		},
+	}
+
+	selected, missing := settings.SelectServers([]string{"db-prod", "github", "db-dev", "notion"})
+
+	if strings.Join(selected, ",") != "db-dev,github" {
+		t.Fatalf("unexpected selection: %v", selected)
+	}
+	if strings.Join(missing, ",") != "jira,slack" {
+		t.Fatalf("unexpected missing servers: %v", missing)
+	}
+
+	var all *models.MCPSettings
+	if selected, missing := all.SelectServers([]string{"b", "a"}); strings.Join(selected, ",") != "a,b" || len(missing) != 0 {
+		t.Fatalf("expected every server without settings, got %v %v", selected, missing)
+	}
+}
+
+func TestMCPServerOverrideAppendsArgsAndMergesEnv(t *testing.T) {
+	server, err := models.ParseMCPServer("github", []byte(`{"command":"npx","args":["server"],"env":{"A":"1","B":"2"}}`))
+	if err != nil {
+		t.Fatalf("ParseMCPServer failed: %v", err)
+	}
+
+	override := &models.MCPServerOverride{Args: []string{"--read-only"}, Env: map[string]string{"B": "project", "C": "3"}}
+	override.Apply(server)
+
+	if strings.Join(server.Args, " ") != "server --read-only" {
+		t.Fatalf("unexpected args: %v", server.Args)
+	}
+	if server.Env["A"] != "1" || server.Env["B"] != "project" || server.Env["C"] != "3" {
+		t.Fatalf("unexpected env: %v", server.Env)
+	}
+}
+
+func TestRenderMCPFormats(t *testing.T) {
+	github, err := models.ParseMCPServer("github", []byte(`{"command":"npx","args":["server"],"env":{"GITHUB_TOKEN":"${env:GITHUB_TOKEN}","MODE":"ro"},"timeout":1500}`))
+	if err != nil {
+		t.Fatalf("ParseMCPServer failed: %v", err)
+	}
+	events, err := models.ParseMCPServer("events", []byte(`{"type":"sse","url":"https://example.com/sse"}`))
+	if err != nil {
+		t.Fatalf("ParseMCPServer failed: %v", err)
+	}
+	servers := []*models.MCPServer{github, events}
+
+	data, _, err := models.RenderMCP(models.MCPFormatVSCode, servers, []models.MCPInput{{Type: "promptString", ID: "token", Password: true}})
+	if err != nil {
+		t.Fatalf("RenderMCP vscode failed: %v", err)
+	}
+	var vscode struct {
+		Servers map[string]map[string]interface{} `json:"servers"`
+		Inputs  []models.MCPInput                 `json:"inputs"`
+	}
+	if err := json.Unmarshal(data, &vscode); err != nil {
+		t.Fatalf("invalid vscode output: %v", err)
+	}
+	if vscode.Servers["github"]["type"] != "stdio" || vscode.Servers["events"]["type"] != "sse" || len(vscode.Inputs) != 1 {
+		t.Fatalf("unexpected vscode output: %s", data)
+	}
+
+	data, _, err = models.RenderMCP(models.MCPFormatZed, servers, nil)
+	if err != nil {
+		t.Fatalf("RenderMCP zed failed: %v", err)
+	}
+	var zed struct {
+		ContextServers map[string]map[string]interface{} `json:"context_servers"`
+	}
+	if err := json.Unmarshal(data, &zed); err != nil {
+		t.Fatalf("invalid zed output: %v", err)
+	}
+	if zed.ContextServers["github"]["command"] != "npx" || zed.ContextServers["events"]["url"] != "https://example.com/sse" {
+		t.Fatalf("unexpected zed output: %s", data)
+	}
+
+	data, warnings, err := models.RenderMCP(models.MCPFormatCodex, servers, nil)
+	if err != nil {
+		t.Fatalf("RenderMCP codex failed: %v", err)
+	}
+	expected := `[mcp_servers.github]
+command = "npx"
+args = ["server"]
+env = { MODE = "ro" }
+env_vars = ["GITHUB_TOKEN"]
+startup_timeout_sec = 1.5
+`
+	if string(data) != expected {
+		t.Fatalf("unexpected codex output:\n%s", data)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"events"`) {
+		t.Fatalf("expected a warning for the sse server, got %v", warnings)
+	}
+}
+
+func TestMCPServerValidationKeepsShortCustomKeys(t *testing.T) {
+	// "tags" is two edits from "args" and "id" two from "cwd"; neither is a typo.
+	server, err := models.ParseMCPServer("svc", []byte(`{"command":"npx","tags":["internal"],"id":"svc-1"}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := server.Validate(); err != nil {
+		t.Fatalf("expected custom keys to pass validation, got:\n%v", err)
+	}
+	if _, ok := server.Extra["tags"]; !ok {
+		t.Fatalf("expected tags to be kept in Extra, got %v", server.Extra)
+	}
+	if _, ok := server.Extra["id"]; !ok {
+		t.Fatalf("expected id to be kept in Extra, got %v", server.Extra)
+	}
+}
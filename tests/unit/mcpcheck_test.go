@@ -0,0 +1,188 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"mindful/src/mcpcheck"
+	"mindful/src/models"
+)
+
+// fakeMCPReply answers the requests mcpcheck sends; it returns nil for notifications.
+func fakeMCPReply(line []byte) map[string]interface{} {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if json.Unmarshal(line, &req) != nil || len(req.ID) == 0 {
+		return nil
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"protocolVersion": mcpcheck.ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "fake", "version": "1.2.3"},
+		}
+	case "tools/list":
+		result = map[string]interface{}{"tools": []map[string]string{{"name": "a"}, {"name": "b"}}}
+	default:
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"}}
+	}
+	return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+}
+
+// TestMCPCheckHelperProcess is not a real test: it is started by the stdio tests
+// below and acts as an MCP server on stdin/stdout.
+func TestMCPCheckHelperProcess(t *testing.T) {
+	mode := os.Getenv("MINDFUL_MCP_HELPER")
+	if mode == "" {
+		return
+	}
+	if mode == "fail" {
+		fmt.Fprintln(os.Stderr, "starting fake server")
+		fmt.Fprintln(os.Stderr, "fatal: FAKE_TOKEN is not set")
+		os.Exit(1)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		if reply := fakeMCPReply(scanner.Bytes()); reply != nil {
+			encoder.Encode(reply)
+		}
+	}
+	os.Exit(0)
+}
+
+func helperServer(mode string) *models.MCPServer {
+	return &models.MCPServer{
+		Name:    "helper",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestMCPCheckHelperProcess"},
+		Env:     map[string]string{"MINDFUL_MCP_HELPER": mode},
+	}
+}
+
+func TestMCPCheckStdioServer(t *testing.T) {
+	result := mcpcheck.Check(context.Background(), helperServer("serve"), mcpcheck.Options{Timeout: 10 * time.Second})
+	if !result.OK() {
+		t.Fatalf("expected the check to pass, got %v (stderr: %s)", result.Err, result.Stderr)
+	}
+	if result.ServerName != "fake" || result.ProtocolVersion != mcpcheck.ProtocolVersion || result.Tools != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestMCPCheckReportsStderrOfFailingServer(t *testing.T) {
+	result := mcpcheck.Check(context.Background(), helperServer("fail"), mcpcheck.Options{Timeout: 10 * time.Second})
+	if result.OK() {
+		t.Fatal("expected the check to fail")
+	}
+	if !strings.Contains(result.Stderr, "FAKE_TOKEN is not set") {
+		t.Fatalf("expected the stderr tail, got %q (err %v)", result.Stderr, result.Err)
+	}
+}
+
+func TestMCPCheckHTTPServer(t *testing.T) {
+	var sawAuth bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sawAuth = sawAuth || r.Header.Get("Authorization") == "Bearer token"
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		reply := fakeMCPReply(body)
+		if reply == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		if strings.Contains(string(body), "tools/list") {
+			// Answer over an event stream, as streaming servers do.
+			data, _ := json.Marshal(reply)
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reply)
+	}))
+	defer server.Close()
+
+	result := mcpcheck.Check(context.Background(), &models.MCPServer{
+		Name:    "remote",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}, mcpcheck.Options{Timeout: 5 * time.Second})
+	if !result.OK() {
+		t.Fatalf("expected the check to pass, got %v", result.Err)
+	}
+	if result.Transport != models.MCPTransportHTTP || result.Tools != 2 || !sawAuth {
+		t.Fatalf("unexpected result: %+v (auth header seen: %v)", result, sawAuth)
+	}
+}
+
+func TestMCPCheckSSEServer(t *testing.T) {
+	replies := make(chan map[string]interface{}, 4)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case reply := <-replies:
+				data, _ := json.Marshal(reply)
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		if reply := fakeMCPReply(body); reply != nil {
+			replies <- reply
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result := mcpcheck.Check(context.Background(), &models.MCPServer{
+		Name:      "legacy",
+		Transport: models.MCPTransportSSE,
+		URL:       server.URL + "/sse",
+	}, mcpcheck.Options{Timeout: 5 * time.Second})
+	if !result.OK() {
+		t.Fatalf("expected the check to pass, got %v", result.Err)
+	}
+	if result.ServerVersion != "1.2.3" || result.Tools != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestMCPCheckTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	result := mcpcheck.Check(context.Background(), &models.MCPServer{Name: "slow", URL: server.URL}, mcpcheck.Options{Timeout: 200 * time.Millisecond})
+	if result.OK() || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", result.Err)
+	}
+}
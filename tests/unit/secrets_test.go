@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mindful/src/storage"
+)
+
+func TestSecretsInitKeyIsRequiredBeforeTheFirstWrite(t *testing.T) {
+	projectDir := newCLIProject(t)
+	t.Setenv(storage.PassphraseEnv, "")
+	t.Setenv(storage.KeyFileEnv, "")
+	shared := storage.DefaultKeyFile()
+	if err := os.Remove(shared); err != nil {
+		t.Fatalf("remove key file: %v", err)
+	}
+
+	_, _, err := runMindful(t, projectDir, "mcp", "add", "docs", "--command", "npx")
+	if !errors.Is(err, storage.ErrKeyMissing) || !strings.Contains(err.Error(), "mindful secrets init-key") {
+		t.Fatalf("expected a missing key to point at init-key, got %v", err)
+	}
+	if _, err := os.Stat(shared); !os.IsNotExist(err) {
+		t.Fatalf("expected no key file to be generated implicitly, err=%v", err)
+	}
+
+	stdout, stderr, err := runMindful(t, projectDir, "secrets", "init-key")
+	if err != nil {
+		t.Fatalf("init-key: %v\n%s", err, stderr)
+	}
+	assertContains(t, stdout, "✓ created key file "+shared)
+	before, err := os.ReadFile(shared)
+	if err != nil {
+		t.Fatalf("read key file: %v", err)
+	}
+	if _, _, err := runMindful(t, projectDir, "secrets", "init-key"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected init-key to refuse an existing key file, got %v", err)
+	}
+	if after, _ := os.ReadFile(shared); string(after) != string(before) {
+		t.Fatal("existing key file was rewritten")
+	}
+
+	if _, stderr, err := runMindful(t, projectDir, "mcp", "add", "docs", "--command", "npx"); err != nil {
+		t.Fatalf("mcp add: %v\n%s", err, stderr)
+	}
+}
+
+func TestRotateKeySwapsInTheDefaultKeyFile(t *testing.T) {
+	projectDir := newCLIProject(t)
+	t.Setenv(storage.PassphraseEnv, "")
+	t.Setenv(storage.KeyFileEnv, "")
+
+	if _, stderr, err := runMindful(t, projectDir, "secrets", "set", "token", "s3cret"); err != nil {
+		t.Fatalf("secrets set: %v\n%s", err, stderr)
+	}
+	shared := storage.DefaultKeyFile()
+	before, err := os.ReadFile(shared)
+	if err != nil {
+		t.Fatalf("read shared key: %v", err)
+	}
+
+	if err := os.WriteFile(shared+".new", []byte("staged"), 0o600); err != nil {
+		t.Fatalf("write staged key: %v", err)
+	}
+	if _, _, err := runMindful(t, projectDir, "secrets", "rotate-key"); err == nil || !strings.Contains(err.Error(), "earlier rotation") {
+		t.Fatalf("expected a leftover staged key to stop the rotation, got %v", err)
+	}
+	if data, _ := os.ReadFile(shared + ".new"); string(data) != "staged" {
+		t.Fatal("leftover staged key was overwritten")
+	}
+	if err := os.Remove(shared + ".new"); err != nil {
+		t.Fatalf("remove staged key: %v", err)
+	}
+
+	stdout, stderr, err := runMindful(t, projectDir, "secrets", "rotate-key")
+	if err != nil {
+		t.Fatalf("rotate-key: %v\n%s", err, stderr)
+	}
+	assertContains(t, stdout, "✓ key rotated to the key file "+shared, "the previous key was kept in "+shared+".old")
+	if _, err := os.Stat(shared + ".new"); !os.IsNotExist(err) {
+		t.Errorf("expected the staged key to be moved into place, err=%v", err)
+	}
+	if after, _ := os.ReadFile(shared); string(after) == string(before) {
+		t.Error("expected the default key file to hold a new key")
+	}
+	if old, _ := os.ReadFile(shared + ".old"); string(old) != string(before) {
+		t.Error("expected the previous key to be kept")
+	}
+
+	stdout, stderr, err = runMindful(t, projectDir, "secrets", "list")
+	if err != nil || !strings.Contains(stdout, "token") {
+		t.Fatalf("secrets list with the new key: %v\n%s%s", err, stdout, stderr)
+	}
+}
+
+func TestRotateKeyWritesANewKeyFile(t *testing.T) {
+	projectDir := newCLIProject(t)
+	t.Setenv(storage.PassphraseEnv, "")
+	t.Setenv(storage.KeyFileEnv, "")
+
+	if _, stderr, err := runMindful(t, projectDir, "secrets", "set", "token", "s3cret"); err != nil {
+		t.Fatalf("secrets set: %v\n%s", err, stderr)
+	}
+	shared := storage.DefaultKeyFile()
+	before, err := os.ReadFile(shared)
+	if err != nil {
+		t.Fatalf("read shared key: %v", err)
+	}
+
+	newKey := filepath.Join(t.TempDir(), "project.key")
+	stdout, stderr, err := runMindful(t, projectDir, "secrets", "rotate-key", "--key-file", newKey)
+	if err != nil {
+		t.Fatalf("rotate-key: %v\n%s", err, stderr)
+	}
+	assertContains(t, stdout, "✓ key rotated to the key file "+newKey, "point "+storage.KeyFileEnv+" at "+newKey)
+	if after, _ := os.ReadFile(shared); string(after) != string(before) {
+		t.Fatal("shared key file was rewritten")
+	}
+
+	t.Setenv(storage.KeyFileEnv, newKey)
+	stdout, stderr, err = runMindful(t, projectDir, "secrets", "list")
+	if err != nil || !strings.Contains(stdout, "token") {
+		t.Fatalf("secrets list with the new key: %v\n%s%s", err, stdout, stderr)
+	}
+}
+
+func TestRotateKeyReencryptsTheUserStore(t *testing.T) {
+	projectDir := newCLIProject(t)
+	t.Setenv(storage.PassphraseEnv, "old passphrase")
+	t.Setenv(storage.KeyFileEnv, "")
+
+	if _, stderr, err := runMindful(t, projectDir, "mcp", "add", "personal", "--scope", "user", "--command", "npx"); err != nil {
+		t.Fatalf("mcp add --scope user: %v\n%s", err, stderr)
+	}
+
+	t.Setenv("MINDFUL_NEW_PASSPHRASE", "new passphrase")
+	stdout, stderr, err := runMindful(t, projectDir, "secrets", "rotate-key")
+	if err != nil {
+		t.Fatalf("rotate-key: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "user mindful.db: re-encrypted 1 values") {
+		t.Errorf("expected the user store to be rotated, got:\n%s", stdout)
+	}
+
+	t.Setenv(storage.PassphraseEnv, "new passphrase")
+	stdout, stderr, err = runMindful(t, projectDir, "mcp", "show", "personal", "--scope", "user")
+	if err != nil || !strings.Contains(stdout, "npx") {
+		t.Fatalf("mcp show with the new passphrase: %v\n%s%s", err, stdout, stderr)
+	}
+}
@@ -1,12 +1,17 @@
 package unit
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
+	"mindful/src/models"
 	"mindful/src/source"
+	"mindful/src/storage"
 )
 
 func TestLoadArtifactsCombinesTeamAndProject(t *testing.T) {
@@ -77,3 +82,631 @@ func TestLoadArtifactsCombinesTeamAndProject(t *testing.T) {
 		t.Errorf("subagent content mismatch: %q", subagent.Content)
 	}
 }
+
+func TestLoadProjectMCPReadsYAML(t *testing.T) {
+	projectDir := t.TempDir()
+	mindfulDir := filepath.Join(projectDir, "mindful")
+	if err := os.MkdirAll(mindfulDir, 0o755); err != nil {
+		t.Fatalf("mindful dir: %v", err)
+	}
+
+	manager := source.NewManager()
+	if cfg, path, err := manager.LoadProjectMCP(projectDir); err != nil || cfg != nil || path != "" {
+		t.Fatalf("expected no project MCP config, got %v %q %v", cfg, path, err)
+	}
+
+	content := "mcpServers:\n  docs:\n    url: https://example.com/mcp\n  fs:\n    command: npx\n    args: [server, .]\n"
+	if err := os.WriteFile(filepath.Join(mindfulDir, "mcp.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write mcp.yaml: %v", err)
+	}
+
+	cfg, path, err := manager.LoadProjectMCP(projectDir)
+	if err != nil {
+		t.Fatalf("LoadProjectMCP failed: %v", err)
+	}
+	if filepath.Base(path) != "mcp.yaml" {
+		t.Fatalf("unexpected source path %q", path)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid servers, got %v", err)
+	}
+	server, err := cfg.Server("fs")
+	if err != nil {
+		t.Fatalf("server fs: %v", err)
+	}
+	if server.Command != "npx" || strings.Join(server.Args, " ") != "server ." {
+		t.Fatalf("unexpected server: %+v", server)
+	}
+	if !cfg.HasServer("docs") {
+		t.Fatalf("expected docs server, got %v", cfg.ListServers())
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	src, err := models.ParseGitSource("git+ssh://git@example.com/acme/agents.git#v1.2")
+	if err != nil || src == nil || src.URL != "ssh://git@example.com/acme/agents.git" || src.Ref != "v1.2" {
+		t.Fatalf("unexpected parse result %+v (err=%v)", src, err)
+	}
+	if src, err := models.ParseGitSource("~/team-source"); src != nil || err != nil {
+		t.Fatalf("expected a local path to be left alone, got %+v (err=%v)", src, err)
+	}
+	for _, value := range []string{"git+ftp://example.com/repo", "git+https://", "git+file:///srv/repo#"} {
+		if _, err := models.ParseGitSource(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+// newGitRemote creates a bare repository whose main branch holds memory.md and
+// returns it with a function that commits and pushes new memory content.
+func newGitRemote(t *testing.T) (string, func(content string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, "cache"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(tempDir, "gitconfig"))
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	remote := filepath.Join(tempDir, "team.git")
+	work := filepath.Join(tempDir, "work")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(tempDir, "init", "-q", "--bare", "--initial-branch=main", remote)
+	git(tempDir, "init", "-q", "--initial-branch=main", work)
+	git(work, "remote", "add", "origin", remote)
+
+	commitMemory := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, "memory.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write memory: %v", err)
+		}
+		git(work, "add", "memory.md")
+		git(work, "commit", "-q", "-m", content)
+		git(work, "push", "-q", "origin", "HEAD:main")
+	}
+	commitMemory("first")
+	git(work, "tag", "v1")
+	git(work, "push", "-q", "origin", "v1")
+	return remote, commitMemory
+}
+
+func assertSourceMemory(t *testing.T, dir, want string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "memory.md"))
+	if err != nil || string(data) != want {
+		t.Fatalf("expected memory %q in %s, got %q (err=%v)", want, dir, data, err)
+	}
+}
+
+func TestSyncGitSourceClonesAndUpdates(t *testing.T) {
+	remote, commitMemory := newGitRemote(t)
+
+	manager := source.NewManager()
+	latest := &models.GitSource{URL: "file://" + remote}
+	pinned := &models.GitSource{URL: "file://" + remote, Ref: "v1"}
+
+	checkout, err := manager.SyncGitSource(latest, false)
+	if err != nil || !checkout.Cloned {
+		t.Fatalf("expected a fresh clone, got %+v (err=%v)", checkout, err)
+	}
+	assertSourceMemory(t, checkout.Dir, "first")
+
+	commitMemory("second")
+	cached, err := manager.SyncGitSource(latest, false)
+	if err != nil || cached.Changed() || cached.Dir != checkout.Dir {
+		t.Fatalf("expected the cached checkout to be reused, got %+v (err=%v)", cached, err)
+	}
+	assertSourceMemory(t, cached.Dir, "first")
+
+	updated, err := manager.SyncGitSource(latest, true)
+	if err != nil || !updated.Changed() || updated.Previous != checkout.Commit {
+		t.Fatalf("expected the update to move the checkout, got %+v (err=%v)", updated, err)
+	}
+	assertSourceMemory(t, updated.Dir, "second")
+
+	tagged, err := manager.SyncGitSource(pinned, true)
+	if err != nil || tagged.Dir == updated.Dir {
+		t.Fatalf("expected a separate checkout for the tag, got %+v (err=%v)", tagged, err)
+	}
+	assertSourceMemory(t, tagged.Dir, "first")
+
+	if _, err := manager.SyncGitSource(&models.GitSource{URL: "file://" + remote, Ref: "missing"}, false); err == nil {
+		t.Fatal("expected an unknown ref to fail")
+	}
+}
+
+func TestGitSourcePinnedCommit(t *testing.T) {
+	remote, commitMemory := newGitRemote(t)
+	manager := source.NewManager()
+	src := &models.GitSource{URL: "file://" + remote, Ref: "main"}
+	value := models.GitSourcePrefix + "file://" + remote + "#main"
+
+	checkout, err := manager.SyncGitSource(src, false)
+	if err != nil {
+		t.Fatalf("SyncGitSource: %v", err)
+	}
+	pin, err := manager.SnapshotSource(value, checkout.Dir)
+	if err != nil || pin.Commit != checkout.Commit || pin.Source != value {
+		t.Fatalf("unexpected snapshot %+v (err=%v)", pin, err)
+	}
+
+	commitMemory("second")
+	latest, err := manager.SyncGitSource(src, true)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	assertSourceMemory(t, latest.Dir, "second")
+
+	pinned, err := manager.CheckoutGitCommit(src, pin.Commit)
+	if err != nil || pinned.Commit != pin.Commit {
+		t.Fatalf("CheckoutGitCommit returned %+v (err=%v)", pinned, err)
+	}
+	assertSourceMemory(t, pinned.Dir, "first")
+	if pinned.Dir == latest.Dir {
+		t.Fatal("expected the pinned commit to get its own checkout")
+	}
+	// Builds pinned at another commit must not move the shared checkout.
+	assertSourceMemory(t, latest.Dir, "second")
+
+	again, err := manager.CheckoutGitCommit(src, pin.Commit)
+	if err != nil || again.Dir != pinned.Dir {
+		t.Fatalf("expected the commit checkout to be reused, got %+v (err=%v)", again, err)
+	}
+	current, err := manager.CheckoutGitCommit(src, latest.Commit)
+	if err != nil || current.Dir == pinned.Dir {
+		t.Fatalf("expected a separate checkout per commit, got %+v (err=%v)", current, err)
+	}
+	assertSourceMemory(t, current.Dir, "second")
+	assertSourceMemory(t, pinned.Dir, "first")
+
+	changes, err := manager.DiffGitSource(pinned.Dir, pin.Commit, latest.Commit)
+	if err != nil {
+		t.Fatalf("DiffGitSource: %v", err)
+	}
+	if len(changes.Commits) != 1 || !strings.HasSuffix(changes.Commits[0], " second") {
+		t.Fatalf("unexpected commits %q", changes.Commits)
+	}
+	if len(changes.Files) != 1 || changes.Files[0] != "M\tmemory.md" {
+		t.Fatalf("unexpected files %q", changes.Files)
+	}
+}
+
+func TestConcurrentCommitCheckouts(t *testing.T) {
+	remote, commitMemory := newGitRemote(t)
+	manager := source.NewManager()
+	src := &models.GitSource{URL: "file://" + remote, Ref: "main"}
+
+	first, err := manager.SyncGitSource(src, false)
+	if err != nil {
+		t.Fatalf("SyncGitSource: %v", err)
+	}
+	commitMemory("second")
+	second, err := manager.SyncGitSource(src, true)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	want := map[string]string{first.Commit: "first", second.Commit: "second"}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		commit := first.Commit
+		if i%2 == 1 {
+			commit = second.Commit
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkout, err := manager.CheckoutGitCommit(src, commit)
+			if err != nil {
+				errs <- err
+				return
+			}
+			data, err := os.ReadFile(filepath.Join(checkout.Dir, "memory.md"))
+			if err != nil || string(data) != want[commit] {
+				errs <- fmt.Errorf("checkout of %s has memory %q (err=%v)", commit, data, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSnapshotLocalSourceHashesBuildInputs(t *testing.T) {
+	teamDir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(teamDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("memory.md", "team")
+	write("subagents/researcher.md", "researcher")
+	write("notes.txt", "not a build input")
+
+	manager := source.NewManager()
+	before, err := manager.SnapshotSource(teamDir, teamDir)
+	if err != nil {
+		t.Fatalf("SnapshotSource: %v", err)
+	}
+	if len(before.Files) != 2 || before.Files["subagents/researcher.md"] == "" || before.Commit != "" {
+		t.Fatalf("unexpected snapshot %+v", before)
+	}
+
+	write("memory.md", "team, edited")
+	write("subagents/reviewer.md", "reviewer")
+	if err := os.Remove(filepath.Join(teamDir, "subagents", "researcher.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	after, err := manager.SnapshotSource(teamDir, teamDir)
+	if err != nil {
+		t.Fatalf("SnapshotSource: %v", err)
+	}
+
+	changes := before.DiffFiles(after)
+	if strings.Join(changes.Added, ",") != "subagents/reviewer.md" ||
+		strings.Join(changes.Changed, ",") != "memory.md" ||
+		strings.Join(changes.Removed, ",") != "subagents/researcher.md" {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if !after.DiffFiles(after).Empty() {
+		t.Fatal("expected a snapshot to match itself")
+	}
+}
+
+func TestSnapshotLocalSourceHashesStoredMCPConfigs(t *testing.T) {
+	teamDir := t.TempDir()
+	dbPath := filepath.Join(teamDir, models.DefaultStorageFileName)
+	keys := newKeyFile(t, t.TempDir())
+	openStore := func() *storage.Manager {
+		t.Helper()
+		store, err := storage.NewManagerWithKeys(dbPath, keys)
+		if err != nil {
+			t.Fatalf("NewManagerWithKeys: %v", err)
+		}
+		return store
+	}
+	store := openStore()
+	if err := store.StoreMCP("docs", "eyJjb21tYW5kIjoibnB4In0="); err != nil {
+		t.Fatalf("StoreMCP: %v", err)
+	}
+	store.Close()
+
+	manager := source.NewManager()
+	before, err := manager.SnapshotSource(teamDir, teamDir)
+	if err != nil {
+		t.Fatalf("SnapshotSource: %v", err)
+	}
+	if before.Files[models.DefaultStorageFileName] == "" {
+		t.Fatalf("expected the MCP store to be hashed, got %+v", before)
+	}
+
+	// Reopening for writing and a write that is undone leave the configs unchanged.
+	store = openStore()
+	if err := store.StoreMCP("scratch", "eyJjb21tYW5kIjoibnB4In0="); err != nil {
+		t.Fatalf("StoreMCP: %v", err)
+	}
+	if err := store.DeleteMCP("scratch"); err != nil {
+		t.Fatalf("DeleteMCP: %v", err)
+	}
+	store.Close()
+	store = openStore()
+	store.Close()
+
+	after, err := manager.SnapshotSource(teamDir, teamDir)
+	if err != nil {
+		t.Fatalf("SnapshotSource: %v", err)
+	}
+	if changes := before.DiffFiles(after); !changes.Empty() {
+		t.Fatalf("expected no drift after reopening the store, got %+v", changes)
+	}
+
+	store = openStore()
+	if err := store.StoreMCP("search", "eyJjb21tYW5kIjoibnB4In0="); err != nil {
+		t.Fatalf("StoreMCP: %v", err)
+	}
+	store.Close()
+	after, err = manager.SnapshotSource(teamDir, teamDir)
+	if err != nil {
+		t.Fatalf("SnapshotSource: %v", err)
+	}
+	if changes := before.DiffFiles(after); strings.Join(changes.Changed, ",") != models.DefaultStorageFileName {
+		t.Fatalf("expected the new server to change the store hash, got %+v", changes)
+	}
+}
+
+func TestLoadLayersMergesInPrecedenceOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("org/memory.md", "Org baseline")
+	write("org/subagents/reviewer.md", "Org reviewer")
+	write("org/subagents/helper.md", "Org helper")
+	write("team/memory.mdc", "Team rules")
+	write("team/subagents/reviewer.md", "Team reviewer")
+	write("project/mindful/project-memory.mdc", "Project notes")
+	write("user/memory.mdc", "My preferences")
+	write("user/subagents/helper.md", "My helper")
+
+	layers := []source.Layer{
+		source.SharedLayer("org", "org", "git+https://example.com/org.git", filepath.Join(tempDir, "org")),
+		source.SharedLayer("team", "team", "../team", filepath.Join(tempDir, "team")),
+		source.ProjectLayer(filepath.Join(tempDir, "project")),
+		source.UserLayer(filepath.Join(tempDir, "user")),
+	}
+	artifacts, err := source.NewManager().LoadLayers(layers)
+	if err != nil {
+		t.Fatalf("LoadLayers: %v", err)
+	}
+
+	memory := artifacts.Memory.Content
+	last := -1
+	for _, section := range []string{"scope:org", "Org baseline", "scope:team", "Team rules", "scope:project", "Project notes", "scope:user", "My preferences"} {
+		index := strings.Index(memory, section)
+		if index <= last {
+			t.Fatalf("expected %q after the previous section in %q", section, memory)
+		}
+		last = index
+	}
+
+	subagents := map[string]string{}
+	for _, subagent := range artifacts.Subagents {
+		subagents[subagent.Name] = subagent.Content
+	}
+	if len(subagents) != 2 {
+		t.Fatalf("expected 2 subagents, got %v", subagents)
+	}
+	if content := subagents["reviewer"]; !strings.Contains(content, "scope:team") || !strings.Contains(content, "Team reviewer") {
+		t.Errorf("team reviewer should override the org one: %q", content)
+	}
+	if content := subagents["helper"]; !strings.Contains(content, "scope:user") || !strings.Contains(content, "My helper") {
+		t.Errorf("user helper should override the org one: %q", content)
+	}
+}
+
+func TestProjectConfigValidatesSourceLayers(t *testing.T) {
+	valid := &models.ProjectConfig{Name: "demo", Version: "1.0.0", Sources: []*models.SourceLayer{
+		{Name: "org", Path: "git+https://example.com/org.git#main"},
+		{Name: "team", Path: "../team", Scope: "team"},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid sources, got %v", err)
+	}
+	if scope := valid.Sources[0].EffectiveScope(); scope != "org" {
+		t.Fatalf("expected the scope to default to the name, got %q", scope)
+	}
+
+	for name, cfg := range map[string]*models.ProjectConfig{
+		"duplicate name": {Name: "demo", Version: "1.0.0", Sources: []*models.SourceLayer{
+			{Name: "team", Path: "../a"}, {Name: "team", Path: "../b"},
+		}},
+		"reserved scope": {Name: "demo", Version: "1.0.0", Sources: []*models.SourceLayer{
+			{Name: "mine", Path: "../a", Scope: "user"},
+		}},
+		"source and sources": {Name: "demo", Version: "1.0.0", Source: "../a", Sources: []*models.SourceLayer{
+			{Name: "team", Path: "../b"},
+		}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestUserLayerIsMarkedPersonal(t *testing.T) {
+	tempDir := t.TempDir()
+	userDir := filepath.Join(tempDir, "me")
+	if err := os.MkdirAll(filepath.Join(userDir, "subagents"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "memory.mdc"), []byte("My preferences"), 0o644); err != nil {
+		t.Fatalf("write user memory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "subagents", "helper.md"), []byte("My helper"), 0o644); err != nil {
+		t.Fatalf("write user subagent: %v", err)
+	}
+
+	t.Setenv(models.UserDirEnv, userDir)
+	resolved, err := models.UserMindfulDir()
+	if err != nil || resolved != userDir {
+		t.Fatalf("expected %s to select %s, got %q (err=%v)", models.UserDirEnv, userDir, resolved, err)
+	}
+
+	artifacts, err := source.NewManager().LoadLayers([]source.Layer{
+		source.ProjectLayer(filepath.Join(tempDir, "project")),
+		source.UserLayer(resolved),
+	})
+	if err != nil {
+		t.Fatalf("LoadLayers: %v", err)
+	}
+	if !strings.Contains(artifacts.Memory.Content, "scope:user") || !strings.Contains(artifacts.Memory.Content, models.PersonalNotice) {
+		t.Errorf("user memory should be marked personal: %q", artifacts.Memory.Content)
+	}
+	if len(artifacts.Subagents) != 1 || !artifacts.Subagents[0].Personal || !strings.Contains(artifacts.Subagents[0].Content, models.PersonalNotice) {
+		t.Fatalf("user subagent should be marked personal: %+v", artifacts.Subagents)
+	}
+	if len(artifacts.Personal) != 2 {
+		t.Fatalf("expected memory and subagent to be reported as personal, got %v", artifacts.Personal)
+	}
+
+	disabled := false
+	if (&models.ProjectConfig{}).UserScopeEnabled() != true || (&models.ProjectConfig{UserScope: &disabled}).UserScopeEnabled() {
+		t.Fatal("user_scope should default to enabled and honour false")
+	}
+}
+
+func TestFrontmatterIsParsedAndRenderedPerTool(t *testing.T) {
+	tempDir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("team/memory.mdc", "Team rules")
+	write("team/subagents/reviewer.md", "---\ndescription: Reviews diffs\ntools: Read, Grep\nmodel: sonnet\ncolor: blue\ntags: [review]\n---\nYou review code.")
+	write("team/subagents/linter.md", "---\npriority: 10\n---\nTeam linter")
+	write("team/subagents/legacy.md", "Legacy helper")
+	write("project/mindful/project-memory.mdc", "---\ncoding-agents: [cursor]\npriority: 1\n---\nCursor only notes")
+	write("project/mindful/project-subagents/linter.md", "Project linter")
+	write("project/mindful/project-subagents/legacy.md", "---\nenabled: false\n---\nignored")
+
+	manager := source.NewManager()
+	artifacts, err := manager.LoadLayers([]source.Layer{
+		source.SharedLayer("team", "team", "../team", filepath.Join(tempDir, "team")),
+		source.ProjectLayer(filepath.Join(tempDir, "project")),
+	})
+	if err != nil {
+		t.Fatalf("LoadLayers: %v", err)
+	}
+
+	if files := artifacts.Memory.Files; len(files) != 2 || !strings.Contains(files[0].Content, "Cursor only notes") {
+		t.Fatalf("expected the higher priority memory first, got %q", artifacts.Memory.Content)
+	}
+	subagents := map[string]*models.SubagentArtifact{}
+	for _, subagent := range artifacts.Subagents {
+		subagents[subagent.Name] = subagent
+	}
+	if _, ok := subagents["legacy"]; ok || len(subagents) != 2 {
+		t.Fatalf("expected the disabled project file to remove legacy, got %v", subagents)
+	}
+	if linter := subagents["linter"]; !strings.Contains(linter.Content, "Team linter") {
+		t.Errorf("expected the higher priority team linter to win: %q", linter.Content)
+	}
+	reviewer := subagents["reviewer"]
+	if reviewer.Meta == nil || reviewer.Meta.Model != "sonnet" || strings.Join(reviewer.Meta.AllowedTools, ",") != "Read,Grep" ||
+		strings.Join(reviewer.Meta.Tags, ",") != "review" || strings.Contains(reviewer.Content, "---") {
+		t.Fatalf("unexpected reviewer %+v", reviewer)
+	}
+
+	outputs, err := manager.RenderTools(artifacts, []source.ToolTarget{
+		{Name: "claude", Memory: true, Subagents: true, Frontmatter: models.FrontmatterClaude},
+		{Name: "cursor", Memory: true, Subagents: true, Frontmatter: models.FrontmatterCursor},
+		{Name: "codex", Memory: true, Subagents: true, Frontmatter: models.FrontmatterPlain},
+	}, []string{"claude", "codex", "cursor"})
+	if err != nil {
+		t.Fatalf("RenderTools: %v", err)
+	}
+	rendered := map[string]map[string]string{}
+	for _, output := range outputs {
+		rendered[output.Tool] = map[string]string{"memory": output.Memory}
+		for _, subagent := range output.Subagents {
+			rendered[output.Tool][subagent.Name] = subagent.Content
+		}
+	}
+
+	if strings.Contains(rendered["claude"]["memory"], "Cursor only notes") || !strings.Contains(rendered["cursor"]["memory"], "Cursor only notes") {
+		t.Errorf("coding-agents should limit memory to cursor: %v", rendered)
+	}
+	claude := rendered["claude"]["reviewer"]
+	if !strings.HasPrefix(claude, "---\nname: reviewer\ndescription: Reviews diffs\ntools: Read, Grep\nmodel: sonnet\ncolor: blue\n---\n") {
+		t.Errorf("unexpected claude subagent:\n%s", claude)
+	}
+	cursor := rendered["cursor"]["reviewer"]
+	if !strings.HasPrefix(cursor, "---\ndescription: Reviews diffs\ncolor: blue\n---\n") || strings.Contains(cursor, "model:") {
+		t.Errorf("unexpected cursor rule:\n%s", cursor)
+	}
+	if codex := rendered["codex"]["reviewer"]; strings.Contains(codex, "---") || !strings.Contains(codex, "You review code.") {
+		t.Errorf("plain output should drop frontmatter:\n%s", codex)
+	}
+
+	if _, err := manager.RenderTools(artifacts, nil, []string{"claude"}); err == nil || !strings.Contains(err.Error(), "unknown coding agent 'cursor'") {
+		t.Fatalf("expected an unknown coding agent error, got %v", err)
+	}
+}
+
+func TestFrontmatterErrorsNameTheFileAndField(t *testing.T) {
+	teamDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(teamDir, "subagents"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	manager := source.NewManager()
+
+	for name, content := range map[string]string{
+		"priority":      "---\npriority: high\n---\nbody",
+		"enabled":       "---\nenabled: maybe\n---\nbody",
+		"coding-agents": "---\ncoding-agents: {claude: true}\n---\nbody",
+		"name":          "---\nname: other\n---\nbody",
+		"allowed-tools": "---\ntools: Read\nallowed-tools: [Read]\n---\nbody",
+	} {
+		path := filepath.Join(teamDir, "subagents", "agent.md")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_, err := manager.LoadArtifacts(teamDir, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), "'"+name+"'") {
+			t.Errorf("%s: expected an error naming %s and the field, got %v", name, path, err)
+		}
+	}
+
+	memoryPath := filepath.Join(teamDir, "memory.md")
+	for content, want := range map[string]string{
+		"---\nenabeld: false\n---\nbody": "did you mean 'enabled'?",
+		"---\nglobs: '*.go'\n---\nbody":  "unknown field",
+	} {
+		if err := os.WriteFile(memoryPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_, err := manager.LoadArtifacts(teamDir, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), memoryPath) || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected memory frontmatter %q to fail with %q, got %v", content, want, err)
+		}
+	}
+	if err := os.Remove(memoryPath); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	// Subagents pass fields mindful does not know through to the tools, but not typos.
+	for content, ok := range map[string]bool{
+		"---\nglobs: '*.go'\n---\nbody":       true,
+		"---\ndescripton: Reviews\n---\nbody": false,
+	} {
+		if err := os.WriteFile(filepath.Join(teamDir, "subagents", "agent.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_, err := manager.LoadArtifacts(teamDir, t.TempDir())
+		if ok && err != nil {
+			t.Errorf("expected %q to load, got %v", content, err)
+		}
+		if !ok && (err == nil || !strings.Contains(err.Error(), "did you mean 'description'?")) {
+			t.Errorf("expected %q to be rejected with a suggestion, got %v", content, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(teamDir, "subagents", "agent.md"), []byte("---\ndescription: unterminated\nbody"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := manager.LoadArtifacts(teamDir, t.TempDir()); err == nil || !strings.Contains(err.Error(), "not closed") {
+		t.Errorf("expected an unclosed frontmatter error, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mindful/src/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// newKeyFile generates a key file in dir, as mindful secrets init-key does.
+func newKeyFile(t *testing.T, dir string) storage.KeySource {
+	t.Helper()
+	keys := storage.KeySource{KeyFile: filepath.Join(dir, "key")}
+	if err := storage.GenerateKeyFile(keys.KeyFile); err != nil {
+		t.Fatalf("GenerateKeyFile: %v", err)
+	}
+	return keys
+}
+
+func TestStorageEncryptsMCPConfigsAtRest(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindful.db")
+	keys := newKeyFile(t, dir)
+
+	manager, err := storage.NewManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("NewManagerWithKeys: %v", err)
+	}
+	if err := manager.StoreMCP("github", "eyJjb21tYW5kIjoibnB4In0="); err != nil {
+		t.Fatalf("StoreMCP: %v", err)
+	}
+	if value, err := manager.RetrieveMCP("github"); err != nil || value != "eyJjb21tYW5kIjoibnB4In0=" {
+		t.Fatalf("RetrieveMCP returned %q (err=%v)", value, err)
+	}
+	manager.Close()
+
+	raw := readRawMCP(t, dbPath, "github")
+	if !strings.HasPrefix(raw, "enc:v1:") || strings.Contains(raw, "eyJjb21tYW5kIjoibnB4In0=") {
+		t.Fatalf("expected encrypted value at rest, got %q", raw)
+	}
+
+	missing, err := storage.NewManagerWithKeys(dbPath, storage.KeySource{KeyFile: filepath.Join(dir, "absent")})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, err := missing.ListMCP(); !errors.Is(err, storage.ErrKeyMissing) {
+		t.Fatalf("expected ErrKeyMissing without the key, got %v", err)
+	}
+	missing.Close()
+
+	manager, err = storage.NewManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	count, err := manager.RotateKey(storage.KeySource{Passphrase: "correct horse"})
+	if err != nil || count != 1 {
+		t.Fatalf("RotateKey returned %d (err=%v)", count, err)
+	}
+	manager.Close()
+
+	wrong, err := storage.NewManagerWithKeys(dbPath, storage.KeySource{Passphrase: "battery staple"})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, err := wrong.RetrieveMCP("github"); !errors.Is(err, storage.ErrKeyMissing) {
+		t.Fatalf("expected a wrong passphrase to be rejected, got %v", err)
+	}
+	wrong.Close()
+
+	rotated, err := storage.NewManagerWithKeys(dbPath, storage.KeySource{Passphrase: "correct horse"})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer rotated.Close()
+	records, err := rotated.ListMCP()
+	if err != nil || records["github"] != "eyJjb21tYW5kIjoibnB4In0=" {
+		t.Fatalf("expected decrypted records after rotation, got %v (err=%v)", records, err)
+	}
+}
+
+func readRawMCP(t *testing.T, dbPath, name string) string {
+	t.Helper()
+	db, err := bolt.Open(dbPath, 0o600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("open raw db: %v", err)
+	}
+	defer db.Close()
+
+	var raw string
+	if err := db.View(func(tx *bolt.Tx) error {
+		raw = string(tx.Bucket([]byte("mcp")).Get([]byte(name)))
+		return nil
+	}); err != nil {
+		t.Fatalf("read raw value: %v", err)
+	}
+	return raw
+}
+
+func TestStorageSecretsBucket(t *testing.T) {
+	dir := t.TempDir()
+	keys := newKeyFile(t, dir)
+	manager, err := storage.NewSecretStoreWithKeys(filepath.Join(dir, "secrets.db"), keys)
+	if err != nil {
+		t.Fatalf("NewSecretStoreWithKeys: %v", err)
+	}
+	defer manager.Close()
+
+	// MCP stores have no secrets bucket and refuse to hold secrets.
+	mcpStore, err := storage.NewManagerWithKeys(filepath.Join(dir, "mindful.db"), keys)
+	if err != nil {
+		t.Fatalf("NewManagerWithKeys: %v", err)
+	}
+	if err := mcpStore.StoreSecret("github_token", "ghp_123"); err == nil {
+		t.Fatal("expected an MCP store to refuse secrets")
+	}
+	mcpStore.Close()
+	db, err := bolt.Open(filepath.Join(dir, "mindful.db"), 0o600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("open raw database: %v", err)
+	}
+	if err := db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("secrets")) != nil {
+			t.Error("expected no secrets bucket in an MCP store")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("inspect raw database: %v", err)
+	}
+	db.Close()
+
+	if err := manager.StoreSecret("github_token", "ghp_123"); err != nil {
+		t.Fatalf("StoreSecret: %v", err)
+	}
+	if value, ok, err := manager.RetrieveSecret("github_token"); err != nil || !ok || value != "ghp_123" {
+		t.Fatalf("RetrieveSecret returned %q, %v (err=%v)", value, ok, err)
+	}
+	if _, ok, err := manager.RetrieveSecret("absent"); err != nil || ok {
+		t.Fatalf("expected absent secret to be reported missing, got ok=%v err=%v", ok, err)
+	}
+
+	names, err := manager.ListSecrets()
+	if err != nil || len(names) != 1 || names[0] != "github_token" {
+		t.Fatalf("ListSecrets returned %v (err=%v)", names, err)
+	}
+	if err := manager.DeleteSecret("github_token"); err != nil {
+		t.Fatalf("DeleteSecret: %v", err)
+	}
+	if err := manager.DeleteSecret("github_token"); err == nil {
+		t.Fatalf("expected deleting a missing secret to fail")
+	}
+}
+
+func TestStorageReadOnlyManagersShareTheDatabase(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindful.db")
+	keys := newKeyFile(t, dir)
+
+	if _, err := storage.NewReadOnlyManagerWithKeys(dbPath, keys); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing database to be reported, got %v", err)
+	}
+
+	writer, err := storage.NewManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("NewManagerWithKeys: %v", err)
+	}
+	if err := writer.StoreMCP("github", "eyJjb21tYW5kIjoibnB4In0="); err != nil {
+		t.Fatalf("StoreMCP: %v", err)
+	}
+	writer.Close()
+
+	first, err := storage.NewReadOnlyManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("first reader: %v", err)
+	}
+	defer first.Close()
+	second, err := storage.NewReadOnlyManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("second reader: %v", err)
+	}
+	defer second.Close()
+
+	for _, reader := range []*storage.Manager{first, second} {
+		if value, err := reader.RetrieveMCP("github"); err != nil || value != "eyJjb21tYW5kIjoibnB4In0=" {
+			t.Fatalf("RetrieveMCP returned %q (err=%v)", value, err)
+		}
+		if secrets, err := reader.ListSecrets(); err != nil || len(secrets) != 0 {
+			t.Fatalf("ListSecrets returned %v (err=%v)", secrets, err)
+		}
+	}
+	if err := first.StoreMCP("other", "eyJjb21tYW5kIjoibnB4In0="); !errors.Is(err, storage.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestStorageReportsTheProcessHoldingTheLock(t *testing.T) {
+	previous := storage.LockTimeout
+	storage.LockTimeout = 100 * time.Millisecond
+	defer func() { storage.LockTimeout = previous }()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "mindful.db")
+	keys := newKeyFile(t, dir)
+
+	writer, err := storage.NewManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("NewManagerWithKeys: %v", err)
+	}
+
+	started := time.Now()
+	_, err = storage.NewReadOnlyManagerWithKeys(dbPath, keys)
+	if !errors.Is(err, storage.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("PID %d", os.Getpid())) {
+		t.Fatalf("expected the holder's PID in %q", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("opening a locked database took %s", elapsed)
+	}
+
+	writer.Close()
+	if _, err := os.Stat(dbPath + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock file to be removed on close, got %v", err)
+	}
+	reader, err := storage.NewReadOnlyManagerWithKeys(dbPath, keys)
+	if err != nil {
+		t.Fatalf("open after release: %v", err)
+	}
+	reader.Close()
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+
+	"mindful/src/models"
+	"mindful/src/symlink"
+)
+
+func TestResolveConfigMergesProjectOverrides(t *testing.T) {
+	agentsPath := "docs/AGENTS.md"
+	disabled := ""
+
+	cfg, err := symlink.ResolveConfig(map[string]*models.ToolSymlinkOverride{
+		"codex":  {Memory: &agentsPath},
+		"cursor": {MCP: &disabled},
+		"gemini": {Memory: &agentsPath},
+	})
+	if err != nil {
+		t.Fatalf("ResolveConfig: %v", err)
+	}
+
+	codex, ok := cfg.ToolConfig("codex")
+	if !ok || codex.Memory != agentsPath {
+		t.Fatalf("expected codex memory override, got %+v", codex)
+	}
+
+	cursor, ok := cfg.ToolConfig("cursor")
+	if !ok {
+		t.Fatalf("expected cursor mapping to be kept")
+	}
+	if cursor.MCP != "" {
+		t.Errorf("expected cursor mcp to be disabled, got %q", cursor.MCP)
+	}
+	if cursor.Memory != ".cursor/rules/general.mindful.mdc" {
+		t.Errorf("expected cursor memory default to be kept, got %q", cursor.Memory)
+	}
+
+	if gemini, ok := cfg.ToolConfig("gemini"); !ok || gemini.Memory != agentsPath {
+		t.Errorf("expected gemini tool to be added, got %+v", gemini)
+	}
+
+	defaults, err := symlink.DefaultConfig()
+	if err != nil {
+		t.Fatalf("DefaultConfig: %v", err)
+	}
+	if original, _ := defaults.ToolConfig("codex"); original.Memory != "AGENTS.md" {
+		t.Errorf("merge must not mutate the default mapping, got %q", original.Memory)
+	}
+}
@@ -1,9 +1,11 @@
 package unit
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"mindful/src/models"
@@ -17,17 +19,17 @@ func TestSymlinkManagerCreateAndValidate(t *testing.T) {
 
 	projectDir := t.TempDir()
 	mindfulOut := filepath.Join(projectDir, "mindful", "out")
-	if err := os.MkdirAll(filepath.Join(mindfulOut, "subagents"), 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "claude", "subagents"), 0o755); err != nil {
 		t.Fatalf("create out dir: %v", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(mindfulOut, "memory.md"), []byte("memory"), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "memory.md"), []byte("memory"), 0o644); err != nil {
 		t.Fatalf("write memory: %v", err)
 	}
-	if err := os.WriteFile(filepath.Join(mindfulOut, "mcp.json"), []byte("{}"), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "mcp.json"), []byte("{}"), 0o644); err != nil {
 		t.Fatalf("write mcp: %v", err)
 	}
-	if err := os.WriteFile(filepath.Join(mindfulOut, "subagents", "researcher.mdc"), []byte("agent"), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "subagents", "researcher.mdc"), []byte("agent"), 0o644); err != nil {
 		t.Fatalf("write subagent: %v", err)
 	}
 
@@ -77,3 +79,901 @@ func TestSymlinkManagerCreateAndValidate(t *testing.T) {
 		t.Fatalf("expected CLAUDE.md to be removed, err=%v", err)
 	}
 }
+
+func TestSymlinkManagerCopyModeTracksEdits(t *testing.T) {
+	projectDir := t.TempDir()
+	mindfulOut := filepath.Join(projectDir, "mindful", "out")
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "claude"), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+
+	memoryPath := filepath.Join(mindfulOut, "claude", "memory.md")
+	if err := os.WriteFile(memoryPath, []byte("memory v1\n"), 0o644); err != nil {
+		t.Fatalf("write memory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "mcp.json"), []byte(`{"mcpServers":{"docs":{"command":"docs"}}}`), 0o644); err != nil {
+		t.Fatalf("write mcp: %v", err)
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			Memory: "CLAUDE.md",
+			MCP:    ".mcp.json",
+			Mode:   models.LinkModeCopy,
+		},
+	})
+
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	copyPath := filepath.Join(projectDir, "CLAUDE.md")
+	if info, err := os.Lstat(copyPath); err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected CLAUDE.md to be a regular file, err=%v", err)
+	}
+	if err := manager.ValidateSymlinks("claude"); err != nil {
+		t.Fatalf("ValidateSymlinks: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(filepath.Join(projectDir, ".mcp.json")); err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected the MCP copy to be private (0600), got %v (err=%v)", info.Mode().Perm(), err)
+		}
+	}
+
+	assertStatus := func(want string) {
+		t.Helper()
+		infos, err := manager.ListSymlinks("claude")
+		if err != nil {
+			t.Fatalf("ListSymlinks: %v", err)
+		}
+		if infos[0].Status != want {
+			t.Fatalf("expected CLAUDE.md status %q, got %q", want, infos[0].Status)
+		}
+	}
+
+	if err := os.WriteFile(memoryPath, []byte("memory v2\n"), 0o644); err != nil {
+		t.Fatalf("rewrite memory: %v", err)
+	}
+	assertStatus(models.LinkStatusStale)
+
+	data, err := os.ReadFile(copyPath)
+	if err != nil {
+		t.Fatalf("read copy: %v", err)
+	}
+	if err := os.WriteFile(copyPath, append(data, []byte("hand edit\n")...), 0o644); err != nil {
+		t.Fatalf("edit copy: %v", err)
+	}
+	assertStatus(models.LinkStatusModified)
+
+	if err := manager.CreateSymlinks("claude"); err == nil {
+		t.Fatalf("expected apply to refuse overwriting a hand-edited copy")
+	}
+	if err := manager.Apply("claude", symlink.ApplyOptions{Force: true}); err != nil {
+		t.Fatalf("forced Apply: %v", err)
+	}
+	assertStatus(models.LinkStatusOK)
+
+	if err := manager.CleanupSymlinks("claude"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	for _, name := range []string{"CLAUDE.md", ".mcp.json"} {
+		if _, err := os.Lstat(filepath.Join(projectDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, err=%v", name, err)
+		}
+	}
+}
+
+func TestSymlinkManagerInjectsMemoryBlocks(t *testing.T) {
+	projectDir := t.TempDir()
+	mindfulOut := filepath.Join(projectDir, "mindful", "out")
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "claude"), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+
+	memory := models.ScopeAnnotation("team", "/team/memory.mdc") + "\nTeam rules\n\n" +
+		models.ScopeAnnotation("project", "mindful/project-memory.mdc") + "\nProject rules\n"
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "memory.md"), []byte(memory), 0o644); err != nil {
+		t.Fatalf("write memory: %v", err)
+	}
+
+	userContent := "# My notes\n\nKeep this.\n"
+	claudePath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.WriteFile(claudePath, []byte(userContent), 0o644); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			Memory: "CLAUDE.md",
+			Modes:  map[string]string{models.ArtifactMemory: models.LinkModeInject},
+		},
+	})
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+	first, err := os.ReadFile(claudePath)
+	if err != nil {
+		t.Fatalf("read CLAUDE.md: %v", err)
+	}
+	for _, want := range []string{"# My notes", "## Mindful Memory (scope: team)", "Team rules", "## Mindful Memory (scope: project)", "Project rules"} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("expected CLAUDE.md to contain %q, got:\n%s", want, first)
+		}
+	}
+
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("second CreateSymlinks: %v", err)
+	}
+	second, _ := os.ReadFile(claudePath)
+	if string(first) != string(second) {
+		t.Fatalf("injection is not idempotent:\n%s\n---\n%s", first, second)
+	}
+	if err := manager.ValidateSymlinks("claude"); err != nil {
+		t.Fatalf("ValidateSymlinks: %v", err)
+	}
+
+	if err := manager.CleanupSymlinks("claude"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	cleaned, _ := os.ReadFile(claudePath)
+	if string(cleaned) != userContent {
+		t.Fatalf("expected user content to be restored, got:\n%s", cleaned)
+	}
+}
+
+func TestSymlinkManagerInjectsInPlaceKeepingLineEndingsAndMode(t *testing.T) {
+	projectDir := t.TempDir()
+	memoryPath := filepath.Join(projectDir, "mindful", "out", "claude", "memory.md")
+	if err := os.MkdirAll(filepath.Dir(memoryPath), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	writeMemory := func(team, project string) {
+		t.Helper()
+		memory := models.ScopeAnnotation("team", "/team/memory.mdc") + "\n" + team + "\n\n" +
+			models.ScopeAnnotation("project", "mindful/project-memory.mdc") + "\n" + project + "\n"
+		if err := os.WriteFile(memoryPath, []byte(memory), 0o644); err != nil {
+			t.Fatalf("write memory: %v", err)
+		}
+	}
+	writeMemory("Team rules", "Project rules")
+
+	// A CRLF file with user text between the blocks, readable only by its owner.
+	userContent := strings.Join([]string{
+		"# My notes",
+		"<!-- mindful:begin scope=team -->",
+		"old team",
+		"<!-- mindful:end scope=team -->",
+		"",
+		"Between the blocks.",
+		"",
+		"",
+		"<!-- mindful:begin scope=project -->",
+		"old project",
+		"<!-- mindful:end scope=project -->",
+		"Trailing notes.",
+		"",
+	}, "\r\n")
+	claudePath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.WriteFile(claudePath, []byte(userContent), 0o600); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			Memory: "CLAUDE.md",
+			Modes:  map[string]string{models.ArtifactMemory: models.LinkModeInject},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	data, err := os.ReadFile(claudePath)
+	if err != nil {
+		t.Fatalf("read CLAUDE.md: %v", err)
+	}
+	content := string(data)
+	if strings.Contains(strings.ReplaceAll(content, "\r\n", ""), "\n") {
+		t.Errorf("expected CRLF line endings to be kept, got %q", content)
+	}
+	if !strings.HasPrefix(content, "# My notes\r\n<!-- mindful:begin scope=team -->") ||
+		!strings.Contains(content, "<!-- mindful:end scope=team -->\r\n\r\nBetween the blocks.\r\n\r\n\r\n<!-- mindful:begin scope=project -->") ||
+		!strings.HasSuffix(content, "<!-- mindful:end scope=project -->\r\nTrailing notes.\r\n") {
+		t.Errorf("expected blocks to be replaced in place, got %q", content)
+	}
+	if !strings.Contains(content, "Team rules") || strings.Contains(content, "old team") {
+		t.Errorf("expected the team block to be refreshed, got %q", content)
+	}
+	if info, err := os.Stat(claudePath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600 to be kept, got %v (err=%v)", info.Mode(), err)
+	}
+
+	links, err := manager.ListSymlinks("claude")
+	if err != nil || len(links) != 1 || links[0].Status != models.LinkStatusOK {
+		t.Fatalf("expected the CRLF file to be up to date, got %+v (err=%v)", links, err)
+	}
+
+	writeMemory("New team rules", "Project rules")
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("second CreateSymlinks: %v", err)
+	}
+	updated, _ := os.ReadFile(claudePath)
+	if want := strings.Replace(content, "Team rules", "New team rules", 1); string(updated) != want {
+		t.Fatalf("expected only the team block to change:\n%q\n---\n%q", want, updated)
+	}
+}
+
+func TestSymlinkManagerMergesMCPServers(t *testing.T) {
+	projectDir := t.TempDir()
+	mindfulOut := filepath.Join(projectDir, "mindful", "out")
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "claude"), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+
+	targetPath := filepath.Join(mindfulOut, "claude", "mcp.json")
+	writeTarget := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(targetPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("write mcp target: %v", err)
+		}
+	}
+	writeTarget(`{"mcpServers":{"github":{"command":"gh-mcp"},"docs":{"command":"docs-mcp"}}}`)
+
+	mcpPath := filepath.Join(projectDir, ".mcp.json")
+	userFile := `{"theme":"dark","mcpServers":{"private":{"command":"mine"}}}`
+	if err := os.WriteFile(mcpPath, []byte(userFile), 0o644); err != nil {
+		t.Fatalf("write .mcp.json: %v", err)
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			MCP:   ".mcp.json",
+			Modes: map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+		},
+	})
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	readServers := func() map[string]interface{} {
+		t.Helper()
+		data, err := os.ReadFile(mcpPath)
+		if err != nil {
+			t.Fatalf("read .mcp.json: %v", err)
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("parse .mcp.json: %v", err)
+		}
+		if doc["theme"] != "dark" {
+			t.Errorf("expected unrelated keys to be preserved, got %v", doc)
+		}
+		servers, _ := doc["mcpServers"].(map[string]interface{})
+		return servers
+	}
+
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+	servers := readServers()
+	for _, name := range []string{"private", "github", "docs"} {
+		if _, ok := servers[name]; !ok {
+			t.Errorf("expected server %s after merge, got %v", name, servers)
+		}
+	}
+	// Ownership lives in the apply state, so the user's file keeps its own schema.
+	if data, _ := os.ReadFile(mcpPath); strings.Contains(string(data), "_mindful") {
+		t.Errorf("expected no mindful bookkeeping in .mcp.json, got:\n%s", data)
+	}
+	state, err := manager.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if entries := state.Tools["claude"].Entries; len(entries) != 1 || strings.Join(entries[0].Servers, ",") != "docs,github" {
+		t.Fatalf("expected the merged servers in the apply state, got %+v", entries)
+	}
+	if err := manager.ValidateSymlinks("claude"); err != nil {
+		t.Fatalf("ValidateSymlinks: %v", err)
+	}
+
+	writeTarget(`{"mcpServers":{"github":{"command":"gh-mcp"}}}`)
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("second CreateSymlinks: %v", err)
+	}
+	servers = readServers()
+	if _, ok := servers["docs"]; ok {
+		t.Errorf("expected removed server docs to be pruned, got %v", servers)
+	}
+	if _, ok := servers["private"]; !ok {
+		t.Errorf("expected user server to survive, got %v", servers)
+	}
+
+	if err := manager.CleanupSymlinks("claude"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	servers = readServers()
+	if len(servers) != 1 || servers["private"] == nil {
+		t.Errorf("expected only the user server after cleanup, got %v", servers)
+	}
+}
+
+func TestSymlinkManagerRefusesToTakeOverUserServers(t *testing.T) {
+	projectDir := t.TempDir()
+	targetPath := filepath.Join(projectDir, "mindful", "out", "claude", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	if err := os.WriteFile(targetPath, []byte(`{"mcpServers":{"github":{"command":"gh-mcp"}}}`), 0o644); err != nil {
+		t.Fatalf("write mcp target: %v", err)
+	}
+
+	mcpPath := filepath.Join(projectDir, ".mcp.json")
+	userFile := `{"mcpServers":{"github":{"command":"my-github"}}}`
+	if err := os.WriteFile(mcpPath, []byte(userFile), 0o644); err != nil {
+		t.Fatalf("write .mcp.json: %v", err)
+	}
+
+	manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			MCP:   ".mcp.json",
+			Modes: map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	err = manager.CreateSymlinks("claude")
+	if err == nil || !strings.Contains(err.Error(), "github") || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected the clash with the user's github server to be reported, got %v", err)
+	}
+	if data, _ := os.ReadFile(mcpPath); string(data) != userFile {
+		t.Fatalf("expected .mcp.json to be left alone, got:\n%s", data)
+	}
+
+	if err := manager.Apply("claude", symlink.ApplyOptions{Force: true}); err != nil {
+		t.Fatalf("Apply --force: %v", err)
+	}
+	data, _ := os.ReadFile(mcpPath)
+	if !strings.Contains(string(data), "gh-mcp") {
+		t.Fatalf("expected --force to replace the user's server, got:\n%s", data)
+	}
+}
+
+func TestSymlinkManagerMergesIntoCommentedZedSettings(t *testing.T) {
+	projectDir := t.TempDir()
+	targetPath := filepath.Join(projectDir, "mindful", "out", "zed", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	writeTarget := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(targetPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("write zed target: %v", err)
+		}
+	}
+	writeTarget(`{"context_servers":{"github":{"command":{"path":"gh-mcp","args":[]}}}}`)
+
+	settingsPath := filepath.Join(projectDir, ".zed", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+		t.Fatalf("create .zed: %v", err)
+	}
+	settings := `// Zed settings
+{
+  "vim_mode": true,
+  "ui_font_size": 16, // larger for demos
+  "context_servers": {
+    // My own server
+    "private": {"command": {"path": "mine"}},
+  },
+  "buffer_font_family": "Zed Mono",
+}
+`
+	if err := os.WriteFile(settingsPath, []byte(settings), 0o644); err != nil {
+		t.Fatalf("write settings.json: %v", err)
+	}
+
+	manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"zed": {
+			MCP:       ".zed/settings.json",
+			MCPFormat: models.MCPFormatZed,
+			Modes:     map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	if err := manager.CreateSymlinks("zed"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+	data, _ := os.ReadFile(settingsPath)
+	want := `// Zed settings
+{
+  "vim_mode": true,
+  "ui_font_size": 16, // larger for demos
+  "context_servers": {
+    // My own server
+    "private": {"command": {"path": "mine"}},
+    "github": {
+      "command": {
+        "path": "gh-mcp",
+        "args": []
+      }
+    },
+  },
+  "buffer_font_family": "Zed Mono",
+}
+`
+	if string(data) != want {
+		t.Fatalf("expected only the github server to be added:\n%s\n--- got ---\n%s", want, data)
+	}
+	if err := manager.ValidateSymlinks("zed"); err != nil {
+		t.Fatalf("ValidateSymlinks: %v", err)
+	}
+
+	writeTarget(`{"context_servers":{"github":{"command":{"path":"gh-mcp","args":["--read-only"]}}}}`)
+	if err := manager.CreateSymlinks("zed"); err != nil {
+		t.Fatalf("second CreateSymlinks: %v", err)
+	}
+	data, _ = os.ReadFile(settingsPath)
+	if !strings.Contains(string(data), `"--read-only"`) || !strings.Contains(string(data), "// larger for demos") {
+		t.Fatalf("expected the github server to be updated in place, got:\n%s", data)
+	}
+
+	if err := manager.CleanupSymlinks("zed"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	if data, _ := os.ReadFile(settingsPath); string(data) != settings {
+		t.Fatalf("expected cleanup to restore the original settings, got:\n%s", data)
+	}
+}
+
+func TestSymlinkManagerMigratesLegacyMergeMarker(t *testing.T) {
+	projectDir := t.TempDir()
+	targetPath := filepath.Join(projectDir, "mindful", "out", "claude", "mcp.json")
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	if err := os.WriteFile(targetPath, []byte(`{"mcpServers":{"github":{"command":"gh-mcp"}}}`), 0o644); err != nil {
+		t.Fatalf("write mcp target: %v", err)
+	}
+
+	// Written by an older version that kept ownership in the file itself.
+	mcpPath := filepath.Join(projectDir, ".mcp.json")
+	legacy := `{"_mindful":{"servers":["docs"]},"mcpServers":{"docs":{"command":"old-docs"},"private":{"command":"mine"}}}`
+	if err := os.WriteFile(mcpPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write .mcp.json: %v", err)
+	}
+
+	manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {
+			MCP:   ".mcp.json",
+			Modes: map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	data, _ := os.ReadFile(mcpPath)
+	var doc map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("parse .mcp.json: %v", err)
+	}
+	if _, ok := doc["_mindful"]; ok {
+		t.Errorf("expected the legacy marker to be dropped, got:\n%s", data)
+	}
+	servers := doc["mcpServers"]
+	if _, ok := servers["docs"]; ok || servers["github"] == nil || servers["private"] == nil {
+		t.Fatalf("expected docs to be pruned and github merged next to private, got:\n%s", data)
+	}
+
+	// A lost apply state must not turn mindful's own servers into clashes.
+	if err := os.Remove(filepath.Join(projectDir, "mindful", models.DefaultStateFileName)); err != nil {
+		t.Fatalf("remove state: %v", err)
+	}
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks without state: %v", err)
+	}
+}
+
+func TestSymlinkManagerMergesCodexTOML(t *testing.T) {
+	projectDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	targetPath := filepath.Join(projectDir, "mindful", "out", "codex", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	writeTarget := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(targetPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("write codex target: %v", err)
+		}
+	}
+	writeTarget("[mcp_servers.github]\ncommand = \"gh-mcp\"\n\n[mcp_servers.docs]\ncommand = \"docs-mcp\"\n")
+
+	configPath := filepath.Join(home, ".codex", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatalf("create codex dir: %v", err)
+	}
+	userFile := "# personal settings\nmodel = \"o3\"\n\n[mcp_servers.private]\ncommand = \"mine\"\n\n# faster profile\n[profiles.fast]\nmodel = \"o4-mini\"\n"
+	if err := os.WriteFile(configPath, []byte(userFile), 0o644); err != nil {
+		t.Fatalf("write config.toml: %v", err)
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"codex": {
+			MCP:       "~/.codex/config.toml",
+			MCPFormat: models.MCPFormatCodex,
+			Modes:     map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+		},
+	})
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	previews, err := manager.PreviewMerges("codex", symlink.ApplyOptions{})
+	if err != nil {
+		t.Fatalf("PreviewMerges: %v", err)
+	}
+	if len(previews) != 1 || !strings.Contains(previews[0].Diff(), "+[mcp_servers.github]") {
+		t.Fatalf("expected a diff adding github, got %+v", previews)
+	}
+
+	readConfig := func() string {
+		t.Helper()
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatalf("read config.toml: %v", err)
+		}
+		return string(data)
+	}
+
+	if err := manager.CreateSymlinks("codex"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+	content := readConfig()
+	for _, want := range []string{"# personal settings", "[mcp_servers.private]", "# faster profile\n[profiles.fast]", "[mcp_servers.github]", "[mcp_servers.docs]"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q after merge, got:\n%s", want, content)
+		}
+	}
+	if err := manager.ValidateSymlinks("codex"); err != nil {
+		t.Fatalf("ValidateSymlinks: %v", err)
+	}
+
+	writeTarget("[mcp_servers.github]\ncommand = \"gh-mcp\"\n")
+	if err := manager.CreateSymlinks("codex"); err != nil {
+		t.Fatalf("second CreateSymlinks: %v", err)
+	}
+	if content := readConfig(); strings.Contains(content, "docs-mcp") || !strings.Contains(content, "[mcp_servers.private]") {
+		t.Errorf("expected docs to be pruned and the user server kept, got:\n%s", content)
+	}
+
+	if err := manager.CleanupSymlinks("codex"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	if content := readConfig(); content != userFile {
+		t.Errorf("expected cleanup to restore the user's file, got:\n%s", content)
+	}
+}
+
+func TestCodexTOMLMergeKeepsEachProjectsServers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".codex", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatalf("create codex dir: %v", err)
+	}
+	userFile := "[mcp_servers.private]\ncommand = \"mine\"\n"
+	if err := os.WriteFile(configPath, []byte(userFile), 0o644); err != nil {
+		t.Fatalf("write config.toml: %v", err)
+	}
+
+	newProject := func(servers string) *symlink.Manager {
+		t.Helper()
+		projectDir := t.TempDir()
+		targetPath := filepath.Join(projectDir, "mindful", "out", "codex", "config.toml")
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+			t.Fatalf("create out dir: %v", err)
+		}
+		if err := os.WriteFile(targetPath, []byte(servers), 0o600); err != nil {
+			t.Fatalf("write codex target: %v", err)
+		}
+		manager, err := symlink.NewManager(projectDir, models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+			"codex": {
+				MCP:       "~/.codex/config.toml",
+				MCPFormat: models.MCPFormatCodex,
+				Modes:     map[string]string{models.ArtifactMCP: models.LinkModeMerge},
+			},
+		}))
+		if err != nil {
+			t.Fatalf("NewManager: %v", err)
+		}
+		return manager
+	}
+	readConfig := func() string {
+		t.Helper()
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatalf("read config.toml: %v", err)
+		}
+		return string(data)
+	}
+
+	first := newProject("[mcp_servers.github]\ncommand = \"gh-mcp\"\n")
+	second := newProject("[mcp_servers.docs]\ncommand = \"docs-mcp\"\n")
+	for _, manager := range []*symlink.Manager{first, second} {
+		if err := manager.CreateSymlinks("codex"); err != nil {
+			t.Fatalf("CreateSymlinks: %v", err)
+		}
+	}
+	content := readConfig()
+	for _, want := range []string{"[mcp_servers.private]", "[mcp_servers.github]", "[mcp_servers.docs]"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q with both projects applied, got:\n%s", want, content)
+		}
+	}
+	for _, manager := range []*symlink.Manager{first, second} {
+		if err := manager.ValidateSymlinks("codex"); err != nil {
+			t.Errorf("ValidateSymlinks: %v", err)
+		}
+	}
+
+	// A server the user wrote, or another project merged, is never taken over silently.
+	for _, clash := range []string{"private", "github"} {
+		manager := newProject("[mcp_servers." + clash + "]\ncommand = \"other\"\n")
+		if err := manager.CreateSymlinks("codex"); err == nil || !strings.Contains(err.Error(), clash) {
+			t.Errorf("expected a clash on %s, got %v", clash, err)
+		}
+	}
+	if readConfig() != content {
+		t.Fatalf("expected clashing applies to leave config.toml alone, got:\n%s", readConfig())
+	}
+
+	if err := second.CleanupSymlinks("codex"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	content = readConfig()
+	if strings.Contains(content, "docs-mcp") || !strings.Contains(content, "gh-mcp") || !strings.Contains(content, "[mcp_servers.private]") {
+		t.Errorf("expected cleanup to remove only the second project's server, got:\n%s", content)
+	}
+	if err := first.CleanupSymlinks("codex"); err != nil {
+		t.Fatalf("CleanupSymlinks: %v", err)
+	}
+	if content := readConfig(); content != userFile {
+		t.Errorf("expected cleanup to restore the user's file, got:\n%s", content)
+	}
+}
+
+func TestSymlinkManagerPrunesOrphanedSubagents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation on Windows requires special privileges")
+	}
+
+	projectDir := t.TempDir()
+	subagentDir := filepath.Join(projectDir, "mindful", "out", "claude", "subagents")
+	if err := os.MkdirAll(subagentDir, 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	for _, name := range []string{"keep.mdc", "gone.mdc"} {
+		if err := os.WriteFile(filepath.Join(subagentDir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("write subagent: %v", err)
+		}
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {Subagents: ".claude/agents/{name}.mindful.md"},
+	})
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("claude"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	// A user-owned file matching the template must never be pruned.
+	userAgent := filepath.Join(projectDir, ".claude", "agents", "mine.mindful.md")
+	if err := os.WriteFile(userAgent, []byte("mine"), 0o644); err != nil {
+		t.Fatalf("write user agent: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(subagentDir, "gone.mdc")); err != nil {
+		t.Fatalf("remove subagent: %v", err)
+	}
+
+	pruned, err := manager.PruneOrphans("claude")
+	if err != nil {
+		t.Fatalf("PruneOrphans: %v", err)
+	}
+	want := filepath.Join(".claude", "agents", "gone.mindful.md")
+	if len(pruned) != 1 || pruned[0] != want {
+		t.Fatalf("expected %s to be pruned, got %v", want, pruned)
+	}
+
+	if _, err := os.Lstat(filepath.Join(projectDir, want)); !os.IsNotExist(err) {
+		t.Fatalf("expected orphan to be removed, err=%v", err)
+	}
+	for _, path := range []string{userAgent, filepath.Join(projectDir, ".claude", "agents", "keep.mindful.md")} {
+		if _, err := os.Lstat(path); err != nil {
+			t.Fatalf("expected %s to survive pruning: %v", path, err)
+		}
+	}
+}
+
+func TestSymlinkManagerStateRemovesAbandonedLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation on Windows requires special privileges")
+	}
+
+	projectDir := t.TempDir()
+	mindfulOut := filepath.Join(projectDir, "mindful", "out")
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "codex"), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mindfulOut, "codex", "memory.md"), []byte("memory"), 0o644); err != nil {
+		t.Fatalf("write memory: %v", err)
+	}
+
+	oldConfig := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"codex": {Memory: "AGENTS.md"},
+	})
+	manager, err := symlink.NewManager(projectDir, oldConfig)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := manager.CreateSymlinks("codex"); err != nil {
+		t.Fatalf("CreateSymlinks: %v", err)
+	}
+
+	state, err := manager.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if tool := state.Tools["codex"]; tool == nil || len(tool.Entries) != 1 || tool.Entries[0].Path != "AGENTS.md" {
+		t.Fatalf("expected AGENTS.md to be recorded, got %+v", state.Tools["codex"])
+	}
+	if state.BuildHash == "" {
+		t.Errorf("expected build hash to be recorded")
+	}
+
+	newConfig := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"codex": {Memory: "docs/AGENTS.md"},
+	})
+	manager, err = symlink.NewManager(projectDir, newConfig)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	infos, err := manager.ListSymlinks("codex")
+	if err != nil {
+		t.Fatalf("ListSymlinks: %v", err)
+	}
+	if len(infos) != 2 || infos[1].Status != models.LinkStatusOrphaned {
+		t.Fatalf("expected the old path to be reported as orphaned, got %+v", infos)
+	}
+
+	if err := manager.CreateSymlinks("codex"); err != nil {
+		t.Fatalf("CreateSymlinks after template change: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(projectDir, "AGENTS.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected old AGENTS.md link to be removed, err=%v", err)
+	}
+
+	if err := manager.ForgetTool("codex"); err != nil {
+		t.Fatalf("ForgetTool: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(projectDir, "docs", "AGENTS.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected docs/AGENTS.md to be removed when the tool is forgotten, err=%v", err)
+	}
+	if tools, _ := manager.RecordedTools(); len(tools) != 0 {
+		t.Fatalf("expected no recorded tools, got %v", tools)
+	}
+}
+
+func TestSymlinkManagerAdoptAndRestore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation on Windows requires special privileges")
+	}
+
+	projectDir := t.TempDir()
+	mindfulOut := filepath.Join(projectDir, "mindful", "out")
+	if err := os.MkdirAll(filepath.Join(mindfulOut, "claude"), 0o755); err != nil {
+		t.Fatalf("create out dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mindfulOut, "claude", "memory.md"), []byte("team memory"), 0o644); err != nil {
+		t.Fatalf("write memory: %v", err)
+	}
+
+	original := []byte("# Hand-written notes\n")
+	claudePath := filepath.Join(projectDir, "CLAUDE.md")
+	if err := os.WriteFile(claudePath, original, 0o644); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	config := models.NewSymlinkConfig(map[string]*models.ToolSymlinkConfig{
+		"claude": {Memory: "CLAUDE.md"},
+	})
+	manager, err := symlink.NewManager(projectDir, config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	if err := manager.Apply("claude", symlink.ApplyOptions{}); err == nil {
+		t.Fatalf("expected apply to refuse the hand-written CLAUDE.md")
+	}
+
+	backup, err := manager.NewBackup()
+	if err != nil {
+		t.Fatalf("NewBackup: %v", err)
+	}
+	adopted, err := manager.Adopt("claude", backup)
+	if err != nil {
+		t.Fatalf("Adopt: %v", err)
+	}
+	if len(adopted) != 1 || adopted[0].Path != "CLAUDE.md" {
+		t.Fatalf("unexpected adopted entries: %+v", adopted)
+	}
+	if err := backup.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(backup.Dir(), filepath.FromSlash(adopted[0].File)))
+	if err != nil || string(saved) != string(original) {
+		t.Fatalf("expected original content in backup, got %q (err=%v)", saved, err)
+	}
+
+	if err := manager.Apply("claude", symlink.ApplyOptions{}); err != nil {
+		t.Fatalf("Apply after adopt: %v", err)
+	}
+	if info, err := os.Lstat(claudePath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected CLAUDE.md to be a symlink after adopt, err=%v", err)
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil || len(backups) != 1 || backups[0].Name != backup.Name() {
+		t.Fatalf("unexpected backups %+v (err=%v)", backups, err)
+	}
+
+	restored, err := manager.Restore(backup.Name(), false)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(restored) != 1 {
+		t.Fatalf("expected one restored entry, got %+v", restored)
+	}
+
+	info, err := os.Lstat(claudePath)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected CLAUDE.md to be a regular file after restore, err=%v", err)
+	}
+	if data, _ := os.ReadFile(claudePath); string(data) != string(original) {
+		t.Fatalf("expected original content after restore, got %q", data)
+	}
+
+	tools, err := manager.RecordedTools()
+	if err != nil || len(tools) != 0 {
+		t.Fatalf("expected restored path to be dropped from state, got %v (err=%v)", tools, err)
+	}
+}